@@ -0,0 +1,50 @@
+package main
+
+import (
+	"cms-project/internal/database"
+	"cms-project/internal/export"
+	"cms-project/internal/media"
+	"cms-project/internal/theme"
+	"cms-project/internal/workflow"
+	"flag"
+	"log"
+	"os"
+)
+
+// Static export of the public site:
+//
+//	go run ./cmd/export -out public -base-url https://example.com [-full] [-zip site.zip]
+func main() {
+	out := flag.String("out", "public", "Output directory")
+	baseURL := flag.String("base-url", "", "Absolute URL the site is published at, overrides SITE_URL")
+	full := flag.Bool("full", false, "Render every blog instead of only those updated since the previous export")
+	zipPath := flag.String("zip", "", "Also write the output to this zip archive, outside the output directory")
+	flag.Parse()
+
+	if *baseURL != "" {
+		os.Setenv("SITE_URL", *baseURL)
+	}
+	if os.Getenv("SITE_URL") == "" {
+		log.Println("SITE_URL is not set, feeds and sitemaps will contain relative URLs")
+	}
+	// The pages are rendered with the theme whether or not the server serves it
+	os.Setenv("THEME_ENABLED", "true")
+
+	// Initialize database
+	database.InitDB()
+
+	// Load the editorial workflow
+	workflow.Init()
+
+	// Configure the media storage backend
+	media.Init()
+
+	// Load the theme
+	theme.Init()
+
+	result, err := export.Run(export.Options{Dir: *out, Full: *full, Zip: *zipPath})
+	if err != nil {
+		log.Fatalf("Export failed: %v", err)
+	}
+	log.Printf("Exported site to %s: %d written, %d unchanged, %d removed", *out, result.Written, result.Unchanged, result.Removed)
+}
@@ -1,9 +1,18 @@
 package main
 
 import (
+	"cms-project/internal/audit"
 	_ "cms-project/internal/blog" // Swagger için gerekli
 	"cms-project/internal/database"
+	"cms-project/internal/media"
+	"cms-project/internal/outbox"
 	"cms-project/internal/routes"
+	"cms-project/internal/sitemap"
+	"cms-project/internal/stream"
+	"cms-project/internal/theme"
+	"cms-project/internal/user"
+	"cms-project/internal/webhook"
+	"cms-project/internal/workflow"
 	"log"
 	"net/http"
 
@@ -21,10 +30,45 @@ import (
 // @contact.name Your Name
 // @contact.url https://your-website.com
 // @contact.email your-email@example.com
+
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+
+// @securityDefinitions.apikey APIKeyAuth
+// @in header
+// @name X-API-Key
 func main() {
 	// Initialize database
 	database.InitDB()
 
+	// Load token settings
+	user.InitAuth()
+
+	// Load the editorial workflow
+	workflow.Init()
+
+	// Configure the media storage backend
+	media.Init()
+
+	// Keep the sitemap current as content changes
+	sitemap.Init()
+
+	// Deliver content events to webhook subscribers
+	webhook.Init()
+
+	// Push content events to event stream clients on every instance
+	stream.Init()
+
+	// Dispatch committed content events to the bus, webhooks and configured brokers
+	outbox.Init()
+
+	// Load the theme for public pages when enabled
+	theme.Init()
+
+	// Purge audit events past their retention period
+	audit.StartRetention()
+
 	r := routes.InitializeRoutes()
 	// Swagger route
 	r.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
@@ -0,0 +1,83 @@
+package audit
+
+import (
+	"cms-project/pkg/response"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GetEventsHandler handles retrieving audit events
+// @Summary Get audit events
+// @Description Retrieve recorded content mutations, newest first
+// @Tags Audit
+// @Security BearerAuth
+// @Param entity_type query string false "Entity type (blog, blog_lock, category, comment, media, menu, menu_location, tag)"
+// @Param entity_id query string false "Entity ID"
+// @Param actor_id query string false "ID of the user who made the change"
+// @Param action query string false "Action (create, update, delete, publish, link, unlink)"
+// @Param from query string false "Only events at or after this RFC 3339 time"
+// @Param to query string false "Only events before this RFC 3339 time"
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of events per page"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /audit [get]
+func GetEventsHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := Filter{
+		EntityType: q.Get("entity_type"),
+		EntityID:   q.Get("entity_id"),
+		Action:     q.Get("action"),
+	}
+
+	var err error
+	filter.Page, err = strconv.Atoi(q.Get("page"))
+	if err != nil || filter.Page < 1 {
+		filter.Page = 1
+	}
+	filter.Limit, err = strconv.Atoi(q.Get("limit"))
+	if err != nil || filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+
+	if value := q.Get("actor_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			response.JSON(w, http.StatusBadRequest, false, "Invalid actor ID format", nil)
+			return
+		}
+		filter.ActorID = &id
+	}
+	if filter.From, err = parseTime(q.Get("from")); err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid from time, expected RFC 3339", nil)
+		return
+	}
+	if filter.To, err = parseTime(q.Get("to")); err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid to time, expected RFC 3339", nil)
+		return
+	}
+
+	events, err := GetEvents(filter)
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to fetch audit events", nil)
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "Audit events retrieved successfully", events)
+}
+
+// Helper to parse an optional RFC 3339 time
+func parseTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
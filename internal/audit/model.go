@@ -0,0 +1,50 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx/types"
+)
+
+// Actions recorded in the audit log
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionPublish = "publish"
+	ActionLink    = "link"
+	ActionUnlink  = "unlink"
+)
+
+// Event represents a single recorded content mutation
+type Event struct {
+	ID         int64          `db:"id" json:"id"`
+	Action     string         `db:"action" json:"action" example:"update"`
+	EntityType string         `db:"entity_type" json:"entity_type" example:"blog"`
+	EntityID   string         `db:"entity_id" json:"entity_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	ActorID    *uuid.UUID     `db:"actor_id" json:"actor_id,omitempty"`
+	APIKeyID   *uuid.UUID     `db:"api_key_id" json:"api_key_id,omitempty"`
+	RequestID  string         `db:"request_id" json:"request_id"`
+	IP         string         `db:"ip" json:"ip"`
+	Changes    types.JSONText `db:"changes" json:"changes" swaggertype:"object"`
+	CreatedAt  time.Time      `db:"created_at" json:"created_at"`
+}
+
+// Change holds the value of a field before and after a mutation
+type Change struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// Filter narrows down the events returned by GetEvents
+type Filter struct {
+	EntityType string
+	EntityID   string
+	ActorID    *uuid.UUID
+	Action     string
+	From       *time.Time
+	To         *time.Time
+	Page       int
+	Limit      int
+}
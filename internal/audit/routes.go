@@ -0,0 +1,12 @@
+package audit
+
+import (
+	"cms-project/internal/user"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterAuditRoutes registers all audit log routes
+func RegisterAuditRoutes(r *mux.Router) {
+	r.Handle("", user.Require(user.PermAuditRead, GetEventsHandler)).Methods("GET")
+}
@@ -0,0 +1,179 @@
+package audit
+
+import (
+	"cms-project/internal/database"
+	"cms-project/internal/user"
+	middleware "cms-project/pkg"
+	"cms-project/pkg/request"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/types"
+)
+
+// Actor identifies who made a mutation and the request it was made through
+type Actor struct {
+	UserID    *uuid.UUID
+	APIKeyID  *uuid.UUID
+	RequestID string
+	IP        string
+}
+
+// ActorFrom returns the actor of an authenticated request
+func ActorFrom(r *http.Request) Actor {
+	actor := Actor{RequestID: middleware.RequestID(r.Context()), IP: request.ClientIP(r)}
+	if u := user.FromContext(r.Context()); u != nil {
+		actor.UserID = &u.ID
+	}
+	if key := user.APIKeyFromContext(r.Context()); key != nil {
+		actor.APIKeyID = &key.ID
+	}
+	return actor
+}
+
+// Write appends an event for a mutation made by actor. Services pass the transaction of the
+// mutation so that the event is kept exactly when the mutation commits. before and after are the
+// entity before and after the change; either may be nil for creations and deletions.
+func Write(db sqlx.Execer, actor Actor, action, entityType, entityID string, before, after interface{}) error {
+	changes, err := diff(before, after)
+	if err != nil {
+		log.Printf("Error computing audit diff: %v", err)
+		return err
+	}
+
+	query := `
+		INSERT INTO audit_events (action, entity_type, entity_id, actor_id, api_key_id, request_id, ip, changes, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())`
+	_, err = db.Exec(query, action, entityType, entityID, actor.UserID, actor.APIKeyID, actor.RequestID, actor.IP, types.JSONText(changes))
+	if err != nil {
+		log.Printf("Error recording audit event: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetEvents retrieves audit events matching the filter, newest first
+func GetEvents(filter Filter) ([]Event, error) {
+	var conditions []string
+	var args []interface{}
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if filter.EntityType != "" {
+		add("entity_type = $%d", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		add("entity_id = $%d", filter.EntityID)
+	}
+	if filter.ActorID != nil {
+		add("actor_id = $%d", *filter.ActorID)
+	}
+	if filter.Action != "" {
+		add("action = $%d", filter.Action)
+	}
+	if filter.From != nil {
+		add("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		add("created_at < $%d", *filter.To)
+	}
+
+	query := "SELECT * FROM audit_events"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+	query += fmt.Sprintf(" ORDER BY id DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	events := []Event{}
+	if err := database.DB.Select(&events, query, args...); err != nil {
+		log.Printf("Error fetching audit events: %v", err)
+		return nil, err
+	}
+	return events, nil
+}
+
+// PurgeEvents deletes events older than the retention period
+func PurgeEvents(retention time.Duration) (int64, error) {
+	result, err := database.DB.Exec("DELETE FROM audit_events WHERE created_at < $1", time.Now().Add(-retention))
+	if err != nil {
+		log.Printf("Error purging audit events: %v", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// StartRetention purges expired events once a day when AUDIT_RETENTION_DAYS is set.
+// Without it events are kept forever.
+func StartRetention() {
+	days, err := strconv.Atoi(os.Getenv("AUDIT_RETENTION_DAYS"))
+	if err != nil || days <= 0 {
+		return
+	}
+	retention := time.Duration(days) * 24 * time.Hour
+
+	go func() {
+		for {
+			if n, err := PurgeEvents(retention); err == nil && n > 0 {
+				log.Printf("Purged %d audit events older than %d days", n, days)
+			}
+			time.Sleep(24 * time.Hour)
+		}
+	}()
+}
+
+// diff returns the fields that differ between before and after as a JSON object of Changes
+func diff(before, after interface{}) ([]byte, error) {
+	beforeFields, err := toFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := toFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]Change{}
+	for key, value := range beforeFields {
+		if other, ok := afterFields[key]; !ok || !reflect.DeepEqual(value, other) {
+			changes[key] = Change{Before: value, After: afterFields[key]}
+		}
+	}
+	for key, value := range afterFields {
+		if _, ok := beforeFields[key]; !ok {
+			changes[key] = Change{After: value}
+		}
+	}
+	return json.Marshal(changes)
+}
+
+// toFields flattens a value into its JSON fields
+func toFields(v interface{}) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return fields, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		// Not an object: record it as a single value
+		var value interface{}
+		if err := json.Unmarshal(data, &value); err != nil {
+			return nil, err
+		}
+		fields = map[string]interface{}{"value": value}
+	}
+	return fields, nil
+}
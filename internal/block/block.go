@@ -0,0 +1,225 @@
+package block
+
+import (
+	"bytes"
+	"cms-project/pkg/markup"
+	"database/sql/driver"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// Format is the content_format of blogs whose content is a list of blocks
+const Format = "blocks"
+
+// Block types
+const (
+	TypeParagraph = "paragraph"
+	TypeHeading   = "heading"
+	TypeImage     = "image"
+	TypeQuote     = "quote"
+	TypeCode      = "code"
+	TypeEmbed     = "embed"
+	TypeCallout   = "callout"
+	TypeHTML      = "html"
+)
+
+// maxBlocks limits the number of blocks in a single document
+const maxBlocks = 1000
+
+var (
+	ErrUnknownType = errors.New("unknown block type")
+	ErrTooMany     = fmt.Errorf("a document can have at most %d blocks", maxBlocks)
+)
+
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+var (
+	schemasOnce sync.Once
+	schemas     map[string]*jsonschema.Schema
+	schemasErr  error
+)
+
+// Block is a single typed piece of content. Data holds the type specific fields.
+type Block struct {
+	Type string          `json:"type" example:"paragraph"`
+	Data json.RawMessage `json:"data" swaggertype:"object"`
+}
+
+// Blocks is an ordered list of blocks stored as a JSONB column
+type Blocks []Block
+
+// ValidationError describes the block that failed validation
+type ValidationError struct {
+	Index int
+	Type  string
+	Err   error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("block %d (%s): %v", e.Index, e.Type, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Scan implements sql.Scanner for JSONB columns
+func (b *Blocks) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*b = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into Blocks", src)
+	}
+	return json.Unmarshal(data, b)
+}
+
+// Value implements driver.Valuer for JSONB columns
+func (b Blocks) Value() (driver.Value, error) {
+	if b == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Validate checks every block against the JSON schema of its type
+func (b Blocks) Validate() error {
+	if len(b) > maxBlocks {
+		return ErrTooMany
+	}
+	compiled, err := loadSchemas()
+	if err != nil {
+		log.Printf("Error loading block schemas: %v", err)
+		return err
+	}
+	for i, blk := range b {
+		schema, ok := compiled[blk.Type]
+		if !ok {
+			return &ValidationError{Index: i, Type: blk.Type, Err: ErrUnknownType}
+		}
+		var data interface{}
+		if err := json.Unmarshal(blk.Data, &data); err != nil {
+			return &ValidationError{Index: i, Type: blk.Type, Err: err}
+		}
+		if err := schema.Validate(data); err != nil {
+			return &ValidationError{Index: i, Type: blk.Type, Err: validationReason(err)}
+		}
+	}
+	return nil
+}
+
+// HTML renders the blocks to sanitized HTML with heading anchors and a table of contents
+func (b Blocks) HTML() (string, error) {
+	var buf strings.Builder
+	for _, blk := range b {
+		if err := render(&buf, blk); err != nil {
+			return "", err
+		}
+	}
+	return markup.Decorate(buf.String())
+}
+
+// Text renders the blocks to plain text
+func (b Blocks) Text() string {
+	var parts []string
+	for _, blk := range b {
+		var buf strings.Builder
+		if err := render(&buf, blk); err != nil {
+			continue
+		}
+		if text := markup.PlainText(buf.String()); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// FromContent converts content in one of the markup formats into blocks. Plain text becomes a
+// single paragraph, markdown and HTML a single HTML block.
+func FromContent(format, src string) (Blocks, error) {
+	if strings.TrimSpace(src) == "" {
+		return Blocks{}, nil
+	}
+	if format == markup.FormatPlain {
+		return Blocks{newBlock(TypeParagraph, paragraph{Text: html.EscapeString(strings.TrimSpace(src))})}, nil
+	}
+	converted, err := markup.Convert(format, src)
+	if err != nil {
+		return nil, err
+	}
+	return Blocks{newBlock(TypeHTML, rawHTML{HTML: converted})}, nil
+}
+
+// Helper to build a block from its typed data
+func newBlock(typ string, data interface{}) Block {
+	raw, _ := json.Marshal(data)
+	return Block{Type: typ, Data: raw}
+}
+
+// Helper to compile the embedded schemas once
+func loadSchemas() (map[string]*jsonschema.Schema, error) {
+	schemasOnce.Do(func() {
+		entries, err := schemaFiles.ReadDir("schemas")
+		if err != nil {
+			schemasErr = err
+			return
+		}
+		compiler := jsonschema.NewCompiler()
+		compiled := make(map[string]*jsonschema.Schema, len(entries))
+		for _, entry := range entries {
+			name := "schemas/" + entry.Name()
+			data, err := schemaFiles.ReadFile(name)
+			if err != nil {
+				schemasErr = err
+				return
+			}
+			if err := compiler.AddResource(name, bytes.NewReader(data)); err != nil {
+				schemasErr = err
+				return
+			}
+			schema, err := compiler.Compile(name)
+			if err != nil {
+				schemasErr = err
+				return
+			}
+			compiled[strings.TrimSuffix(entry.Name(), ".json")] = schema
+		}
+		schemas = compiled
+	})
+	return schemas, schemasErr
+}
+
+// Helper to reduce a schema validation error to its most specific cause
+func validationReason(err error) error {
+	var verr *jsonschema.ValidationError
+	if !errors.As(err, &verr) {
+		return err
+	}
+	for len(verr.Causes) > 0 {
+		verr = verr.Causes[0]
+	}
+	location := verr.InstanceLocation
+	if location == "" {
+		location = "/"
+	}
+	return fmt.Errorf("%s: %s", location, verr.Message)
+}
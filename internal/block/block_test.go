@@ -0,0 +1,203 @@
+package block
+
+import (
+	"cms-project/pkg/markup"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func blk(typ, data string) Block {
+	return Block{Type: typ, Data: json.RawMessage(data)}
+}
+
+func TestValidate(t *testing.T) {
+	valid := []Blocks{
+		{},
+		{
+			blk(TypeParagraph, `{"text":"Hello"}`),
+			blk(TypeHeading, `{"text":"Title","level":2}`),
+			blk(TypeImage, `{"url":"/uploads/a.png","alt":"A"}`),
+			blk(TypeQuote, `{"text":"Quote","cite":"Someone"}`),
+			blk(TypeCode, `{"code":"x := 1","language":"go"}`),
+			blk(TypeEmbed, `{"url":"https://www.youtube.com/watch?v=1"}`),
+			blk(TypeCallout, `{"text":"Note","tone":"warning"}`),
+			blk(TypeHTML, `{"html":"<p>Hi</p>"}`),
+		},
+	}
+	for _, blocks := range valid {
+		if err := blocks.Validate(); err != nil {
+			t.Errorf("Validate(%d blocks) = %v, want nil", len(blocks), err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		blocks Blocks
+		index  int
+	}{
+		{"unknown type", Blocks{blk(TypeParagraph, `{"text":"a"}`), blk("video", `{}`)}, 1},
+		{"missing field", Blocks{blk(TypeHeading, `{"text":"Title"}`)}, 0},
+		{"level out of range", Blocks{blk(TypeHeading, `{"text":"Title","level":7}`)}, 0},
+		{"extra field", Blocks{blk(TypeParagraph, `{"text":"a","style":"x"}`)}, 0},
+		{"javascript image", Blocks{blk(TypeImage, `{"url":"javascript:alert(1)","alt":""}`)}, 0},
+		{"protocol relative image", Blocks{blk(TypeImage, `{"url":"//evil.example/a.png","alt":""}`)}, 0},
+		{"backslash image", Blocks{blk(TypeImage, `{"url":"/\\evil.example/a.png","alt":""}`)}, 0},
+		{"relative embed", Blocks{blk(TypeParagraph, `{"text":"a"}`), blk(TypeEmbed, `{"url":"/video"}`)}, 1},
+		{"invalid json", Blocks{blk(TypeParagraph, `{"text":`)}, 0},
+	}
+	for _, tt := range tests {
+		var verr *ValidationError
+		if err := tt.blocks.Validate(); !errors.As(err, &verr) {
+			t.Errorf("%s: Validate = %v, want a ValidationError", tt.name, err)
+		} else if verr.Index != tt.index {
+			t.Errorf("%s: index = %d, want %d", tt.name, verr.Index, tt.index)
+		}
+	}
+	err := Blocks{blk("video", `{}`)}.Validate()
+	if !errors.Is(err, ErrUnknownType) {
+		t.Errorf("unknown type: Validate = %v, want ErrUnknownType", err)
+	}
+}
+
+func TestValidateTooMany(t *testing.T) {
+	blocks := make(Blocks, maxBlocks+1)
+	for i := range blocks {
+		blocks[i] = blk(TypeParagraph, `{"text":"a"}`)
+	}
+	if err := blocks.Validate(); !errors.Is(err, ErrTooMany) {
+		t.Errorf("Validate = %v, want ErrTooMany", err)
+	}
+}
+
+func TestHTML(t *testing.T) {
+	tests := []struct {
+		name      string
+		block     Block
+		want      string
+		forbidden []string
+	}{
+		{"paragraph keeps inline markup", blk(TypeParagraph, `{"text":"Hi <em>there</em><script>alert(1)</script>"}`),
+			"<p>Hi <em>there</em></p>", []string{"<script"}},
+		{"heading escaped and anchored", blk(TypeHeading, `{"text":"A <b>bold</b> title","level":3}`),
+			`<h3 id="a-b-bold-b-title">A &lt;b&gt;bold&lt;/b&gt; title<a class="heading-anchor"`, nil},
+		{"heading level clamped", blk(TypeHeading, `{"text":"Deep","level":9}`), `<h6 id="deep">`, nil},
+		{"image", blk(TypeImage, `{"url":"/uploads/a.png","alt":"A \"cat\"","caption":"Cat"}`),
+			`<figure><img src="/uploads/a.png" alt="A &#34;cat&#34;"/><figcaption>Cat</figcaption></figure>`, nil},
+		{"javascript image dropped", blk(TypeImage, `{"url":"javascript:alert(1)","alt":""}`), "", []string{"<img", "javascript:"}},
+		{"quote with cite", blk(TypeQuote, `{"text":"Wise","cite":"<Someone>"}`),
+			"<blockquote><p>Wise</p><footer><cite>&lt;Someone&gt;</cite></footer></blockquote>", nil},
+		{"code escaped", blk(TypeCode, `{"code":"<b>x</b>","language":"html"}`),
+			`<pre><code class="language-html">&lt;b&gt;x&lt;/b&gt;</code></pre>`, nil},
+		{"embed as link", blk(TypeEmbed, `{"url":"https://vimeo.com/1","provider":"vimeo"}`),
+			`<figure class="embed" data-provider="vimeo"><a href="https://vimeo.com/1" rel="nofollow noopener">https://vimeo.com/1</a></figure>`, nil},
+		{"protocol relative embed dropped", blk(TypeEmbed, `{"url":"//evil.example"}`), "", []string{"evil.example"}},
+		{"unknown tone falls back", blk(TypeCallout, `{"text":"Note","tone":"loud"}`), `<aside class="callout callout-info">`, nil},
+		{"html sanitized", blk(TypeHTML, `{"html":"<p onclick=\"x()\">Hi</p><iframe src=\"https://evil.example\"></iframe>"}`),
+			"<p>Hi</p>", []string{"onclick", "<iframe"}},
+	}
+	for _, tt := range tests {
+		got, err := Blocks{tt.block}.HTML()
+		if err != nil {
+			t.Errorf("%s: HTML error = %v", tt.name, err)
+			continue
+		}
+		if !strings.Contains(got, tt.want) {
+			t.Errorf("%s: HTML = %q, want it to contain %q", tt.name, got, tt.want)
+		}
+		for _, s := range tt.forbidden {
+			if strings.Contains(got, s) {
+				t.Errorf("%s: HTML = %q, must not contain %q", tt.name, got, s)
+			}
+		}
+	}
+}
+
+func TestHTMLTableOfContents(t *testing.T) {
+	got, err := Blocks{
+		blk(TypeHeading, `{"text":"One","level":2}`),
+		blk(TypeParagraph, `{"text":"a"}`),
+		blk(TypeHeading, `{"text":"Two","level":2}`),
+	}.HTML()
+	if err != nil {
+		t.Fatalf("HTML: %v", err)
+	}
+	if !strings.HasPrefix(got, `<nav class="toc">`) {
+		t.Errorf("HTML = %q, want a table of contents first", got)
+	}
+}
+
+func TestHTMLUnknownType(t *testing.T) {
+	if _, err := (Blocks{blk("video", `{}`)}).HTML(); !errors.Is(err, ErrUnknownType) {
+		t.Errorf("HTML error = %v, want ErrUnknownType", err)
+	}
+}
+
+func TestText(t *testing.T) {
+	blocks := Blocks{
+		blk(TypeHeading, `{"text":"Title","level":1}`),
+		blk(TypeParagraph, `{"text":"Some <strong>bold</strong> text"}`),
+		blk("video", `{}`),
+		blk(TypeImage, `{"url":"/a.png","alt":"ignored"}`),
+		blk(TypeCode, `{"code":"x := 1"}`),
+	}
+	if got, want := blocks.Text(), "Title\n\nSome bold text\n\nx := 1"; got != want {
+		t.Errorf("Text = %q, want %q", got, want)
+	}
+}
+
+func TestFromContent(t *testing.T) {
+	tests := []struct {
+		name, format, src string
+		want              Blocks
+	}{
+		{"blank", markup.FormatMarkdown, "  \n", Blocks{}},
+		{"plain", markup.FormatPlain, " a < b \n", Blocks{blk(TypeParagraph, `{"text":"a &lt; b"}`)}},
+		{"markdown", markup.FormatMarkdown, "# Hi\n\n<script>x</script>", Blocks{blk(TypeHTML, `{"html":"<h1>Hi</h1>\n"}`)}},
+		{"html", markup.FormatHTML, `<p onclick="x">Hi</p>`, Blocks{blk(TypeHTML, `{"html":"<p>Hi</p>"}`)}},
+	}
+	for _, tt := range tests {
+		got, err := FromContent(tt.format, tt.src)
+		if err != nil {
+			t.Errorf("%s: FromContent error = %v", tt.name, err)
+			continue
+		}
+		gotJSON, _ := json.Marshal(got)
+		wantJSON, _ := json.Marshal(tt.want)
+		if string(gotJSON) != string(wantJSON) {
+			t.Errorf("%s: FromContent = %s, want %s", tt.name, gotJSON, wantJSON)
+		}
+		if err := got.Validate(); err != nil {
+			t.Errorf("%s: converted blocks do not validate: %v", tt.name, err)
+		}
+	}
+	if _, err := FromContent("rst", "text"); !errors.Is(err, markup.ErrUnknownFormat) {
+		t.Errorf("FromContent error = %v, want ErrUnknownFormat", err)
+	}
+}
+
+func TestSafeURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://example.com/a.png", true},
+		{"http://example.com/a.png", true},
+		{"/uploads/a.png", true},
+		{"javascript:alert(1)", false},
+		{"JaVaScRiPt:alert(1)", false},
+		{"data:image/png;base64,AAAA", false},
+		{"vbscript:msgbox(1)", false},
+		{"//evil.example/a.png", false},
+		{`/\evil.example/a.png`, false},
+		{" https://example.com", false},
+		{"uploads/a.png", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := safeURL(tt.url); got != tt.want {
+			t.Errorf("safeURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
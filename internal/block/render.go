@@ -0,0 +1,153 @@
+package block
+
+import (
+	"cms-project/pkg/markup"
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+)
+
+// calloutTones are the tones a callout can have, the first one is the default
+var calloutTones = []string{"info", "success", "warning", "danger"}
+
+type paragraph struct {
+	Text string `json:"text"`
+}
+
+type heading struct {
+	Text  string `json:"text"`
+	Level int    `json:"level"`
+}
+
+type image struct {
+	URL     string `json:"url"`
+	Alt     string `json:"alt"`
+	Caption string `json:"caption,omitempty"`
+}
+
+type quote struct {
+	Text string `json:"text"`
+	Cite string `json:"cite,omitempty"`
+}
+
+type code struct {
+	Code     string `json:"code"`
+	Language string `json:"language,omitempty"`
+}
+
+type embedLink struct {
+	URL      string `json:"url"`
+	Provider string `json:"provider,omitempty"`
+	Caption  string `json:"caption,omitempty"`
+}
+
+type callout struct {
+	Text string `json:"text"`
+	Tone string `json:"tone,omitempty"`
+}
+
+type rawHTML struct {
+	HTML string `json:"html"`
+}
+
+// render writes the HTML of a single block. Markup written by users is sanitized, the surrounding
+// elements are generated here and their attributes escaped.
+func render(b *strings.Builder, blk Block) error {
+	switch blk.Type {
+	case TypeParagraph:
+		var d paragraph
+		if err := json.Unmarshal(blk.Data, &d); err != nil {
+			return err
+		}
+		fmt.Fprintf(b, "<p>%s</p>\n", markup.Sanitize(d.Text))
+	case TypeHeading:
+		var d heading
+		if err := json.Unmarshal(blk.Data, &d); err != nil {
+			return err
+		}
+		level := min(max(d.Level, 1), 6)
+		fmt.Fprintf(b, "<h%d>%s</h%d>\n", level, html.EscapeString(d.Text), level)
+	case TypeImage:
+		var d image
+		if err := json.Unmarshal(blk.Data, &d); err != nil {
+			return err
+		}
+		if !safeURL(d.URL) {
+			return nil
+		}
+		fmt.Fprintf(b, `<figure><img src="%s" alt="%s">`, html.EscapeString(d.URL), html.EscapeString(d.Alt))
+		if d.Caption != "" {
+			fmt.Fprintf(b, "<figcaption>%s</figcaption>", html.EscapeString(d.Caption))
+		}
+		b.WriteString("</figure>\n")
+	case TypeQuote:
+		var d quote
+		if err := json.Unmarshal(blk.Data, &d); err != nil {
+			return err
+		}
+		fmt.Fprintf(b, "<blockquote><p>%s</p>", markup.Sanitize(d.Text))
+		if d.Cite != "" {
+			fmt.Fprintf(b, "<footer><cite>%s</cite></footer>", html.EscapeString(d.Cite))
+		}
+		b.WriteString("</blockquote>\n")
+	case TypeCode:
+		var d code
+		if err := json.Unmarshal(blk.Data, &d); err != nil {
+			return err
+		}
+		if d.Language != "" {
+			fmt.Fprintf(b, `<pre><code class="language-%s">%s</code></pre>`+"\n", html.EscapeString(d.Language), html.EscapeString(d.Code))
+		} else {
+			fmt.Fprintf(b, "<pre><code>%s</code></pre>\n", html.EscapeString(d.Code))
+		}
+	case TypeEmbed:
+		var d embedLink
+		if err := json.Unmarshal(blk.Data, &d); err != nil {
+			return err
+		}
+		if !safeURL(d.URL) {
+			return nil
+		}
+		// Embeds are rendered as links; frontends may upgrade them to players by provider
+		b.WriteString(`<figure class="embed"`)
+		if d.Provider != "" {
+			fmt.Fprintf(b, ` data-provider="%s"`, html.EscapeString(d.Provider))
+		}
+		b.WriteString(">")
+		fmt.Fprintf(b, `<a href="%s" rel="nofollow noopener">%s</a>`, html.EscapeString(d.URL), html.EscapeString(d.URL))
+		if d.Caption != "" {
+			fmt.Fprintf(b, "<figcaption>%s</figcaption>", html.EscapeString(d.Caption))
+		}
+		b.WriteString("</figure>\n")
+	case TypeCallout:
+		var d callout
+		if err := json.Unmarshal(blk.Data, &d); err != nil {
+			return err
+		}
+		tone := calloutTones[0]
+		for _, t := range calloutTones {
+			if d.Tone == t {
+				tone = t
+			}
+		}
+		fmt.Fprintf(b, `<aside class="callout callout-%s"><p>%s</p></aside>`+"\n", tone, markup.Sanitize(d.Text))
+	case TypeHTML:
+		var d rawHTML
+		if err := json.Unmarshal(blk.Data, &d); err != nil {
+			return err
+		}
+		b.WriteString(markup.Sanitize(d.HTML))
+		b.WriteString("\n")
+	default:
+		return ErrUnknownType
+	}
+	return nil
+}
+
+// safeURL reports whether a URL is absolute http(s) or site relative, rejecting script schemes and
+// protocol relative URLs, which browsers also recognise as /\host
+func safeURL(u string) bool {
+	return strings.HasPrefix(u, "https://") || strings.HasPrefix(u, "http://") ||
+		(strings.HasPrefix(u, "/") && !strings.HasPrefix(u, "//") && !strings.HasPrefix(u, `/\`))
+}
@@ -0,0 +1,107 @@
+package blog
+
+import (
+	"cms-project/internal/block"
+	"cms-project/pkg/markup"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Defaults for the generated metadata, overridable with BLOG_EXCERPT_LENGTH and BLOG_WORDS_PER_MINUTE
+const (
+	defaultExcerptLength  = 200
+	defaultWordsPerMinute = 200
+)
+
+// ErrUnknownContentFormat is returned for content formats blogs cannot be written in
+var ErrUnknownContentFormat = errors.New("content_format must be one of markdown, html, plain or blocks")
+
+// validateContent checks the content format and, for block content, every block
+func validateContent(req *CreateBlogRequest) error {
+	if req.ContentFormat == block.Format {
+		return req.Blocks.Validate()
+	}
+	if !markup.ValidFormat(req.ContentFormat) {
+		return ErrUnknownContentFormat
+	}
+	return nil
+}
+
+// renderContent stores the sanitized HTML rendering of the blog content. Block content is also
+// rendered to plain text for Content, other formats are kept as a single block for block editors.
+func renderContent(blog *Blog) error {
+	if blog.ContentFormat == block.Format {
+		html, err := blog.Blocks.HTML()
+		if err != nil {
+			log.Printf("Error rendering blog blocks: %v", err)
+			return err
+		}
+		blog.Content = blog.Blocks.Text()
+		blog.ContentHTML = html
+		computeMetadata(blog, blog.Content)
+		return nil
+	}
+
+	html, err := markup.Render(blog.ContentFormat, blog.Content)
+	if err != nil {
+		log.Printf("Error rendering blog content: %v", err)
+		return err
+	}
+	blocks, err := block.FromContent(blog.ContentFormat, blog.Content)
+	if err != nil {
+		log.Printf("Error converting blog content to blocks: %v", err)
+		return err
+	}
+	blog.ContentHTML = html
+	blog.Blocks = blocks
+	computeMetadata(blog, markup.PlainText(html))
+	return nil
+}
+
+// computeMetadata sets the excerpt, word count and reading time from the plain text of a blog
+func computeMetadata(blog *Blog, text string) {
+	words := strings.Fields(text)
+	blog.WordCount = len(words)
+	blog.ReadingTimeMinutes = 0
+	if len(words) > 0 {
+		wpm := intFromEnv("BLOG_WORDS_PER_MINUTE", defaultWordsPerMinute)
+		blog.ReadingTimeMinutes = (len(words) + wpm - 1) / wpm
+	}
+
+	blog.CustomExcerpt = strings.TrimSpace(blog.CustomExcerpt)
+	if blog.CustomExcerpt != "" {
+		blog.Excerpt = blog.CustomExcerpt
+		return
+	}
+	blog.Excerpt = truncate(strings.Join(words, " "), intFromEnv("BLOG_EXCERPT_LENGTH", defaultExcerptLength))
+}
+
+// truncate shortens text to at most n characters, cutting at a word boundary and adding an ellipsis
+func truncate(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	cut := n
+	for cut > 0 && !unicode.IsSpace(runes[cut]) {
+		cut--
+	}
+	if cut == 0 {
+		cut = n // a single long word is cut in the middle
+	}
+	return strings.TrimRightFunc(string(runes[:cut]), func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	}) + "…"
+}
+
+// Helper to read a positive integer setting from the environment
+func intFromEnv(key string, fallback int) int {
+	if n, err := strconv.Atoi(os.Getenv(key)); err == nil && n > 0 {
+		return n
+	}
+	return fallback
+}
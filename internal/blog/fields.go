@@ -0,0 +1,140 @@
+package blog
+
+import (
+	"cms-project/internal/category"
+	"cms-project/internal/tag"
+	"cms-project/pkg/fields"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// Relations that can be embedded with ?include=
+const (
+	includeCategories = "categories"
+	includeTags       = "tags"
+	includeAuthor     = "author"
+)
+
+var includable = map[string]bool{includeCategories: true, includeTags: true, includeAuthor: true}
+
+// responseShape is the response shape requested with ?fields=, ?include= and ?view=
+type responseShape struct {
+	fields  []string
+	include map[string]bool
+}
+
+// parseShape reads the requested response shape, checking fields against the JSON fields of v
+func parseShape(r *http.Request, v interface{}) (responseShape, error) {
+	q := r.URL.Query()
+	s := responseShape{fields: fields.Parse(q.Get("fields")), include: make(map[string]bool)}
+	if name := fields.Unknown(s.fields, fields.Names(v)); name != "" {
+		return s, fmt.Errorf("unknown field %q", name)
+	}
+	includes := fields.Parse(q.Get("include"))
+	if name := fields.Unknown(includes, includable); name != "" {
+		return s, fmt.Errorf("unknown relation %q, use categories, tags or author", name)
+	}
+	for _, name := range includes {
+		s.include[name] = true
+	}
+	if len(s.fields) == 0 && q.Get("view") == "summary" {
+		for name := range fields.Names(BlogSummary{}) {
+			s.fields = append(s.fields, name)
+		}
+	}
+	return s, nil
+}
+
+// full reports whether the default, unfiltered response was requested
+func (s responseShape) full() bool {
+	return len(s.fields) == 0 && len(s.include) == 0
+}
+
+// wants reports whether a field is part of the response
+func (s responseShape) wants(name string) bool {
+	if len(s.fields) == 0 {
+		return true
+	}
+	for _, f := range s.fields {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// apply filters items to the requested fields and embeds the included relations of the matching
+// blogs. Each relation is loaded with a single query for all blogs.
+func (s responseShape) apply(items []interface{}, blogs []Blog) ([]map[string]json.RawMessage, error) {
+	var categories map[uuid.UUID][]category.Category
+	var tags map[uuid.UUID][]tag.Tag
+	var authors map[string]Author
+	var err error
+	ids := make([]uuid.UUID, 0, len(blogs))
+	for _, b := range blogs {
+		ids = append(ids, b.ID)
+	}
+	if s.include[includeCategories] {
+		if categories, err = GetCategoriesForBlogs(ids); err != nil {
+			return nil, err
+		}
+	}
+	if s.include[includeTags] {
+		if tags, err = tag.GetTagsForBlogs(ids); err != nil {
+			return nil, err
+		}
+	}
+	if s.include[includeAuthor] {
+		if authors, err = GetAuthorsForBlogs(blogs); err != nil {
+			return nil, err
+		}
+	}
+
+	shaped := make([]map[string]json.RawMessage, 0, len(items))
+	for i, item := range items {
+		m, err := fields.Select(item, s.fields)
+		if err != nil {
+			return nil, err
+		}
+		if s.include[includeCategories] {
+			list := categories[blogs[i].ID]
+			if list == nil {
+				list = []category.Category{}
+			}
+			if m[includeCategories], err = json.Marshal(list); err != nil {
+				return nil, err
+			}
+		}
+		if s.include[includeTags] {
+			list := tags[blogs[i].ID]
+			if list == nil {
+				list = []tag.Tag{}
+			}
+			if m[includeTags], err = json.Marshal(list); err != nil {
+				return nil, err
+			}
+		}
+		if s.include[includeAuthor] {
+			m[includeAuthor] = json.RawMessage("null")
+			if author, ok := authors[blogs[i].AuthorID]; ok {
+				if m[includeAuthor], err = json.Marshal(author); err != nil {
+					return nil, err
+				}
+			}
+		}
+		shaped = append(shaped, m)
+	}
+	return shaped, nil
+}
+
+// applyList shapes a list of blogs
+func (s responseShape) applyList(blogs []Blog) ([]map[string]json.RawMessage, error) {
+	items := make([]interface{}, 0, len(blogs))
+	for _, b := range blogs {
+		items = append(items, b)
+	}
+	return s.apply(items, blogs)
+}
@@ -1,10 +1,19 @@
 package blog
 
 import (
+	"cms-project/internal/audit"
+	"cms-project/internal/block"
+	"cms-project/internal/media"
+	"cms-project/internal/tag"
+	"cms-project/internal/user"
+	"cms-project/internal/workflow"
+	"cms-project/pkg/markup"
 	"cms-project/pkg/response"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
@@ -12,32 +21,52 @@ import (
 
 // GetBlogsHandler handles retrieving all blogs
 // @Summary Get all blogs
-// @Description Retrieve all blogs with pagination
+// @Description Retrieve blogs with pagination. Anonymous requests and users without blogs:read only get published blogs.
 // @Tags Blog
+// @Security BearerAuth
 // @Param page query int false "Page number"
 // @Param limit query int false "Number of blogs per page"
+// @Param view query string false "Set to summary to return excerpts instead of content" Enums(full, summary)
+// @Param fields query string false "Comma separated fields to return, e.g. id,title,slug"
+// @Param include query string false "Comma separated relations to embed: categories, tags, author"
+// @Param tag query string false "Only blogs with the tag of this slug"
 // @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
 // @Failure 500 {object} response.APIResponse
 // @Router /blogs [get]
 func GetBlogsHandler(w http.ResponseWriter, r *http.Request) {
 	page := getIntQueryParam(r, "page", 1)
 	limit := getIntQueryParam(r, "limit", 10)
+	shape, err := parseShape(r, Blog{})
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
+		return
+	}
 
-	blogs, err := GetBlogs(page, limit)
+	var blogs []Blog
+	if tagSlug := r.URL.Query().Get("tag"); tagSlug != "" {
+		blogs, err = GetBlogsByTag(tagSlug, visibleStatus(r), page, limit)
+	} else {
+		blogs, err = GetBlogs(visibleStatus(r), page, limit)
+	}
 	if err != nil {
 		response.JSON(w, http.StatusInternalServerError, false, "Failed to fetch blogs", nil)
 		return
 	}
-	response.JSON(w, http.StatusOK, true, "Blogs retrieved successfully", blogs)
+	writeBlogList(w, shape, blogs)
 }
 
 // CreateBlogHandler handles creating a new blog
 // @Summary Create anew blog
 // @Description Add a new blog with title and content to the database
 // @Tags Blog
+// @Security BearerAuth
 // @Param blog body blog.CreateBlogRequest  true "Blog data"
 // @Success 201 {object} response.APIResponse
 // @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
 // @Failure 500 {object} response.APIResponse
 // @Router /blogs [post]
 func CreateBlogHandler(w http.ResponseWriter, r *http.Request) {
@@ -47,9 +76,54 @@ func CreateBlogHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.Blocks != nil {
+		req.ContentFormat = block.Format
+	}
+	if req.ContentFormat == "" {
+		req.ContentFormat = markup.FormatMarkdown
+	}
+	if err := validateContent(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
+		return
+	}
+	if err := validateSEO(&req.SEO); err != nil {
+		response.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
+		return
+	}
+	if err := tag.Validate(req.Tags); err != nil {
+		response.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
+		return
+	}
+	if msg := validateCover(req.CoverMediaID); msg != "" {
+		response.JSON(w, http.StatusBadRequest, false, msg, nil)
+		return
+	}
+
+	// New blogs start in the initial workflow state and move to the requested one through a transition
+	author := user.FromContext(r.Context())
+	if req.Status == "" {
+		req.Status = workflow.Initial()
+	}
+	if missing := authorizeCreate(permissionCheck(r), req.Status); missing != "" {
+		user.Forbidden(w, missing)
+		return
+	}
+	if req.Status != workflow.Initial() {
+		if err := workflow.Check(author.Role, workflow.Initial(), req.Status, ""); err != nil {
+			writeWorkflowError(w, err)
+			return
+		}
+	}
+
+	// The author is always the authenticated user, never taken from the body
+	req.AuthorID = author.ID.String()
 	blog := Blog{CreateBlogRequest: req}
 
-	if err := CreateBlog(blog); err != nil {
+	if err := CreateBlog(&blog, audit.ActorFrom(r)); err != nil {
+		if errors.Is(err, ErrSlugTaken) {
+			response.JSON(w, http.StatusConflict, false, err.Error(), nil)
+			return
+		}
 		response.JSON(w, http.StatusInternalServerError, false, "Failed to create blog", nil)
 		return
 	}
@@ -58,11 +132,15 @@ func CreateBlogHandler(w http.ResponseWriter, r *http.Request) {
 
 // GetBlogByIDHandler handles retrieving a single blog by ID
 // @Summary Get a blog by ID
-// @Description Retrieve a specific blog using its ID
+// @Description Retrieve a specific blog using its ID. Blogs that are not published need blogs:read.
 // @Tags Blog
+// @Security BearerAuth
 // @Param id path int true "Blog ID"
+// @Param fields query string false "Comma separated fields to return, e.g. id,title,breadcrumb"
+// @Param include query string false "Comma separated relations to embed: categories, tags, author"
 // @Success 200 {object} response.APIResponse
 // @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
 // @Failure 404 {object} response.APIResponse
 // @Router /blogs/{id} [get]
 func GetBlogByIDHandler(w http.ResponseWriter, r *http.Request) {
@@ -72,20 +150,76 @@ func GetBlogByIDHandler(w http.ResponseWriter, r *http.Request) {
 		response.JSON(w, http.StatusBadRequest, false, "Invalid blog ID format", nil)
 		return
 	}
+	shape, err := parseShape(r, BlogDetail{})
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
+		return
+	}
 
 	blog, err := GetBlogByID(id)
-	if err != nil {
+	// Unpublished blogs are hidden from readers without blogs:read
+	if err != nil || (blog.Status != workflow.Published && visibleStatus(r) != "") {
 		response.JSON(w, http.StatusNotFound, false, "Blog not found", nil)
 		return
 	}
 
-	response.JSON(w, http.StatusOK, true, "Blog retrieved successfully", blog)
+	detail := BlogDetail{Blog: *blog}
+	if shape.wants("breadcrumb") {
+		if detail.Breadcrumb, err = GetBlogBreadcrumb(blog); err != nil {
+			response.JSON(w, http.StatusInternalServerError, false, "Failed to build blog breadcrumb", nil)
+			return
+		}
+	}
+	if shape.full() {
+		response.JSON(w, http.StatusOK, true, "Blog retrieved successfully", detail)
+		return
+	}
+
+	shaped, err := shape.apply([]interface{}{detail}, []Blog{*blog})
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to load blog relations", nil)
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "Blog retrieved successfully", shaped[0])
+}
+
+// GetBlogMetaHandler handles retrieving the head metadata of a blog
+// @Summary Get blog metadata
+// @Description Retrieve the title, canonical URL, meta tags (description, robots, Open Graph, Twitter card) and schema.org BlogPosting JSON-LD of a blog, also rendered as HTML. Empty SEO fields fall back to the title, excerpt, public URL and cover image.
+// @Tags Blog
+// @Security BearerAuth
+// @Param id path string true "Blog ID"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /blogs/{id}/meta [get]
+func GetBlogMetaHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid blog ID format", nil)
+		return
+	}
+	blog, err := GetBlogByID(id)
+	if err != nil {
+		response.JSON(w, http.StatusNotFound, false, "Blog not found", nil)
+		return
+	}
+	meta, err := GetBlogMeta(blog)
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to build blog metadata", nil)
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "Blog metadata retrieved successfully", meta)
 }
 
 // DeleteBlogHandler handles deleting a blog by ID
 // @Summary Delete a blog
 // @Description Remove a blog from the database
 // @Tags Blog
+// @Security BearerAuth
 // @Param id path int true "Blog ID"
 // @Success 204 {object} response.APIResponse
 // @Failure 400 {object} response.APIResponse
@@ -99,7 +233,17 @@ func DeleteBlogHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := DeleteBlog(id); err != nil {
+	existing, err := GetBlogByID(id)
+	if err != nil {
+		response.JSON(w, http.StatusNotFound, false, "Blog not found", nil)
+		return
+	}
+	if missing := authorizeDelete(permissionCheck(r), currentUserID(r), existing); missing != "" {
+		user.Forbidden(w, missing)
+		return
+	}
+
+	if err := DeleteBlog(existing, audit.ActorFrom(r)); err != nil {
 		response.JSON(w, http.StatusInternalServerError, false, "Failed to delete blog", nil)
 		return
 	}
@@ -110,6 +254,7 @@ func DeleteBlogHandler(w http.ResponseWriter, r *http.Request) {
 // @Summary Update a blog
 // @Description Update a blog's title and content using its ID
 // @Tags Blog
+// @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Param id path int true "Blog ID"
@@ -117,6 +262,8 @@ func DeleteBlogHandler(w http.ResponseWriter, r *http.Request) {
 // @Success 200 {object} response.APIResponse
 // @Failure 400 {object} response.APIResponse
 // @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Failure 423 {object} response.APIResponse
 // @Failure 500 {object} response.APIResponse
 // @Router /blogs/{id} [put]
 func UpdateBlogHandler(w http.ResponseWriter, r *http.Request) {
@@ -133,12 +280,63 @@ func UpdateBlogHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	existing, err := GetBlogByID(id)
+	if err != nil {
+		response.JSON(w, http.StatusNotFound, false, "Blog not found", nil)
+		return
+	}
+	if req.Status == "" {
+		req.Status = existing.Status
+	}
+	switch {
+	case req.Blocks != nil:
+		req.ContentFormat = block.Format
+	case req.ContentFormat == "" && existing.ContentFormat == block.Format:
+		// Block content is kept unless new blocks are sent
+		req.ContentFormat = block.Format
+		req.Blocks = existing.Blocks
+	case req.ContentFormat == "":
+		req.ContentFormat = existing.ContentFormat
+	}
+	if err := validateContent(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
+		return
+	}
+	if err := validateSEO(&req.SEO); err != nil {
+		response.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
+		return
+	}
+	if err := tag.Validate(req.Tags); err != nil {
+		response.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
+		return
+	}
+	if msg := validateCover(req.CoverMediaID); msg != "" {
+		response.JSON(w, http.StatusBadRequest, false, msg, nil)
+		return
+	}
+	if req.Status != existing.Status {
+		response.JSON(w, http.StatusBadRequest, false, "Status can only be changed through POST /blogs/{id}/transitions", nil)
+		return
+	}
+	if missing := authorizeUpdate(permissionCheck(r), currentUserID(r), existing); missing != "" {
+		user.Forbidden(w, missing)
+		return
+	}
+	if err := checkLock(id, currentUserID(r)); err != nil {
+		writeLockError(w, err, "Failed to check blog lock")
+		return
+	}
+
 	blog := Blog{
 		ID:                id,
 		CreateBlogRequest: req,
 	}
 
-	if err := UpdateBlog(blog); err != nil {
+	if err := UpdateBlog(&blog, existing, audit.ActorFrom(r)); err != nil {
+		if errors.Is(err, ErrSlugTaken) {
+			response.JSON(w, http.StatusConflict, false, err.Error(), nil)
+			return
+		}
 		response.JSON(w, http.StatusInternalServerError, false, "Failed to update blog", nil)
 		return
 	}
@@ -150,11 +348,16 @@ func UpdateBlogHandler(w http.ResponseWriter, r *http.Request) {
 // @Summary Search blogs
 // @Description Search blogs by title or content using a keyword
 // @Tags Blog
+// @Security BearerAuth
 // @Param keyword query string true "Keyword to search for"
 // @Param page query int false "Page number"
 // @Param limit query int false "Number of blogs per page"
+// @Param view query string false "Set to summary to return excerpts instead of content" Enums(full, summary)
+// @Param fields query string false "Comma separated fields to return, e.g. id,title,slug"
+// @Param include query string false "Comma separated relations to embed: categories, tags, author"
 // @Success 200 {object} response.APIResponse
 // @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
 // @Failure 500 {object} response.APIResponse
 // @Router /blogs/search [get]
 func SearchBlogsHandler(w http.ResponseWriter, r *http.Request) {
@@ -173,44 +376,60 @@ func SearchBlogsHandler(w http.ResponseWriter, r *http.Request) {
 	if err != nil || limit < 1 {
 		limit = 10
 	}
+	shape, err := parseShape(r, Blog{})
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
+		return
+	}
 
 	// Call service
-	blogs, err := SearchBlogs(keyword, page, limit)
+	blogs, err := SearchBlogs(keyword, visibleStatus(r), page, limit)
 	if err != nil {
 		response.JSON(w, http.StatusInternalServerError, false, "Failed to search blogs", nil)
 		return
 	}
 
 	// Success response
-	response.JSON(w, http.StatusOK, true, "Blogs retrieved successfully", blogs)
+	writeBlogList(w, shape, blogs)
 }
 
 // AddCategoryToBlogHandler handles adding a category to a blog
 // @Summary Add a category to a blog
 // @Description Associate a category with a blog
 // @Tags Blog
+// @Security BearerAuth
 // @Param blog body blog.CreateBlogRequest  true "Blog data"
-// @Param id path int true "Blog ID"
-// @Param category_id query int true "Category ID"
+// @Param id path string true "Blog ID"
+// @Param category_id query string true "Category ID"
 // @Success 200 {object} response.APIResponse
 // @Failure 400 {object} response.APIResponse
 // @Failure 500 {object} response.APIResponse
 // @Router /blogs/{id}/categories [post]
 func AddCategoryToBlogHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	blogID, err := strconv.Atoi(vars["id"])
+	blogID, err := uuid.Parse(vars["id"])
 	if err != nil {
-		response.JSON(w, http.StatusBadRequest, false, "Invalid blog ID", nil)
+		response.JSON(w, http.StatusBadRequest, false, "Invalid blog ID format", nil)
 		return
 	}
 
-	categoryID, err := strconv.Atoi(r.URL.Query().Get("category_id"))
+	categoryID, err := uuid.Parse(r.URL.Query().Get("category_id"))
 	if err != nil {
-		response.JSON(w, http.StatusBadRequest, false, "Invalid category ID", nil)
+		response.JSON(w, http.StatusBadRequest, false, "Invalid category ID format", nil)
+		return
+	}
+
+	existing, err := GetBlogByID(blogID)
+	if err != nil {
+		response.JSON(w, http.StatusNotFound, false, "Blog not found", nil)
+		return
+	}
+	if missing := authorizeUpdate(permissionCheck(r), currentUserID(r), existing); missing != "" {
+		user.Forbidden(w, missing)
 		return
 	}
 
-	if err := AddCategoryToBlog(blogID, categoryID); err != nil {
+	if err := AddCategoryToBlog(blogID, categoryID, audit.ActorFrom(r)); err != nil {
 		response.JSON(w, http.StatusInternalServerError, false, "Failed to add category to blog", nil)
 		return
 	}
@@ -242,9 +461,330 @@ func RemoveCategoryFromBlogHandler(w http.ResponseWriter, r *http.Request) {
 		response.JSON(w, http.StatusBadRequest, false, "Invalid category ID format", nil)
 		return
 	}
-	if err := RemoveCategoryFromBlog(blogID, categoryID); err != nil {
+
+	existing, err := GetBlogByID(blogID)
+	if err != nil {
+		response.JSON(w, http.StatusNotFound, false, "Blog not found", nil)
+		return
+	}
+	if missing := authorizeUpdate(permissionCheck(r), currentUserID(r), existing); missing != "" {
+		user.Forbidden(w, missing)
+		return
+	}
+
+	if err := RemoveCategoryFromBlog(blogID, categoryID, audit.ActorFrom(r)); err != nil {
 		response.JSON(w, http.StatusInternalServerError, false, "Failed to remove category from blog", nil)
 		return
 	}
 	response.JSON(w, http.StatusOK, true, "Category removed from blog successfully", nil)
 }
+
+// TransitionBlogHandler handles moving a blog to another workflow state
+// @Summary Transition a blog
+// @Description Move a blog to another workflow state such as in_review, approved or published. Illegal moves are rejected.
+// @Tags Blog
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Blog ID"
+// @Param transition body blog.TransitionRequest true "Target state and reviewer comment"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Failure 422 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /blogs/{id}/transitions [post]
+func TransitionBlogHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid blog ID format", nil)
+		return
+	}
+
+	var req TransitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid input", nil)
+		return
+	}
+
+	existing, err := GetBlogByID(id)
+	if err != nil {
+		response.JSON(w, http.StatusNotFound, false, "Blog not found", nil)
+		return
+	}
+	actor := user.FromContext(r.Context())
+	if missing := authorizeTransition(permissionCheck(r), actor.ID.String(), existing, req.To); missing != "" {
+		user.Forbidden(w, missing)
+		return
+	}
+	if err := workflow.Check(actor.Role, existing.Status, req.To, req.Comment); err != nil {
+		writeWorkflowError(w, err)
+		return
+	}
+
+	transition, err := TransitionBlog(id, existing.Status, req.To, audit.ActorFrom(r), req.Comment)
+	if err != nil {
+		if errors.Is(err, ErrStatusChanged) {
+			response.JSON(w, http.StatusConflict, false, "Blog status changed in the meantime, reload and try again", nil)
+			return
+		}
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to transition blog", nil)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, true, "Blog transitioned successfully", transition)
+}
+
+// GetBlogTransitionsHandler handles retrieving the workflow history of a blog
+// @Summary Get blog transitions
+// @Description Retrieve the workflow history of a blog with reviewer comments, together with the transitions the current user may perform next
+// @Tags Blog
+// @Security BearerAuth
+// @Param id path string true "Blog ID"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /blogs/{id}/transitions [get]
+func GetBlogTransitionsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid blog ID format", nil)
+		return
+	}
+
+	existing, err := GetBlogByID(id)
+	if err != nil {
+		response.JSON(w, http.StatusNotFound, false, "Blog not found", nil)
+		return
+	}
+	history, err := GetBlogTransitions(id)
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to fetch blog transitions", nil)
+		return
+	}
+
+	// Only offer the moves both the workflow and the permissions of the current user allow
+	actor := user.FromContext(r.Context())
+	available := []workflow.Transition{}
+	for _, t := range workflow.Available(actor.Role, existing.Status) {
+		if authorizeTransition(permissionCheck(r), actor.ID.String(), existing, t.To) == "" {
+			available = append(available, t)
+		}
+	}
+	response.JSON(w, http.StatusOK, true, "Blog transitions retrieved successfully", BlogTransitions{History: history, Available: available})
+}
+
+// GetBlogLockHandler handles retrieving the edit lock of a blog
+// @Summary Get blog edit lock
+// @Description Show who is currently editing a blog; data is null when nobody is
+// @Tags Blog
+// @Security BearerAuth
+// @Param id path string true "Blog ID"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /blogs/{id}/lock [get]
+func GetBlogLockHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid blog ID format", nil)
+		return
+	}
+	lock, err := GetActiveLock(id)
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to fetch blog lock", nil)
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "Blog lock retrieved successfully", lock)
+}
+
+// AcquireBlogLockHandler handles taking the edit lock of a blog
+// @Summary Acquire blog edit lock
+// @Description Take the advisory edit lock of a blog. The lock expires unless renewed with a heartbeat.
+// @Tags Blog
+// @Security BearerAuth
+// @Param id path string true "Blog ID"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 423 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /blogs/{id}/lock [post]
+func AcquireBlogLockHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid blog ID format", nil)
+		return
+	}
+
+	existing, err := GetBlogByID(id)
+	if err != nil {
+		response.JSON(w, http.StatusNotFound, false, "Blog not found", nil)
+		return
+	}
+	if missing := authorizeUpdate(permissionCheck(r), currentUserID(r), existing); missing != "" {
+		user.Forbidden(w, missing)
+		return
+	}
+
+	editor := user.FromContext(r.Context())
+	name := editor.Name
+	if name == "" {
+		name = editor.Email
+	}
+	lock, err := AcquireLock(id, editor.ID, name)
+	if err != nil {
+		writeLockError(w, err, "Failed to acquire blog lock")
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "Blog lock acquired successfully", lock)
+}
+
+// RenewBlogLockHandler handles extending a held edit lock
+// @Summary Renew blog edit lock
+// @Description Heartbeat that extends the edit lock held by the current user
+// @Tags Blog
+// @Security BearerAuth
+// @Param id path string true "Blog ID"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /blogs/{id}/lock [put]
+func RenewBlogLockHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid blog ID format", nil)
+		return
+	}
+	lock, err := RenewLock(id, user.FromContext(r.Context()).ID)
+	if err != nil {
+		writeLockError(w, err, "Failed to renew blog lock")
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "Blog lock renewed successfully", lock)
+}
+
+// ReleaseBlogLockHandler handles giving up or breaking an edit lock
+// @Summary Release blog edit lock
+// @Description Release the edit lock held by the current user. Admins can break someone else's lock with force=true.
+// @Tags Blog
+// @Security BearerAuth
+// @Param id path string true "Blog ID"
+// @Param force query bool false "Break the lock regardless of its holder"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /blogs/{id}/lock [delete]
+func ReleaseBlogLockHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid blog ID format", nil)
+		return
+	}
+
+	if force, _ := strconv.ParseBool(r.URL.Query().Get("force")); force {
+		if !user.HasPermission(r.Context(), user.PermBlogsBreakLock) {
+			user.Forbidden(w, user.PermBlogsBreakLock)
+			return
+		}
+		if err := BreakLock(id, audit.ActorFrom(r)); err != nil {
+			response.JSON(w, http.StatusInternalServerError, false, "Failed to break blog lock", nil)
+			return
+		}
+		response.JSON(w, http.StatusOK, true, "Blog lock broken successfully", nil)
+		return
+	}
+
+	if err := ReleaseLock(id, user.FromContext(r.Context()).ID); err != nil {
+		writeLockError(w, err, "Failed to release blog lock")
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "Blog lock released successfully", nil)
+}
+
+// Helper to check that a cover media exists and is an image
+func validateCover(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	cover, err := media.GetMediaByID(*id)
+	if err != nil {
+		return "Cover media not found"
+	}
+	if !strings.HasPrefix(cover.MimeType, "image/") {
+		return "Cover media must be an image"
+	}
+	return ""
+}
+
+// Helper to write a list of blogs in the requested shape
+func writeBlogList(w http.ResponseWriter, shape responseShape, blogs []Blog) {
+	if shape.full() {
+		response.JSON(w, http.StatusOK, true, "Blogs retrieved successfully", blogs)
+		return
+	}
+	shaped, err := shape.applyList(blogs)
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to load blog relations", nil)
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "Blogs retrieved successfully", shaped)
+}
+
+// Helper to map lock errors to responses
+func writeLockError(w http.ResponseWriter, err error, fallback string) {
+	var locked *LockedError
+	switch {
+	case errors.As(err, &locked):
+		response.JSON(w, http.StatusLocked, false, locked.Error(), locked.Lock)
+	case errors.Is(err, ErrLockNotHeld):
+		response.JSON(w, http.StatusConflict, false, "You do not hold the edit lock of this blog", nil)
+	default:
+		response.JSON(w, http.StatusInternalServerError, false, fallback, nil)
+	}
+}
+
+// Helper to map workflow errors to responses
+func writeWorkflowError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, workflow.ErrRoleNotAllowed):
+		response.JSON(w, http.StatusForbidden, false, err.Error(), nil)
+	case errors.Is(err, workflow.ErrUnknownState), errors.Is(err, workflow.ErrCommentRequired):
+		response.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
+	default:
+		response.JSON(w, http.StatusUnprocessableEntity, false, err.Error(), nil)
+	}
+}
+
+// Helper to check permissions of the authenticated principal
+func permissionCheck(r *http.Request) checkFunc {
+	return func(perm user.Permission) bool {
+		return user.HasPermission(r.Context(), perm)
+	}
+}
+
+// Helper to get the workflow state the request may list blogs in: published for readers without
+// blogs:read, every state otherwise
+func visibleStatus(r *http.Request) string {
+	if user.HasPermission(r.Context(), user.PermBlogsRead) {
+		return ""
+	}
+	return workflow.Published
+}
+
+// Helper to get the ID of the authenticated user
+func currentUserID(r *http.Request) string {
+	if u := user.FromContext(r.Context()); u != nil {
+		return u.ID.String()
+	}
+	return ""
+}
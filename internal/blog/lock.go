@@ -0,0 +1,156 @@
+package blog
+
+import (
+	"cms-project/internal/audit"
+	"cms-project/internal/database"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const defaultLockTTL = 2 * time.Minute
+
+// ErrLockNotHeld is returned when renewing or releasing a lock the user does not hold
+var ErrLockNotHeld = errors.New("edit lock is not held by this user")
+
+// LockedError is returned when another user holds the edit lock of a blog
+type LockedError struct {
+	Lock *Lock
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%s is editing this post", e.Lock.UserName)
+}
+
+// lockTTL returns how long a lock lives without a heartbeat, configurable through BLOG_LOCK_TTL
+func lockTTL() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("BLOG_LOCK_TTL")); err == nil && d > 0 {
+		return d
+	}
+	return defaultLockTTL
+}
+
+// AcquireLock takes the edit lock of a blog for a user. Acquiring a lock the user already holds
+// renews it; a lock held by someone else is only taken over once it has expired.
+func AcquireLock(blogID, userID uuid.UUID, userName string) (*Lock, error) {
+	var lock Lock
+	query := `
+		INSERT INTO blog_locks (blog_id, user_id, user_name, acquired_at, expires_at)
+		VALUES ($1, $2, $3, NOW(), NOW() + $4 * INTERVAL '1 millisecond')
+		ON CONFLICT (blog_id) DO UPDATE
+			SET user_id = EXCLUDED.user_id, user_name = EXCLUDED.user_name,
+				acquired_at = CASE WHEN blog_locks.user_id = EXCLUDED.user_id THEN blog_locks.acquired_at ELSE NOW() END,
+				expires_at = EXCLUDED.expires_at
+			WHERE blog_locks.user_id = EXCLUDED.user_id OR blog_locks.expires_at <= NOW()
+		RETURNING *`
+	err := database.DB.Get(&lock, query, blogID, userID, userName, lockTTL().Milliseconds())
+	if errors.Is(err, sql.ErrNoRows) {
+		holder, err := GetActiveLock(blogID)
+		if err != nil {
+			return nil, err
+		}
+		if holder == nil {
+			// The other lock expired between the two statements; try once more
+			return AcquireLock(blogID, userID, userName)
+		}
+		return nil, &LockedError{Lock: holder}
+	}
+	if err != nil {
+		log.Printf("Error acquiring blog lock: %v", err)
+		return nil, err
+	}
+	return &lock, nil
+}
+
+// RenewLock extends a lock held by the user; it is called periodically as a heartbeat
+func RenewLock(blogID, userID uuid.UUID) (*Lock, error) {
+	var lock Lock
+	query := `
+		UPDATE blog_locks SET expires_at = NOW() + $3 * INTERVAL '1 millisecond'
+		WHERE blog_id = $1 AND user_id = $2 AND expires_at > NOW()
+		RETURNING *`
+	err := database.DB.Get(&lock, query, blogID, userID, lockTTL().Milliseconds())
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrLockNotHeld
+	}
+	if err != nil {
+		log.Printf("Error renewing blog lock: %v", err)
+		return nil, err
+	}
+	return &lock, nil
+}
+
+// ReleaseLock gives up a lock held by the user
+func ReleaseLock(blogID, userID uuid.UUID) error {
+	result, err := database.DB.Exec("DELETE FROM blog_locks WHERE blog_id = $1 AND user_id = $2", blogID, userID)
+	if err != nil {
+		log.Printf("Error releasing blog lock: %v", err)
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// BreakLock removes the lock of a blog regardless of who holds it. Breaking an active lock is
+// recorded in the audit log in the same transaction.
+func BreakLock(blogID uuid.UUID, actor audit.Actor) error {
+	tx, err := database.DB.Beginx()
+	if err != nil {
+		log.Printf("Error starting lock transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	var lock Lock
+	err = tx.Get(&lock, "DELETE FROM blog_locks WHERE blog_id = $1 RETURNING *", blogID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		log.Printf("Error breaking blog lock: %v", err)
+		return err
+	}
+	if lock.ExpiresAt.After(time.Now()) {
+		if err := audit.Write(tx, actor, audit.ActionUpdate, "blog_lock", blogID.String(), lock, nil); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing lock break: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetActiveLock returns the unexpired lock of a blog, or nil when nobody is editing it
+func GetActiveLock(blogID uuid.UUID) (*Lock, error) {
+	var lock Lock
+	err := database.DB.Get(&lock, "SELECT * FROM blog_locks WHERE blog_id = $1 AND expires_at > NOW()", blogID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("Error fetching blog lock: %v", err)
+		return nil, err
+	}
+	return &lock, nil
+}
+
+// checkLock returns a LockedError when someone other than userID holds an active lock
+func checkLock(blogID uuid.UUID, userID string) error {
+	lock, err := GetActiveLock(blogID)
+	if err != nil {
+		return err
+	}
+	if lock != nil && lock.UserID.String() != userID {
+		return &LockedError{Lock: lock}
+	}
+	return nil
+}
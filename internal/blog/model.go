@@ -1,6 +1,9 @@
 package blog
 
 import (
+	"cms-project/internal/block"
+	"cms-project/internal/media"
+	"cms-project/internal/workflow"
 	"time"
 
 	"github.com/google/uuid"
@@ -8,19 +11,124 @@ import (
 
 // CreateBlogRequest represents the required fields for creating a blog
 type CreateBlogRequest struct {
-	Title      string `json:"title" example:"My First Blog"`
-	Content    string `json:"content" example:"This is the content of the blog."`
-	Status     string `json:"status" example:"draft"` // draft, published
-	CoverImage string `json:"cover_image,omitempty" example:"https://example.com/image.jpg"`
-	AuthorID   string `json:"author_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Title         string `json:"title" example:"My First Blog"`
+	Slug          string `db:"slug" json:"slug,omitempty" example:"my-first-blog"` // generated from the title when empty
+	Content       string `json:"content" example:"This is the content of the blog."`
+	ContentFormat string `db:"content_format" json:"content_format" example:"markdown"` // markdown, html, plain or blocks
+	Status        string `json:"status" example:"draft"`                                // workflow state, changed through POST /blogs/{id}/transitions
+	CoverImage    string `db:"cover_image" json:"cover_image,omitempty" example:"https://example.com/image.jpg"`
+	AuthorID      string `db:"author_id" json:"author_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000" swaggerignore:"true"` // set from the authenticated user
+
+	PrimaryCategoryID *uuid.UUID   `db:"primary_category_id" json:"primary_category_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	CoverMediaID      *uuid.UUID   `db:"cover_media_id" json:"cover_media_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"` // replaces cover_image with the media URL
+	Blocks            block.Blocks `db:"blocks" json:"blocks,omitempty"`                                                                // typed content blocks, replace content when given
+	CustomExcerpt     string       `db:"custom_excerpt" json:"custom_excerpt,omitempty" example:"A short introduction."`                // generated from the content when empty
+	Tags              []string     `db:"-" json:"tags,omitempty" example:"go,postgres"`                                                 // created as needed; kept on update when omitted
+
+	SEO
+}
+
+// SEO holds the search and social metadata of a blog. Empty fields fall back to the title,
+// excerpt, public URL and cover image.
+type SEO struct {
+	MetaTitle       string `db:"meta_title" json:"meta_title,omitempty" example:"My First Blog | Example"`
+	MetaDescription string `db:"meta_description" json:"meta_description,omitempty" example:"What I learned writing my first blog."`
+	CanonicalURL    string `db:"canonical_url" json:"canonical_url,omitempty" example:"https://example.com/blog/my-first-blog"`
+	NoIndex         bool   `db:"noindex" json:"noindex"` // keep search engines from indexing the blog
+	SocialImage     string `db:"social_image" json:"social_image,omitempty" example:"https://example.com/social.jpg"`
 }
 
 // Blog represents a blog post
 type Blog struct {
 	ID uuid.UUID `db:"id" json:"id"`
 	CreateBlogRequest
-	CreatedAt time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+	ContentHTML string    `db:"content_html" json:"content_html"` // sanitized rendering of Content or Blocks
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+
+	Excerpt            string `db:"excerpt" json:"excerpt" example:"This is the content of the blog."`
+	WordCount          int    `db:"word_count" json:"word_count" example:"7"`
+	ReadingTimeMinutes int    `db:"reading_time_minutes" json:"reading_time_minutes" example:"1"`
+	CommentsEnabled    bool   `db:"comments_enabled" json:"comments_enabled"` // changed through PUT /blogs/{id}/comments/settings
+
+	Cover *media.Media `db:"-" json:"cover,omitempty"` // the media referenced by cover_media_id
+}
+
+// BlogSummary lists the fields returned by ?view=summary, with the excerpt instead of the content
+type BlogSummary struct {
+	ID                 uuid.UUID  `json:"id"`
+	Title              string     `json:"title"`
+	Slug               string     `json:"slug"`
+	Excerpt            string     `json:"excerpt"`
+	Status             string     `json:"status"`
+	CoverImage         string     `json:"cover_image,omitempty"`
+	AuthorID           string     `json:"author_id,omitempty"`
+	PrimaryCategoryID  *uuid.UUID `json:"primary_category_id,omitempty"`
+	WordCount          int        `json:"word_count"`
+	ReadingTimeMinutes int        `json:"reading_time_minutes"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+// PublishedBlog is a published blog together with the time it was first published
+type PublishedBlog struct {
+	Blog
+	PublishedAt time.Time `db:"published_at" json:"published_at"`
+}
+
+// PublishedFilter narrows published blogs down to a category, author, tag or search keyword
+type PublishedFilter struct {
+	CategoryID *uuid.UUID
+	AuthorID   *uuid.UUID
+	TagSlug    string
+	Query      string // matched against title and content like SearchBlogs
+}
+
+// Meta is the ready to render head metadata of a blog
+type Meta struct {
+	Title     string      `json:"title" example:"My First Blog | Example"`
+	Canonical string      `json:"canonical" example:"https://example.com/blog/my-first-blog"`
+	Robots    string      `json:"robots" example:"index, follow"`
+	Tags      []MetaTag   `json:"tags"`
+	JSONLD    BlogPosting `json:"json_ld"`
+	HTML      string      `json:"html"` // title, canonical link, meta tags and JSON-LD script as HTML
+}
+
+// MetaTag is a single <meta> tag, identified by either name or property
+type MetaTag struct {
+	Name     string `json:"name,omitempty" example:"description"`
+	Property string `json:"property,omitempty" example:"og:title"`
+	Content  string `json:"content" example:"My First Blog"`
+}
+
+// BlogPosting is the schema.org structured data of a blog
+type BlogPosting struct {
+	Context          string       `json:"@context"`
+	Type             string       `json:"@type"`
+	Headline         string       `json:"headline"`
+	Description      string       `json:"description,omitempty"`
+	URL              string       `json:"url"`
+	MainEntityOfPage string       `json:"mainEntityOfPage"`
+	Image            []string     `json:"image,omitempty"`
+	DatePublished    *time.Time   `json:"datePublished,omitempty"`
+	DateModified     time.Time    `json:"dateModified"`
+	Author           *SchemaThing `json:"author,omitempty"`
+	Publisher        SchemaThing  `json:"publisher"`
+	Keywords         string       `json:"keywords,omitempty"`
+	WordCount        int          `json:"wordCount,omitempty"`
+	ArticleSection   string       `json:"articleSection,omitempty"`
+}
+
+// SchemaThing is a named schema.org entity such as a Person or Organization
+type SchemaThing struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+// Author is the public view of the user who wrote a blog
+type Author struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name" example:"Ayşe Yılmaz"`
 }
 
 // BlogCategory represents the relationship between blogs and categories
@@ -28,3 +136,48 @@ type BlogCategory struct {
 	BlogID     uuid.UUID `db:"blog_id"`
 	CategoryID uuid.UUID `db:"category_id"`
 }
+
+// Breadcrumb is one step of the navigation trail leading to a blog
+type Breadcrumb struct {
+	Name string `json:"name" example:"Technology"`
+	Href string `json:"href" example:"/category/technology"`
+}
+
+// BlogDetail represents a single blog together with its breadcrumb trail
+type BlogDetail struct {
+	Blog
+	Breadcrumb []Breadcrumb `json:"breadcrumb"`
+}
+
+// TransitionRequest asks to move a blog to another workflow state
+type TransitionRequest struct {
+	To      string `json:"to" example:"in_review"`
+	Comment string `json:"comment,omitempty" example:"Ready for review"`
+}
+
+// Transition records a move of a blog between workflow states
+type Transition struct {
+	ID        int64      `db:"id" json:"id"`
+	BlogID    uuid.UUID  `db:"blog_id" json:"blog_id"`
+	FromState string     `db:"from_state" json:"from_state" example:"in_review"`
+	ToState   string     `db:"to_state" json:"to_state" example:"approved"`
+	ActorID   *uuid.UUID `db:"actor_id" json:"actor_id,omitempty"`
+	Comment   string     `db:"comment" json:"comment"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+}
+
+// BlogTransitions is the workflow history of a blog together with the moves the current user may
+// make next
+type BlogTransitions struct {
+	History   []Transition          `json:"history"`
+	Available []workflow.Transition `json:"available"`
+}
+
+// Lock is an advisory edit lock held by a user while editing a blog
+type Lock struct {
+	BlogID     uuid.UUID `db:"blog_id" json:"blog_id"`
+	UserID     uuid.UUID `db:"user_id" json:"user_id"`
+	UserName   string    `db:"user_name" json:"user_name" example:"Ayşe"`
+	AcquiredAt time.Time `db:"acquired_at" json:"acquired_at"`
+	ExpiresAt  time.Time `db:"expires_at" json:"expires_at"`
+}
@@ -0,0 +1,59 @@
+package blog
+
+import (
+	"cms-project/internal/user"
+	"cms-project/internal/workflow"
+)
+
+// Blog statuses
+const (
+	StatusDraft     = workflow.Draft
+	StatusPublished = workflow.Published
+)
+
+// checkFunc reports whether the current principal holds a permission
+type checkFunc func(user.Permission) bool
+
+// authorizeCreate returns the permission missing to create a blog, or "" when allowed
+func authorizeCreate(can checkFunc, status string) user.Permission {
+	if !can(user.PermBlogsCreate) {
+		return user.PermBlogsCreate
+	}
+	if status == StatusPublished && !can(user.PermBlogsPublish) {
+		return user.PermBlogsPublish
+	}
+	return ""
+}
+
+// authorizeUpdate returns the permission missing to edit existing, or "" when allowed.
+// Authors may only change their own posts while the workflow still considers them editable.
+func authorizeUpdate(can checkFunc, userID string, existing *Blog) user.Permission {
+	if !can(user.PermBlogsEdit) && !ownEditable(can, user.PermBlogsEditOwn, userID, existing) {
+		return user.PermBlogsEdit
+	}
+	return ""
+}
+
+// authorizeTransition returns the permission missing to move existing to another state, or "" when
+// allowed. Whether the move itself is legal for the user's role is decided by the workflow.
+func authorizeTransition(can checkFunc, userID string, existing *Blog, to string) user.Permission {
+	if !can(user.PermBlogsEdit) && !(can(user.PermBlogsEditOwn) && existing.AuthorID == userID) {
+		return user.PermBlogsEdit
+	}
+	if to == StatusPublished && !can(user.PermBlogsPublish) {
+		return user.PermBlogsPublish
+	}
+	return ""
+}
+
+// authorizeDelete returns the permission missing to delete existing, or "" when allowed
+func authorizeDelete(can checkFunc, userID string, existing *Blog) user.Permission {
+	if !can(user.PermBlogsDelete) && !(can(user.PermBlogsDeleteOwn) && existing.AuthorID == userID && existing.Status == StatusDraft) {
+		return user.PermBlogsDelete
+	}
+	return ""
+}
+
+func ownEditable(can checkFunc, perm user.Permission, userID string, existing *Blog) bool {
+	return can(perm) && existing.AuthorID == userID && workflow.Editable(existing.Status)
+}
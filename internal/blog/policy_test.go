@@ -0,0 +1,116 @@
+package blog
+
+import (
+	"cms-project/internal/user"
+	"cms-project/internal/workflow"
+	"testing"
+)
+
+const (
+	ownerID = "11111111-1111-1111-1111-111111111111"
+	otherID = "22222222-2222-2222-2222-222222222222"
+)
+
+// roleCheck answers permission checks the way a request by a user of role would
+func roleCheck(role string) checkFunc {
+	return func(perm user.Permission) bool {
+		return user.RoleHas(role, perm)
+	}
+}
+
+func blogBy(authorID, status string) *Blog {
+	return &Blog{CreateBlogRequest: CreateBlogRequest{AuthorID: authorID, Status: status}}
+}
+
+func TestAuthorizeCreate(t *testing.T) {
+	tests := []struct {
+		role   string
+		status string
+		want   user.Permission
+	}{
+		{user.RoleAdmin, StatusPublished, ""},
+		{user.RoleEditor, StatusPublished, ""},
+		{user.RoleEditor, StatusDraft, ""},
+		{user.RoleAuthor, StatusDraft, ""},
+		{user.RoleAuthor, StatusPublished, user.PermBlogsPublish},
+		{user.RoleViewer, StatusDraft, user.PermBlogsCreate},
+		{user.RoleViewer, StatusPublished, user.PermBlogsCreate},
+	}
+	for _, tt := range tests {
+		if got := authorizeCreate(roleCheck(tt.role), tt.status); got != tt.want {
+			t.Errorf("%s creating a %s blog: missing %q, want %q", tt.role, tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestAuthorizeUpdate(t *testing.T) {
+	tests := []struct {
+		name     string
+		role     string
+		authorID string
+		status   string
+		want     user.Permission
+	}{
+		{"author edits own draft", user.RoleAuthor, ownerID, workflow.Draft, ""},
+		{"author edits own post with changes requested", user.RoleAuthor, ownerID, workflow.ChangesRequested, ""},
+		{"author edits own post in review", user.RoleAuthor, ownerID, workflow.InReview, user.PermBlogsEdit},
+		{"author edits own published post", user.RoleAuthor, ownerID, workflow.Published, user.PermBlogsEdit},
+		{"author edits someone else's draft", user.RoleAuthor, otherID, workflow.Draft, user.PermBlogsEdit},
+		{"author edits someone else's published post", user.RoleAuthor, otherID, workflow.Published, user.PermBlogsEdit},
+		{"editor edits someone else's draft", user.RoleEditor, otherID, workflow.Draft, ""},
+		{"editor edits someone else's published post", user.RoleEditor, otherID, workflow.Published, ""},
+		{"admin edits someone else's archived post", user.RoleAdmin, otherID, workflow.Archived, ""},
+		{"viewer edits own draft", user.RoleViewer, ownerID, workflow.Draft, user.PermBlogsEdit},
+	}
+	for _, tt := range tests {
+		if got := authorizeUpdate(roleCheck(tt.role), ownerID, blogBy(tt.authorID, tt.status)); got != tt.want {
+			t.Errorf("%s: missing %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAuthorizeTransition(t *testing.T) {
+	tests := []struct {
+		name     string
+		role     string
+		authorID string
+		from, to string
+		want     user.Permission
+	}{
+		{"author submits own draft", user.RoleAuthor, ownerID, workflow.Draft, workflow.InReview, ""},
+		{"author publishes own draft", user.RoleAuthor, ownerID, workflow.Draft, workflow.Published, user.PermBlogsPublish},
+		{"author submits someone else's draft", user.RoleAuthor, otherID, workflow.Draft, workflow.InReview, user.PermBlogsEdit},
+		{"editor publishes someone else's draft", user.RoleEditor, otherID, workflow.Draft, workflow.Published, ""},
+		{"editor publishes someone else's approved post", user.RoleEditor, otherID, workflow.Approved, workflow.Published, ""},
+		{"editor archives someone else's published post", user.RoleEditor, otherID, workflow.Published, workflow.Archived, ""},
+		{"admin publishes someone else's draft", user.RoleAdmin, otherID, workflow.Draft, workflow.Published, ""},
+		{"viewer submits own draft", user.RoleViewer, ownerID, workflow.Draft, workflow.InReview, user.PermBlogsEdit},
+	}
+	for _, tt := range tests {
+		if got := authorizeTransition(roleCheck(tt.role), ownerID, blogBy(tt.authorID, tt.from), tt.to); got != tt.want {
+			t.Errorf("%s: missing %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAuthorizeDelete(t *testing.T) {
+	tests := []struct {
+		name     string
+		role     string
+		authorID string
+		status   string
+		want     user.Permission
+	}{
+		{"author deletes own draft", user.RoleAuthor, ownerID, workflow.Draft, ""},
+		{"author deletes own published post", user.RoleAuthor, ownerID, workflow.Published, user.PermBlogsDelete},
+		{"author deletes someone else's draft", user.RoleAuthor, otherID, workflow.Draft, user.PermBlogsDelete},
+		{"editor deletes someone else's published post", user.RoleEditor, otherID, workflow.Published, ""},
+		{"admin deletes someone else's published post", user.RoleAdmin, otherID, workflow.Published, ""},
+		{"viewer deletes own draft", user.RoleViewer, ownerID, workflow.Draft, user.PermBlogsDelete},
+	}
+	for _, tt := range tests {
+		if got := authorizeDelete(roleCheck(tt.role), ownerID, blogBy(tt.authorID, tt.status)); got != tt.want {
+			t.Errorf("%s: missing %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package blog
+
+import (
+	"cms-project/internal/database"
+	"cms-project/internal/tag"
+	"cms-project/internal/workflow"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PublishedAt is the SQL expression for when the blog b was first published, falling back to its
+// creation for blogs published before transitions were recorded
+const PublishedAt = `COALESCE((SELECT MIN(t.created_at) FROM blog_transitions t WHERE t.blog_id = b.id AND t.to_state = '` + workflow.Published + `'), b.created_at)`
+
+// GetPublishedBlogs retrieves a page of published blogs, most recently published first. A limit of
+// 0 returns all of them.
+func GetPublishedBlogs(filter PublishedFilter, page, limit int) ([]PublishedBlog, error) {
+	var blogs []PublishedBlog
+	where, args := filter.where()
+	query := "SELECT b.*, " + PublishedAt + " AS published_at FROM blogs b WHERE " + where + " ORDER BY published_at DESC, b.id"
+	if limit > 0 {
+		args = append(args, limit, (page-1)*limit)
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+	}
+	if err := database.DB.Select(&blogs, query, args...); err != nil {
+		log.Printf("Error fetching published blogs: %v", err)
+		return nil, err
+	}
+	return blogs, resolvePublishedCovers(blogs)
+}
+
+// GetPublishedBlogBySlug retrieves a published blog by slug, together with its tag names
+func GetPublishedBlogBySlug(blogSlug string) (*PublishedBlog, error) {
+	var blog PublishedBlog
+	query := "SELECT b.*, " + PublishedAt + " AS published_at FROM blogs b WHERE b.slug = $1 AND b.status = $2"
+	if err := database.DB.Get(&blog, query, blogSlug, workflow.Published); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("Error fetching published blog by slug: %v", err)
+		}
+		return nil, err
+	}
+	tags, err := tag.GetTagsForBlogs([]uuid.UUID{blog.ID})
+	if err != nil {
+		return nil, err
+	}
+	blog.Tags = tagNames(tags[blog.ID])
+	blogs := []PublishedBlog{blog}
+	if err := resolvePublishedCovers(blogs); err != nil {
+		return nil, err
+	}
+	return &blogs[0], nil
+}
+
+// GetPublishedState returns the number of published blogs matching a filter and when the most
+// recent of them changed, which is enough to tell whether a list of them is still current. A blog
+// also counts as changed when one of its tags was renamed or its author updated; categories cannot
+// be renamed.
+func GetPublishedState(filter PublishedFilter) (int, time.Time, error) {
+	var state struct {
+		Count   int        `db:"count"`
+		Updated *time.Time `db:"updated"`
+	}
+	where, args := filter.where()
+	query := `
+		SELECT COUNT(*) AS count, MAX(GREATEST(b.updated_at,
+			(SELECT MAX(t.updated_at) FROM blog_tags bt JOIN tags t ON t.id = bt.tag_id WHERE bt.blog_id = b.id),
+			(SELECT u.updated_at FROM users u WHERE u.id::text = b.author_id::text))) AS updated
+		FROM blogs b WHERE ` + where
+	if err := database.DB.Get(&state, query, args...); err != nil {
+		log.Printf("Error fetching published blog state: %v", err)
+		return 0, time.Time{}, err
+	}
+	if state.Updated == nil {
+		return state.Count, time.Time{}, nil
+	}
+	return state.Count, *state.Updated, nil
+}
+
+// where builds the condition selecting the published blogs matching a filter
+func (f PublishedFilter) where() (string, []interface{}) {
+	args := []interface{}{workflow.Published}
+	conditions := []string{"b.status = $1"}
+	if f.CategoryID != nil {
+		args = append(args, *f.CategoryID)
+		conditions = append(conditions, fmt.Sprintf("EXISTS (SELECT 1 FROM blog_categories bc WHERE bc.blog_id = b.id AND bc.category_id = $%d)", len(args)))
+	}
+	if f.AuthorID != nil {
+		args = append(args, f.AuthorID.String())
+		conditions = append(conditions, fmt.Sprintf("b.author_id = $%d", len(args)))
+	}
+	if f.TagSlug != "" {
+		args = append(args, f.TagSlug)
+		conditions = append(conditions, fmt.Sprintf("EXISTS (SELECT 1 FROM blog_tags bt JOIN tags t ON t.id = bt.tag_id WHERE bt.blog_id = b.id AND t.slug = $%d)", len(args)))
+	}
+	if f.Query != "" {
+		args = append(args, "%"+f.Query+"%")
+		conditions = append(conditions, fmt.Sprintf("(b.title ILIKE $%d OR b.content ILIKE $%d)", len(args), len(args)))
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// resolvePublishedCovers sets the cover images of published blogs from their cover media
+func resolvePublishedCovers(blogs []PublishedBlog) error {
+	plain := make([]Blog, len(blogs))
+	for i := range blogs {
+		plain[i] = blogs[i].Blog
+	}
+	if err := resolveCovers(plain); err != nil {
+		return err
+	}
+	for i := range blogs {
+		blogs[i].Blog = plain[i]
+	}
+	return nil
+}
@@ -1,18 +1,27 @@
 package blog
 
 import (
+	"cms-project/internal/user"
+
 	"github.com/gorilla/mux"
 )
 
 // RegisterBlogRoutes registers all blog routes
 func RegisterBlogRoutes(r *mux.Router) {
-	r.HandleFunc("", GetBlogsHandler).Methods("GET")
-	r.HandleFunc("", CreateBlogHandler).Methods("POST")
-	r.HandleFunc("/{id:[a-fA-F0-9-]+}", GetBlogByIDHandler).Methods("GET")
-	r.HandleFunc("/{id:[a-fA-F0-9-]+}", UpdateBlogHandler).Methods("PUT")
-	r.HandleFunc("/{id:[a-fA-F0-9-]+}", DeleteBlogHandler).Methods("DELETE")
-	r.HandleFunc("/search", SearchBlogsHandler).Methods("GET")
-	r.HandleFunc("/{id:[a-fA-F0-9-]+}/categories", AddCategoryToBlogHandler).Methods("POST")
-	r.HandleFunc("/{id:[a-fA-F0-9-]+}/categories/{category_id:[a-fA-F0-9-]+}", RemoveCategoryFromBlogHandler).Methods("DELETE") // Remove category from blog
+	r.Handle("", user.OptionalAuth(GetBlogsHandler)).Methods("GET") // drafts need blogs:read
+	r.Handle("", user.Require(user.PermBlogsCreate, CreateBlogHandler)).Methods("POST")
+	r.Handle("/{id:[a-fA-F0-9-]+}", user.OptionalAuth(GetBlogByIDHandler)).Methods("GET")
+	r.Handle("/{id:[a-fA-F0-9-]+}", user.RequireAuthFunc(UpdateBlogHandler)).Methods("PUT")    // ownership checked in handler
+	r.Handle("/{id:[a-fA-F0-9-]+}", user.RequireAuthFunc(DeleteBlogHandler)).Methods("DELETE") // ownership checked in handler
+	r.Handle("/search", user.OptionalAuth(SearchBlogsHandler)).Methods("GET")
+	r.Handle("/{id:[a-fA-F0-9-]+}/transitions", user.Require(user.PermBlogsRead, GetBlogTransitionsHandler)).Methods("GET")
+	r.Handle("/{id:[a-fA-F0-9-]+}/meta", user.Require(user.PermBlogsRead, GetBlogMetaHandler)).Methods("GET") // Meta tags and JSON-LD
+	r.Handle("/{id:[a-fA-F0-9-]+}/lock", user.RequireAuthFunc(GetBlogLockHandler)).Methods("GET")
+	r.Handle("/{id:[a-fA-F0-9-]+}/lock", user.RequireAuthFunc(AcquireBlogLockHandler)).Methods("POST")
+	r.Handle("/{id:[a-fA-F0-9-]+}/lock", user.RequireAuthFunc(RenewBlogLockHandler)).Methods("PUT")
+	r.Handle("/{id:[a-fA-F0-9-]+}/lock", user.RequireAuthFunc(ReleaseBlogLockHandler)).Methods("DELETE")
+	r.Handle("/{id:[a-fA-F0-9-]+}/transitions", user.RequireAuthFunc(TransitionBlogHandler)).Methods("POST") // workflow rules checked in handler
+	r.Handle("/{id:[a-fA-F0-9-]+}/categories", user.RequireAuthFunc(AddCategoryToBlogHandler)).Methods("POST")
+	r.Handle("/{id:[a-fA-F0-9-]+}/categories/{category_id:[a-fA-F0-9-]+}", user.RequireAuthFunc(RemoveCategoryFromBlogHandler)).Methods("DELETE") // Remove category from blog
 
 }
@@ -0,0 +1,204 @@
+package blog
+
+import (
+	"cms-project/internal/category"
+	"cms-project/internal/database"
+	"cms-project/internal/site"
+	"cms-project/internal/workflow"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	maxMetaTitleLength       = 70
+	maxMetaDescriptionLength = 160
+	maxSEOURLLength          = 2048
+)
+
+var (
+	ErrMetaTitleTooLong       = fmt.Errorf("meta_title can have at most %d characters", maxMetaTitleLength)
+	ErrMetaDescriptionTooLong = fmt.Errorf("meta_description can have at most %d characters", maxMetaDescriptionLength)
+	ErrInvalidCanonicalURL    = errors.New("canonical_url must be an absolute http or https URL")
+	ErrInvalidSocialImage     = errors.New("social_image must be an http or https URL or a path on this site")
+)
+
+// validateSEO trims the SEO fields of a blog and checks their lengths and URLs
+func validateSEO(seo *SEO) error {
+	seo.MetaTitle = strings.TrimSpace(seo.MetaTitle)
+	seo.MetaDescription = strings.TrimSpace(seo.MetaDescription)
+	seo.CanonicalURL = strings.TrimSpace(seo.CanonicalURL)
+	seo.SocialImage = strings.TrimSpace(seo.SocialImage)
+
+	if utf8.RuneCountInString(seo.MetaTitle) > maxMetaTitleLength {
+		return ErrMetaTitleTooLong
+	}
+	if utf8.RuneCountInString(seo.MetaDescription) > maxMetaDescriptionLength {
+		return ErrMetaDescriptionTooLong
+	}
+	if seo.CanonicalURL != "" && !absoluteURL(seo.CanonicalURL) {
+		return ErrInvalidCanonicalURL
+	}
+	if seo.SocialImage != "" && !absoluteURL(seo.SocialImage) &&
+		(!strings.HasPrefix(seo.SocialImage, "/") || strings.HasPrefix(seo.SocialImage, "//") || len(seo.SocialImage) > maxSEOURLLength) {
+		return ErrInvalidSocialImage
+	}
+	return nil
+}
+
+// GetBlogMeta builds the title, meta tags and schema.org BlogPosting of a blog, filling empty SEO
+// fields from the title, excerpt, public URL and cover image
+func GetBlogMeta(blog *Blog) (*Meta, error) {
+	authors, err := GetAuthorsForBlogs([]Blog{*blog})
+	if err != nil {
+		return nil, err
+	}
+	author := authors[blog.AuthorID].Name
+	var section string
+	if blog.PrimaryCategoryID != nil {
+		if c, err := category.GetCategoryByID(*blog.PrimaryCategoryID); err == nil {
+			section = c.Name
+		}
+	}
+	published, err := getPublishedAt(blog)
+	if err != nil {
+		return nil, err
+	}
+
+	title := firstNonEmpty(blog.MetaTitle, blog.Title)
+	description := firstNonEmpty(blog.MetaDescription, truncate(blog.Excerpt, maxMetaDescriptionLength))
+	canonical := firstNonEmpty(blog.CanonicalURL, site.URL(site.BlogPath(blog.Slug)))
+	image := firstNonEmpty(blog.SocialImage, blog.CoverImage)
+	if image != "" {
+		image = site.URL(image)
+	}
+	robots := "index, follow"
+	if blog.NoIndex {
+		robots = "noindex, follow"
+	}
+
+	meta := &Meta{Title: title, Canonical: canonical, Robots: robots}
+	add := func(name, property, content string) {
+		if content != "" {
+			meta.Tags = append(meta.Tags, MetaTag{Name: name, Property: property, Content: content})
+		}
+	}
+	add("description", "", description)
+	add("robots", "", robots)
+	add("", "og:type", "article")
+	add("", "og:site_name", site.Name())
+	add("", "og:title", title)
+	add("", "og:description", description)
+	add("", "og:url", canonical)
+	add("", "og:image", image)
+	if blog.Cover != nil && image == site.URL(blog.Cover.URL) {
+		// The cover knows its size and alt text
+		if blog.Cover.Width > 0 {
+			add("", "og:image:width", strconv.Itoa(blog.Cover.Width))
+			add("", "og:image:height", strconv.Itoa(blog.Cover.Height))
+		}
+		add("", "og:image:alt", blog.Cover.Alt)
+	}
+	if published != nil {
+		add("", "article:published_time", published.UTC().Format(time.RFC3339))
+	}
+	add("", "article:modified_time", blog.UpdatedAt.UTC().Format(time.RFC3339))
+	add("", "article:section", section)
+	for _, t := range blog.Tags {
+		add("", "article:tag", t)
+	}
+	card := "summary"
+	if image != "" {
+		card = "summary_large_image"
+	}
+	add("twitter:card", "", card)
+	add("twitter:title", "", title)
+	add("twitter:description", "", description)
+	add("twitter:image", "", image)
+
+	meta.JSONLD = BlogPosting{
+		Context:          "https://schema.org",
+		Type:             "BlogPosting",
+		Headline:         blog.Title,
+		Description:      description,
+		URL:              canonical,
+		MainEntityOfPage: canonical,
+		DatePublished:    published,
+		DateModified:     blog.UpdatedAt,
+		Publisher:        SchemaThing{Type: "Organization", Name: site.Name()},
+		Keywords:         strings.Join(blog.Tags, ", "),
+		WordCount:        blog.WordCount,
+		ArticleSection:   section,
+	}
+	if image != "" {
+		meta.JSONLD.Image = []string{image}
+	}
+	if author != "" {
+		meta.JSONLD.Author = &SchemaThing{Type: "Person", Name: author}
+	}
+
+	if meta.HTML, err = renderMeta(meta); err != nil {
+		log.Printf("Error rendering blog meta: %v", err)
+		return nil, err
+	}
+	return meta, nil
+}
+
+// renderMeta writes the metadata as HTML for the head of a page
+func renderMeta(meta *Meta) (string, error) {
+	var b strings.Builder
+	b.WriteString("<title>" + html.EscapeString(meta.Title) + "</title>\n")
+	b.WriteString(`<link rel="canonical" href="` + html.EscapeString(meta.Canonical) + `">` + "\n")
+	for _, tag := range meta.Tags {
+		if tag.Name != "" {
+			b.WriteString(`<meta name="` + html.EscapeString(tag.Name) + `"`)
+		} else {
+			b.WriteString(`<meta property="` + html.EscapeString(tag.Property) + `"`)
+		}
+		b.WriteString(` content="` + html.EscapeString(tag.Content) + `">` + "\n")
+	}
+	// json.Marshal escapes <, > and &, so the data cannot close the script element
+	data, err := json.Marshal(meta.JSONLD)
+	if err != nil {
+		return "", err
+	}
+	b.WriteString(`<script type="application/ld+json">` + string(data) + "</script>\n")
+	return b.String(), nil
+}
+
+// getPublishedAt returns when a blog was first published, or nil when it is not published
+func getPublishedAt(blog *Blog) (*time.Time, error) {
+	if blog.Status != workflow.Published {
+		return nil, nil
+	}
+	var published time.Time
+	query := "SELECT " + PublishedAt + " FROM blogs b WHERE b.id = $1"
+	if err := database.DB.Get(&published, query, blog.ID); err != nil {
+		log.Printf("Error fetching blog publication time: %v", err)
+		return nil, err
+	}
+	return &published, nil
+}
+
+// Helper to check for an absolute http or https URL
+func absoluteURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && len(raw) <= maxSEOURLLength
+}
+
+// Helper to pick the first non-empty value
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -1,40 +1,134 @@
 package blog
 
 import (
+	"cms-project/internal/audit"
+	"cms-project/internal/category"
 	"cms-project/internal/database"
+	"cms-project/internal/events"
+	"cms-project/internal/media"
+	"cms-project/internal/outbox"
+	"cms-project/internal/site"
+	"cms-project/internal/tag"
+	"cms-project/internal/user"
+	"cms-project/internal/workflow"
+	"cms-project/pkg/slug"
+	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 
 	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
-// GetBlogs retrieves all blogs from the database
-func GetBlogs(page, limit int) ([]Blog, error) {
+// ErrSlugTaken is returned when another blog already uses the slug
+var ErrSlugTaken = errors.New("slug is already used by another blog")
+
+// GetBlogs retrieves blogs in a workflow state from the database; an empty status matches all of them
+func GetBlogs(status string, page, limit int) ([]Blog, error) {
 	var blogs []Blog
 
 	offset := (page - 1) * limit
-	query := "SELECT * FROM blogs ORDER BY created_at DESC LIMIT $1 OFFSET $2"
-	err := database.DB.Select(&blogs, query, limit, offset)
+	query := "SELECT * FROM blogs WHERE ($1 = '' OR status = $1) ORDER BY created_at DESC LIMIT $2 OFFSET $3"
+	err := database.DB.Select(&blogs, query, status, limit, offset)
 	if err != nil {
 		log.Printf("Error fetching blogs: %v", err)
 		return nil, err
 	}
-	return blogs, nil
+	return blogs, resolveCovers(blogs)
 }
 
-// CreateBlog inserts a new blog into the database
-func CreateBlog(blog Blog) error {
-	query := "INSERT INTO blogs (id, title, content, status, cover_image, author_id, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())"
+// CreateBlog inserts a new blog into the database, filling in its ID and timestamps. New blogs
+// start in the initial workflow state; when blog.Status names another one, the blog is moved there
+// by a transition. The blog, its tags and categories, the transition and the events and audit
+// events are written in one transaction.
+func CreateBlog(blog *Blog, actor audit.Actor) error {
+	query := "INSERT INTO blogs (id, title, slug, content, content_format, content_html, blocks, status, cover_image, author_id, primary_category_id, custom_excerpt, excerpt, word_count, reading_time_minutes, cover_media_id, meta_title, meta_description, canonical_url, noindex, social_image, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, NOW(), NOW()) RETURNING created_at, updated_at, comments_enabled"
 	blog.ID = uuid.New()
-	_, err := database.DB.Exec(query, blog.ID, blog.Title, blog.Content, blog.Status, blog.CoverImage, blog.AuthorID)
+	target := blog.Status
+	blog.Status = workflow.Initial()
+	if blog.Slug == "" {
+		blog.Slug = blog.Title
+	}
+	// Titles without any transliterable letters, such as Cyrillic or CJK ones, fall back to the ID
+	if blog.Slug = slug.Make(blog.Slug); blog.Slug == "" {
+		blog.Slug = blog.ID.String()
+	}
+	if err := renderContent(blog); err != nil {
+		return err
+	}
+
+	tx, err := database.DB.Beginx()
 	if err != nil {
+		log.Printf("Error starting blog transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if blog.Slug, err = uniqueSlug(tx, blog.Slug); err != nil {
+		return err
+	}
+	err = tx.QueryRow(query, blog.ID, blog.Title, blog.Slug, blog.Content, blog.ContentFormat, blog.ContentHTML, blog.Blocks, blog.Status, blog.CoverImage, blog.AuthorID, blog.PrimaryCategoryID, blog.CustomExcerpt, blog.Excerpt, blog.WordCount, blog.ReadingTimeMinutes, blog.CoverMediaID, blog.MetaTitle, blog.MetaDescription, blog.CanonicalURL, blog.NoIndex, blog.SocialImage).Scan(&blog.CreatedAt, &blog.UpdatedAt, &blog.CommentsEnabled)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrSlugTaken
+		}
 		log.Printf("Error creating blog: %v", err)
 		return err
 	}
+	if err := updateTags(tx, blog); err != nil {
+		return err
+	}
+	if err := resolveCover(blog); err != nil {
+		return err
+	}
+	if blog.PrimaryCategoryID != nil {
+		if err := addCategoryToBlog(tx, blog.ID, *blog.PrimaryCategoryID); err != nil {
+			return err
+		}
+	}
+	if err := publishBlogEvent(tx, "blog.created", blog.ID, blog); err != nil {
+		return err
+	}
+	if err := audit.Write(tx, actor, audit.ActionCreate, "blog", blog.ID.String(), nil, blog); err != nil {
+		return err
+	}
+	if target != "" && target != blog.Status {
+		if _, err := transitionBlog(tx, blog.ID, blog.Status, target, actor, ""); err != nil {
+			return err
+		}
+		blog.Status = target
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing blog: %v", err)
+		return err
+	}
 	return nil
 }
 
-// GetBlogByID retrieves a single blog by its ID
+// GetBlogsByTag retrieves the blogs carrying a tag in a workflow state; an empty status matches all
+// of them
+func GetBlogsByTag(tagSlug, status string, page, limit int) ([]Blog, error) {
+	var blogs []Blog
+
+	offset := (page - 1) * limit
+	query := `
+		SELECT b.* FROM blogs b
+		JOIN blog_tags bt ON bt.blog_id = b.id
+		JOIN tags t ON t.id = bt.tag_id
+		WHERE t.slug = $1 AND ($2 = '' OR b.status = $2)
+		ORDER BY b.created_at DESC
+		LIMIT $3 OFFSET $4`
+	err := database.DB.Select(&blogs, query, tagSlug, status, limit, offset)
+	if err != nil {
+		log.Printf("Error fetching blogs by tag: %v", err)
+		return nil, err
+	}
+	return blogs, resolveCovers(blogs)
+}
+
+// GetBlogByID retrieves a single blog by its ID, together with its tag names
 func GetBlogByID(id uuid.UUID) (*Blog, error) {
 	var blog Blog
 	query := "SELECT * FROM blogs WHERE id = $1"
@@ -43,53 +137,260 @@ func GetBlogByID(id uuid.UUID) (*Blog, error) {
 		log.Printf("Error fetching blog by ID: %v", err)
 		return nil, err
 	}
+	tags, err := tag.GetTagsForBlogs([]uuid.UUID{id})
+	if err != nil {
+		return nil, err
+	}
+	blog.Tags = tagNames(tags[id])
+	if err := resolveCover(&blog); err != nil {
+		return nil, err
+	}
 	return &blog, nil
 }
 
-// DeleteBlog removes a blog from the database
-func DeleteBlog(id uuid.UUID) error {
-	query := "DELETE FROM blogs WHERE id = $1"
-	_, err := database.DB.Exec(query, id)
+// DeleteBlog removes a blog from the database, recording blog.deleted and the audit event in the
+// same transaction. The event carries the blog's category IDs since its links are gone once it
+// commits.
+func DeleteBlog(blog *Blog, actor audit.Actor) error {
+	tx, err := database.DB.Beginx()
 	if err != nil {
+		log.Printf("Error starting blog transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	categoryIDs := []uuid.UUID{}
+	if err := tx.Select(&categoryIDs, "SELECT category_id FROM blog_categories WHERE blog_id = $1", blog.ID); err != nil {
+		log.Printf("Error fetching categories of deleted blog: %v", err)
+		return err
+	}
+	query := "DELETE FROM blogs WHERE id = $1"
+	if _, err := tx.Exec(query, blog.ID); err != nil {
 		log.Printf("Error deleting blog: %v", err)
 		return err
 	}
+	data := map[string][]uuid.UUID{"category_ids": categoryIDs}
+	if err := publishBlogEvent(tx, "blog.deleted", blog.ID, data); err != nil {
+		return err
+	}
+	if err := audit.Write(tx, actor, audit.ActionDelete, "blog", blog.ID.String(), blog, nil); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing blog deletion: %v", err)
+		return err
+	}
 	return nil
 }
 
-// UpdateBlog updates an existing blog
-func UpdateBlog(blog Blog) error {
-	query := "UPDATE blogs SET title = $1, content = $2, status = $3, cover_image = $4,  updated_at = NOW() WHERE id = $5"
-	_, err := database.DB.Exec(query, blog.Title, blog.Content, blog.CoverImage, blog.Status, blog.ID)
+// UpdateBlog updates existing with the fields of blog, its tags and categories and records
+// blog.updated and the audit event in one transaction. blog is filled in with the stored blog.
+func UpdateBlog(blog *Blog, existing *Blog, actor audit.Actor) error {
+	if err := renderContent(blog); err != nil {
+		return err
+	}
+
+	tx, err := database.DB.Beginx()
 	if err != nil {
+		log.Printf("Error starting blog transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "UPDATE blogs SET title = $1, slug = COALESCE(NULLIF($2, ''), slug), content = $3, content_format = $4, content_html = $5, blocks = $6, status = $7, cover_image = $8, primary_category_id = $9, custom_excerpt = $10, excerpt = $11, word_count = $12, reading_time_minutes = $13, cover_media_id = $14, meta_title = $15, meta_description = $16, canonical_url = $17, noindex = $18, social_image = $19, updated_at = NOW() WHERE id = $20 RETURNING *"
+	err = tx.Get(blog, query, blog.Title, slug.Make(blog.Slug), blog.Content, blog.ContentFormat, blog.ContentHTML, blog.Blocks, blog.Status, blog.CoverImage, blog.PrimaryCategoryID, blog.CustomExcerpt, blog.Excerpt, blog.WordCount, blog.ReadingTimeMinutes, blog.CoverMediaID, blog.MetaTitle, blog.MetaDescription, blog.CanonicalURL, blog.NoIndex, blog.SocialImage, blog.ID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrSlugTaken
+		}
 		log.Printf("Error updating blog: %v", err)
 		return err
 	}
+	if err := updateTags(tx, blog); err != nil {
+		return err
+	}
+	if blog.Tags == nil {
+		blog.Tags = existing.Tags
+	}
+	if err := resolveCover(blog); err != nil {
+		return err
+	}
+	if blog.PrimaryCategoryID != nil {
+		if err := addCategoryToBlog(tx, blog.ID, *blog.PrimaryCategoryID); err != nil {
+			return err
+		}
+	}
+	if err := publishBlogEvent(tx, "blog.updated", blog.ID, blog); err != nil {
+		return err
+	}
+	if err := audit.Write(tx, actor, audit.ActionUpdate, "blog", blog.ID.String(), existing, blog); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing blog update: %v", err)
+		return err
+	}
 	return nil
 }
 
-// SearchBlogs searches blogs by title or content
-func SearchBlogs(keyword string, page, limit int) ([]Blog, error) {
+// SearchBlogs searches blogs in a workflow state by title or content; an empty status matches all
+// of them
+func SearchBlogs(keyword, status string, page, limit int) ([]Blog, error) {
 	var blogs []Blog
 
 	offset := (page - 1) * limit
 	query := `
 		SELECT * FROM blogs 
-		WHERE title ILIKE $1 OR content ILIKE $1
+		WHERE (title ILIKE $1 OR content ILIKE $1) AND ($2 = '' OR status = $2)
 		ORDER BY created_at DESC 
-		LIMIT $2 OFFSET $3`
-	err := database.DB.Select(&blogs, query, fmt.Sprintf("%%%s%%", keyword), limit, offset)
+		LIMIT $3 OFFSET $4`
+	err := database.DB.Select(&blogs, query, fmt.Sprintf("%%%s%%", keyword), status, limit, offset)
 	if err != nil {
 		log.Printf("Error searching blogs: %v", err)
 		return nil, err
 	}
-	return blogs, nil
+	return blogs, resolveCovers(blogs)
+}
+
+// AddCategoryToBlog adds a category to a blog, recording blog.updated and the audit event in the
+// same transaction
+func AddCategoryToBlog(blogID, categoryID uuid.UUID, actor audit.Actor) error {
+	tx, err := database.DB.Beginx()
+	if err != nil {
+		log.Printf("Error starting blog transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := addCategoryToBlog(tx, blogID, categoryID); err != nil {
+		return err
+	}
+	link := map[string]uuid.UUID{"category_id": categoryID}
+	if err := publishBlogEvent(tx, "blog.updated", blogID, link); err != nil {
+		return err
+	}
+	if err := audit.Write(tx, actor, audit.ActionLink, "blog", blogID.String(), nil, link); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing blog category: %v", err)
+		return err
+	}
+	return nil
+}
+
+// RemoveCategoryFromBlog removes a category from a blog, recording blog.updated and the audit event
+// in the same transaction
+func RemoveCategoryFromBlog(blogID, categoryID uuid.UUID, actor audit.Actor) error {
+	tx, err := database.DB.Beginx()
+	if err != nil {
+		log.Printf("Error starting blog transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "DELETE FROM blog_categories WHERE blog_id = $1 AND category_id = $2"
+	if _, err := tx.Exec(query, blogID, categoryID); err != nil {
+		log.Printf("Error removing category from blog: %v", err)
+		return err
+	}
+	link := map[string]uuid.UUID{"category_id": categoryID}
+	if err := publishBlogEvent(tx, "blog.updated", blogID, link); err != nil {
+		return err
+	}
+	if err := audit.Write(tx, actor, audit.ActionUnlink, "blog", blogID.String(), link, nil); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing blog category removal: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetCategoriesForBlogs retrieves the categories of several blogs in a single query, keyed by blog ID
+func GetCategoriesForBlogs(blogIDs []uuid.UUID) (map[uuid.UUID][]category.Category, error) {
+	result := make(map[uuid.UUID][]category.Category)
+	if len(blogIDs) == 0 {
+		return result, nil
+	}
+	ids := make([]string, 0, len(blogIDs))
+	for _, id := range blogIDs {
+		ids = append(ids, id.String())
+	}
+	var rows []struct {
+		BlogID uuid.UUID `db:"blog_id"`
+		category.Category
+	}
+	query := `
+		SELECT bc.blog_id, c.* FROM blog_categories bc
+		JOIN categories c ON c.id = bc.category_id
+		WHERE bc.blog_id = ANY($1)
+		ORDER BY c.name`
+	if err := database.DB.Select(&rows, query, pq.Array(ids)); err != nil {
+		log.Printf("Error fetching blog categories: %v", err)
+		return nil, err
+	}
+	for _, row := range rows {
+		result[row.BlogID] = append(result[row.BlogID], row.Category)
+	}
+	return result, nil
+}
+
+// GetAuthorsForBlogs retrieves the authors of several blogs in a single query, keyed by user ID
+func GetAuthorsForBlogs(blogs []Blog) (map[string]Author, error) {
+	result := make(map[string]Author)
+	var ids []uuid.UUID
+	for _, b := range blogs {
+		if id, err := uuid.Parse(b.AuthorID); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	users, err := user.GetUsersByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		result[u.ID.String()] = Author{ID: u.ID, Name: u.Name}
+	}
+	return result, nil
+}
+
+// GetBlogBreadcrumb builds the breadcrumb trail of a blog from its primary category
+func GetBlogBreadcrumb(blog *Blog) ([]Breadcrumb, error) {
+	breadcrumb := []Breadcrumb{{Name: "Home", Href: "/"}}
+	if blog.PrimaryCategoryID != nil {
+		categories, err := category.GetCategoryAncestors(*blog.PrimaryCategoryID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		for _, c := range categories {
+			breadcrumb = append(breadcrumb, Breadcrumb{Name: c.Name, Href: site.CategoryPath(c.Slug)})
+		}
+	}
+	return append(breadcrumb, Breadcrumb{Name: blog.Title, Href: site.BlogPath(blog.Slug)}), nil
 }
 
-// AddCategoryToBlog adds a category to a blog
-func AddCategoryToBlog(blogID, categoryID int) error {
+// uniqueSlug returns a slug for a new blog that no other blog uses, numbering it when needed
+func uniqueSlug(tx *sqlx.Tx, base string) (string, error) {
+	var taken []string
+	if err := tx.Select(&taken, "SELECT slug FROM blogs WHERE slug = $1 OR slug LIKE $2", base, base+"-%"); err != nil {
+		log.Printf("Error checking blog slugs: %v", err)
+		return "", err
+	}
+	return slug.Unique(base, taken), nil
+}
+
+// Helper to recognize violations of a unique index, such as the one on slugs
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// addCategoryToBlog adds a category to a blog, within a transaction or not
+func addCategoryToBlog(db sqlx.Execer, blogID, categoryID uuid.UUID) error {
 	query := "INSERT INTO blog_categories (blog_id, category_id) VALUES ($1, $2) ON CONFLICT DO NOTHING"
-	_, err := database.DB.Exec(query, blogID, categoryID)
+	_, err := db.Exec(query, blogID, categoryID)
 	if err != nil {
 		log.Printf("Error adding category to blog: %v", err)
 		return err
@@ -97,13 +398,66 @@ func AddCategoryToBlog(blogID, categoryID int) error {
 	return nil
 }
 
-// RemoveCategoryFromBlog removes a category from a blog
-func RemoveCategoryFromBlog(blogID, categoryID uuid.UUID) error {
-	query := "DELETE FROM blog_categories WHERE blog_id = $1 AND category_id = $2"
-	_, err := database.DB.Exec(query, blogID, categoryID)
+// updateTags replaces the tags of a blog as part of tx when tags were given, normalizing blog.Tags
+func updateTags(tx *sqlx.Tx, blog *Blog) error {
+	if blog.Tags == nil {
+		return nil
+	}
+	tags, err := tag.SetBlogTags(tx, blog.ID, blog.Tags)
 	if err != nil {
-		log.Printf("Error removing category from blog: %v", err)
 		return err
 	}
+	blog.Tags = tagNames(tags)
 	return nil
 }
+
+// tagNames returns the names of tags
+func tagNames(tags []tag.Tag) []string {
+	names := make([]string, 0, len(tags))
+	for _, t := range tags {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+// resolveCover sets the cover image URL of a blog from its cover media
+func resolveCover(blog *Blog) error {
+	blogs := []Blog{*blog}
+	if err := resolveCovers(blogs); err != nil {
+		return err
+	}
+	*blog = blogs[0]
+	return nil
+}
+
+// resolveCovers sets the cover image URLs of blogs from their cover media with a single query
+func resolveCovers(blogs []Blog) error {
+	var ids []uuid.UUID
+	for _, b := range blogs {
+		if b.CoverMediaID != nil {
+			ids = append(ids, *b.CoverMediaID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	covers, err := media.GetMediaByIDs(ids)
+	if err != nil {
+		return err
+	}
+	for i := range blogs {
+		if blogs[i].CoverMediaID == nil {
+			continue
+		}
+		if cover, ok := covers[*blogs[i].CoverMediaID]; ok {
+			blogs[i].Cover = &cover
+			blogs[i].CoverImage = cover.URL
+		}
+	}
+	return nil
+}
+
+// publishBlogEvent writes a blog event to the outbox as part of tx
+func publishBlogEvent(tx *sqlx.Tx, eventType string, id uuid.UUID, data interface{}) error {
+	return outbox.Write(tx, events.Event{Type: eventType, EntityType: "blog", EntityID: id.String(), Data: data})
+}
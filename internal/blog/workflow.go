@@ -0,0 +1,105 @@
+package blog
+
+import (
+	"cms-project/internal/audit"
+	"cms-project/internal/database"
+	"cms-project/internal/events"
+	"cms-project/internal/outbox"
+	"errors"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrStatusChanged is returned when a blog left the expected state before a transition was applied
+var ErrStatusChanged = errors.New("blog status changed concurrently")
+
+// TransitionBlog moves a blog from one workflow state to another and records who did it and why,
+// both in the workflow history and the audit log. The move only applies if the blog is still in
+// the from state.
+func TransitionBlog(id uuid.UUID, from, to string, actor audit.Actor, comment string) (*Transition, error) {
+	tx, err := database.DB.Beginx()
+	if err != nil {
+		log.Printf("Error starting blog transition: %v", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	transition, err := transitionBlog(tx, id, from, to, actor, comment)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing blog transition: %v", err)
+		return nil, err
+	}
+	return transition, nil
+}
+
+// transitionBlog applies a transition as part of tx, together with its events and audit event
+func transitionBlog(tx *sqlx.Tx, id uuid.UUID, from, to string, actor audit.Actor, comment string) (*Transition, error) {
+	result, err := tx.Exec("UPDATE blogs SET status = $1, updated_at = NOW() WHERE id = $2 AND status = $3", to, id, from)
+	if err != nil {
+		log.Printf("Error updating blog status: %v", err)
+		return nil, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil, ErrStatusChanged
+	}
+
+	transition := Transition{BlogID: id, FromState: from, ToState: to, ActorID: actor.UserID, Comment: comment}
+	query := `
+		INSERT INTO blog_transitions (blog_id, from_state, to_state, actor_id, comment, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		RETURNING id, created_at`
+	if err := tx.QueryRow(query, id, from, to, actor.UserID, comment).Scan(&transition.ID, &transition.CreatedAt); err != nil {
+		log.Printf("Error recording blog transition: %v", err)
+		return nil, err
+	}
+
+	if err := publishTransition(tx, transition); err != nil {
+		return nil, err
+	}
+	action := audit.ActionUpdate
+	if to == StatusPublished {
+		action = audit.ActionPublish
+	}
+	err = audit.Write(tx, actor, action, "blog", id.String(),
+		map[string]string{"status": from},
+		map[string]string{"status": to, "comment": comment})
+	if err != nil {
+		return nil, err
+	}
+	return &transition, nil
+}
+
+// GetBlogTransitions retrieves the workflow history of a blog, oldest first
+func GetBlogTransitions(id uuid.UUID) ([]Transition, error) {
+	transitions := []Transition{}
+	query := "SELECT * FROM blog_transitions WHERE blog_id = $1 ORDER BY id"
+	err := database.DB.Select(&transitions, query, id)
+	if err != nil {
+		log.Printf("Error fetching blog transitions: %v", err)
+		return nil, err
+	}
+	return transitions, nil
+}
+
+// publishTransition writes blog.transitioned and a state specific event such as blog.published to
+// the outbox as part of tx
+func publishTransition(tx *sqlx.Tx, t Transition) error {
+	for _, eventType := range []string{"blog.transitioned", "blog." + t.ToState} {
+		err := outbox.Write(tx, events.Event{
+			Type:       eventType,
+			EntityType: "blog",
+			EntityID:   t.BlogID.String(),
+			Data:       t,
+			OccurredAt: t.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
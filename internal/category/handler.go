@@ -1,11 +1,14 @@
 package category
 
 import (
+	"cms-project/internal/audit"
 	"cms-project/pkg/response"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
-	"strconv"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 )
 
@@ -29,11 +32,13 @@ func GetCategoriesHandler(w http.ResponseWriter, r *http.Request) {
 // @Summary Create a new category
 // @Description Add a new category to the database
 // @Tags Category
+// @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Param category body category.CreateCategoryRequest true "Category data"
 // @Success 201 {object} response.APIResponse
 // @Failure 400 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
 // @Failure 500 {object} response.APIResponse
 // @Router /categories [post]
 func CreateCategoryHandler(w http.ResponseWriter, r *http.Request) {
@@ -45,7 +50,11 @@ func CreateCategoryHandler(w http.ResponseWriter, r *http.Request) {
 	category := Category{
 		CreateCategoryRequest: req,
 	}
-	if err := CreateCategory(category); err != nil {
+	if err := CreateCategory(&category, audit.ActorFrom(r)); err != nil {
+		if errors.Is(err, ErrSlugTaken) {
+			response.JSON(w, http.StatusConflict, false, err.Error(), nil)
+			return
+		}
 		response.JSON(w, http.StatusInternalServerError, false, "Failed to create category", nil)
 		return
 	}
@@ -56,16 +65,16 @@ func CreateCategoryHandler(w http.ResponseWriter, r *http.Request) {
 // @Summary Get a category by ID
 // @Description Retrieve a specific category using its ID
 // @Tags Category
-// @Param id path int true "Category ID"
+// @Param id path string true "Category ID"
 // @Success 200 {object} response.APIResponse
 // @Failure 400 {object} response.APIResponse
 // @Failure 404 {object} response.APIResponse
 // @Router /categories/{id} [get]
 func GetCategoryByIDHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	id, err := strconv.Atoi(vars["id"])
+	id, err := uuid.Parse(vars["id"])
 	if err != nil {
-		response.JSON(w, http.StatusBadRequest, false, "Invalid category ID", nil)
+		response.JSON(w, http.StatusBadRequest, false, "Invalid category ID format", nil)
 		return
 	}
 	category, err := GetCategoryByID(id)
@@ -80,21 +89,55 @@ func GetCategoryByIDHandler(w http.ResponseWriter, r *http.Request) {
 // @Summary Delete a category
 // @Description Remove a category from the database
 // @Tags Category
-// @Param id path int true "Category ID"
+// @Security BearerAuth
+// @Param id path string true "Category ID"
 // @Success 204 {object} response.APIResponse
 // @Failure 400 {object} response.APIResponse
 // @Failure 500 {object} response.APIResponse
 // @Router /categories/{id} [delete]
 func DeleteCategoryHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	id, err := strconv.Atoi(vars["id"])
+	id, err := uuid.Parse(vars["id"])
 	if err != nil {
-		response.JSON(w, http.StatusBadRequest, false, "Invalid category ID", nil)
+		response.JSON(w, http.StatusBadRequest, false, "Invalid category ID format", nil)
 		return
 	}
-	if err := DeleteCategory(id); err != nil {
+	existing, err := GetCategoryByID(id)
+	if err != nil {
+		response.JSON(w, http.StatusNotFound, false, "Category not found", nil)
+		return
+	}
+	if err := DeleteCategory(existing, audit.ActorFrom(r)); err != nil {
 		response.JSON(w, http.StatusInternalServerError, false, "Failed to delete category", nil)
 		return
 	}
 	response.JSON(w, http.StatusNoContent, true, "Category deleted successfully", nil)
 }
+
+// GetCategoryAncestorsHandler handles retrieving the ancestor chain of a category
+// @Summary Get category ancestors
+// @Description Retrieve the categories from the root down to the given category, for breadcrumbs
+// @Tags Category
+// @Param id path string true "Category ID"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /categories/{id}/ancestors [get]
+func GetCategoryAncestorsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid category ID format", nil)
+		return
+	}
+	categories, err := GetCategoryAncestors(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.JSON(w, http.StatusNotFound, false, "Category not found", nil)
+			return
+		}
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to retrieve category ancestors", nil)
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "Category ancestors retrieved successfully", categories)
+}
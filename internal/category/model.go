@@ -8,8 +8,10 @@ import (
 
 // Category represents a blog category
 type CreateCategoryRequest struct {
-	Name        string  `json:"name" example:"Technology"`
-	Description *string `json:"description,omitempty" example:"All about technology"`
+	Name        string     `json:"name" example:"Technology"`
+	Slug        string     `db:"slug" json:"slug,omitempty" example:"technology"` // generated from the name when empty
+	Description *string    `json:"description,omitempty" example:"All about technology"`
+	ParentID    *uuid.UUID `db:"parent_id" json:"parent_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
 }
 
 // Category represents a blog category
@@ -1,11 +1,16 @@
 package category
 
-import "github.com/gorilla/mux"
+import (
+	"cms-project/internal/user"
+
+	"github.com/gorilla/mux"
+)
 
 // RegisterCategoryRoutes registers all category-related routes
 func RegisterCategoryRoutes(r *mux.Router) {
-	r.HandleFunc("", GetCategoriesHandler).Methods("GET")                 // List categories
-	r.HandleFunc("", CreateCategoryHandler).Methods("POST")               // Create a category
-	r.HandleFunc("/{id:[0-9]+}", GetCategoryByIDHandler).Methods("GET")   // Get category by ID
-	r.HandleFunc("/{id:[0-9]+}", DeleteCategoryHandler).Methods("DELETE") // Delete a category
+	r.HandleFunc("", GetCategoriesHandler).Methods("GET")                                                            // List categories
+	r.Handle("", user.Require(user.PermCategoriesWrite, CreateCategoryHandler)).Methods("POST")                      // Create a category
+	r.HandleFunc("/{id:[a-fA-F0-9-]+}", GetCategoryByIDHandler).Methods("GET")                                       // Get category by ID
+	r.Handle("/{id:[a-fA-F0-9-]+}", user.Require(user.PermCategoriesWrite, DeleteCategoryHandler)).Methods("DELETE") // Delete a category
+	r.HandleFunc("/{id:[a-fA-F0-9-]+}/ancestors", GetCategoryAncestorsHandler).Methods("GET")                        // Breadcrumb chain
 }
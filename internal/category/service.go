@@ -1,10 +1,26 @@
 package category
 
 import (
+	"cms-project/internal/audit"
 	"cms-project/internal/database"
+	"cms-project/internal/events"
+	"cms-project/internal/outbox"
+	"cms-project/pkg/slug"
+	"database/sql"
+	"errors"
 	"log"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
+// ErrSlugTaken is returned when another category already uses the slug
+var ErrSlugTaken = errors.New("slug is already used by another category")
+
+// maxAncestorDepth guards ancestor queries against parent_id cycles
+const maxAncestorDepth = 32
+
 // GetAllCategories retrieves all categories from the database
 func GetAllCategories() ([]Category, error) {
 	var categories []Category
@@ -17,19 +33,53 @@ func GetAllCategories() ([]Category, error) {
 	return categories, nil
 }
 
-// CreateCategory inserts a new category into the database
-func CreateCategory(category Category) error {
-	query := "INSERT INTO categories (name, description) VALUES ($1, $2)"
-	_, err := database.DB.Exec(query, category.Name, category.Description)
+// CreateCategory inserts a new category into the database, filling in its ID and creation time.
+// The category.created event and the audit event are written in the same transaction.
+func CreateCategory(category *Category, actor audit.Actor) error {
+	query := "INSERT INTO categories (id, name, slug, description, parent_id) VALUES ($1, $2, $3, $4, $5) RETURNING created_at"
+	category.ID = uuid.New()
+	if category.Slug == "" {
+		category.Slug = category.Name
+	}
+	// Names without any transliterable letters, such as Cyrillic or CJK ones, fall back to the ID
+	if category.Slug = slug.Make(category.Slug); category.Slug == "" {
+		category.Slug = category.ID.String()
+	}
+
+	tx, err := database.DB.Beginx()
 	if err != nil {
+		log.Printf("Error starting category transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if category.Slug, err = uniqueSlug(tx, category.Slug); err != nil {
+		return err
+	}
+	err = tx.QueryRow(query, category.ID, category.Name, category.Slug, category.Description, category.ParentID).Scan(&category.CreatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrSlugTaken
+		}
 		log.Printf("Error creating category: %v", err)
 		return err
 	}
+	if err := publishCategoryEvent(tx, "category.created", category.ID, category); err != nil {
+		return err
+	}
+	if err := audit.Write(tx, actor, audit.ActionCreate, "category", category.ID.String(), nil, category); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing category: %v", err)
+		return err
+	}
 	return nil
 }
 
 // GetCategoryByID retrieves a single category by ID
-func GetCategoryByID(id int) (*Category, error) {
+func GetCategoryByID(id uuid.UUID) (*Category, error) {
 	var category Category
 	query := "SELECT * FROM categories WHERE id = $1"
 	err := database.DB.Get(&category, query, id)
@@ -40,13 +90,81 @@ func GetCategoryByID(id int) (*Category, error) {
 	return &category, nil
 }
 
-// DeleteCategory deletes a category by ID
-func DeleteCategory(id int) error {
-	query := "DELETE FROM categories WHERE id = $1"
-	_, err := database.DB.Exec(query, id)
+// GetCategoryBySlug retrieves a single category by slug
+func GetCategoryBySlug(categorySlug string) (*Category, error) {
+	var category Category
+	query := "SELECT * FROM categories WHERE slug = $1"
+	err := database.DB.Get(&category, query, categorySlug)
 	if err != nil {
+		log.Printf("Error retrieving category by slug: %v", err)
+		return nil, err
+	}
+	return &category, nil
+}
+
+// DeleteCategory deletes a category, recording category.deleted and the audit event in the same
+// transaction
+func DeleteCategory(category *Category, actor audit.Actor) error {
+	tx, err := database.DB.Beginx()
+	if err != nil {
+		log.Printf("Error starting category transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "DELETE FROM categories WHERE id = $1"
+	if _, err := tx.Exec(query, category.ID); err != nil {
 		log.Printf("Error deleting category: %v", err)
 		return err
 	}
+	if err := publishCategoryEvent(tx, "category.deleted", category.ID, nil); err != nil {
+		return err
+	}
+	if err := audit.Write(tx, actor, audit.ActionDelete, "category", category.ID.String(), category, nil); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing category deletion: %v", err)
+		return err
+	}
 	return nil
 }
+
+// GetCategoryAncestors retrieves the chain of categories from the root down to the given category
+func GetCategoryAncestors(id uuid.UUID) ([]Category, error) {
+	var categories []Category
+	query := `
+		WITH RECURSIVE ancestors AS (
+			SELECT id, parent_id, 0 AS depth FROM categories WHERE id = $1
+			UNION ALL
+			SELECT c.id, c.parent_id, a.depth + 1
+			FROM categories c JOIN ancestors a ON c.id = a.parent_id
+			WHERE a.depth < $2
+		)
+		SELECT c.* FROM ancestors a JOIN categories c ON c.id = a.id
+		ORDER BY a.depth DESC`
+	err := database.DB.Select(&categories, query, id, maxAncestorDepth)
+	if err != nil {
+		log.Printf("Error retrieving category ancestors: %v", err)
+		return nil, err
+	}
+	if len(categories) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return categories, nil
+}
+
+// uniqueSlug returns a slug for a new category that no other category uses, numbering it when needed
+func uniqueSlug(tx *sqlx.Tx, base string) (string, error) {
+	var taken []string
+	if err := tx.Select(&taken, "SELECT slug FROM categories WHERE slug = $1 OR slug LIKE $2", base, base+"-%"); err != nil {
+		log.Printf("Error checking category slugs: %v", err)
+		return "", err
+	}
+	return slug.Unique(base, taken), nil
+}
+
+// publishCategoryEvent writes a category event to the outbox as part of tx
+func publishCategoryEvent(tx *sqlx.Tx, eventType string, id uuid.UUID, data interface{}) error {
+	return outbox.Write(tx, events.Event{Type: eventType, EntityType: "category", EntityID: id.String(), Data: data})
+}
@@ -0,0 +1,214 @@
+package comment
+
+import (
+	"cms-project/internal/audit"
+	"cms-project/pkg/request"
+	"cms-project/pkg/response"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// GetThreadHandler handles retrieving the comments of a blog
+// @Summary Get blog comments
+// @Description Retrieve the approved comments of a blog as a thread, and whether new comments are accepted
+// @Tags Comment
+// @Param id path string true "Blog ID"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /blogs/{id}/comments [get]
+func GetThreadHandler(w http.ResponseWriter, r *http.Request) {
+	blogID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid blog ID format", nil)
+		return
+	}
+	thread, err := GetThread(blogID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.JSON(w, http.StatusNotFound, false, "Blog not found", nil)
+			return
+		}
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to retrieve comments", nil)
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "Comments retrieved successfully", thread)
+}
+
+// CreateCommentHandler handles submitting a comment
+// @Summary Comment on a blog
+// @Description Submit a comment or reply on a published blog. Comments are held for moderation.
+// @Tags Comment
+// @Param id path string true "Blog ID"
+// @Param comment body comment.CreateCommentRequest true "Comment"
+// @Success 202 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /blogs/{id}/comments [post]
+func CreateCommentHandler(w http.ResponseWriter, r *http.Request) {
+	blogID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid blog ID format", nil)
+		return
+	}
+	var req CreateCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid input", nil)
+		return
+	}
+
+	comment, err := CreateComment(r.Context(), blogID, req, request.ClientIP(r), r.UserAgent())
+	if err != nil {
+		var invalid *ValidationError
+		switch {
+		case errors.As(err, &invalid), errors.Is(err, ErrInvalidParent), errors.Is(err, ErrTooDeep):
+			response.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
+		case errors.Is(err, ErrCommentsClosed):
+			response.JSON(w, http.StatusForbidden, false, err.Error(), nil)
+		case errors.Is(err, sql.ErrNoRows):
+			response.JSON(w, http.StatusNotFound, false, "Blog not found", nil)
+		default:
+			response.JSON(w, http.StatusInternalServerError, false, "Failed to submit comment", nil)
+		}
+		return
+	}
+
+	// Spam is not revealed to the sender, it looks like any comment awaiting moderation
+	status := comment.Status
+	if status == StatusSpam {
+		status = StatusPending
+	}
+	if status == StatusApproved {
+		response.JSON(w, http.StatusCreated, true, "Comment published", map[string]interface{}{"id": comment.ID, "status": status})
+		return
+	}
+	response.JSON(w, http.StatusAccepted, true, "Comment submitted for moderation", map[string]interface{}{"id": comment.ID, "status": status})
+}
+
+// UpdateSettingsHandler handles enabling or disabling comments on a blog
+// @Summary Enable or disable comments
+// @Description Turn the comment section of a blog on or off
+// @Tags Comment
+// @Security BearerAuth
+// @Param id path string true "Blog ID"
+// @Param settings body comment.SettingsRequest true "Comment settings"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /blogs/{id}/comments/settings [put]
+func UpdateSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	blogID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid blog ID format", nil)
+		return
+	}
+	var req SettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid input", nil)
+		return
+	}
+	if err := SetCommentsEnabled(blogID, req.Enabled, audit.ActorFrom(r)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.JSON(w, http.StatusNotFound, false, "Blog not found", nil)
+			return
+		}
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to update comment settings", nil)
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "Comment settings updated successfully", req)
+}
+
+// GetQueueHandler handles retrieving the moderation queue
+// @Summary Get the moderation queue
+// @Description Retrieve comments for moderation, newest first. Defaults to pending comments.
+// @Tags Comment
+// @Security BearerAuth
+// @Param status query string false "Comment status (pending, approved, spam, trash, all)"
+// @Param blog_id query string false "Blog ID"
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of comments per page"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /comments [get]
+func GetQueueHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := Filter{Status: q.Get("status")}
+	switch filter.Status {
+	case "":
+		filter.Status = StatusPending
+	case "all":
+		filter.Status = ""
+	case StatusPending, StatusApproved, StatusSpam, StatusTrash:
+	default:
+		response.JSON(w, http.StatusBadRequest, false, "Invalid comment status", nil)
+		return
+	}
+
+	var err error
+	filter.Page, err = strconv.Atoi(q.Get("page"))
+	if err != nil || filter.Page < 1 {
+		filter.Page = 1
+	}
+	filter.Limit, err = strconv.Atoi(q.Get("limit"))
+	if err != nil || filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+	if value := q.Get("blog_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			response.JSON(w, http.StatusBadRequest, false, "Invalid blog ID format", nil)
+			return
+		}
+		filter.BlogID = &id
+	}
+
+	comments, err := GetComments(filter)
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to retrieve comments", nil)
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "Comments retrieved successfully", comments)
+}
+
+// ModerateCommentsHandler handles bulk moderation
+// @Summary Moderate comments
+// @Description Approve, unapprove, mark as spam, trash or delete several comments at once
+// @Tags Comment
+// @Security BearerAuth
+// @Param moderation body comment.BulkRequest true "Comment IDs and action"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /comments/moderate [post]
+func ModerateCommentsHandler(w http.ResponseWriter, r *http.Request) {
+	var req BulkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.IDs) == 0 {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid input", nil)
+		return
+	}
+
+	before, err := ModerateComments(req.IDs, req.Action, audit.ActorFrom(r))
+	if err != nil {
+		if errors.Is(err, ErrInvalidAction) || errors.Is(err, ErrTooManyIDs) {
+			response.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
+			return
+		}
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to moderate comments", nil)
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "Comments moderated successfully", map[string]int{"affected": len(before)})
+}
@@ -0,0 +1,80 @@
+package comment
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Comment statuses
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusSpam     = "spam"
+	StatusTrash    = "trash"
+)
+
+// CreateCommentRequest represents a comment submitted by a reader
+type CreateCommentRequest struct {
+	ParentID    *uuid.UUID `json:"parent_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"` // comment replied to
+	AuthorName  string     `json:"author_name" example:"Ayşe"`
+	AuthorEmail string     `json:"author_email" example:"ayse@example.com"`
+	AuthorURL   string     `json:"author_url,omitempty" example:"https://ayse.example.com"`
+	Content     string     `json:"content" example:"Great post!"`
+	Honeypot    string     `json:"honeypot,omitempty" example:""` // hidden form field, only filled in by bots
+}
+
+// Comment represents a reader comment on a blog
+type Comment struct {
+	ID          uuid.UUID  `db:"id" json:"id"`
+	BlogID      uuid.UUID  `db:"blog_id" json:"blog_id"`
+	ParentID    *uuid.UUID `db:"parent_id" json:"parent_id,omitempty"`
+	Depth       int        `db:"depth" json:"depth"`
+	AuthorName  string     `db:"author_name" json:"author_name"`
+	AuthorEmail string     `db:"author_email" json:"author_email"`
+	AuthorURL   string     `db:"author_url" json:"author_url,omitempty"`
+	Content     string     `db:"content" json:"content"`
+	ContentHTML string     `db:"content_html" json:"content_html"`
+	Status      string     `db:"status" json:"status" example:"pending"`
+	IP          string     `db:"ip" json:"ip"`
+	UserAgent   string     `db:"user_agent" json:"user_agent"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// PublicComment is an approved comment as shown to readers, with its replies
+type PublicComment struct {
+	ID          uuid.UUID        `json:"id"`
+	ParentID    *uuid.UUID       `json:"parent_id,omitempty"`
+	AuthorName  string           `json:"author_name" example:"Ayşe"`
+	AuthorURL   string           `json:"author_url,omitempty"`
+	ContentHTML string           `json:"content_html" example:"<p>Great post!</p>"`
+	CreatedAt   time.Time        `json:"created_at"`
+	Replies     []*PublicComment `json:"replies"`
+}
+
+// Thread is the comment section of a blog
+type Thread struct {
+	Open     bool             `json:"open"` // whether new comments are accepted
+	Count    int              `json:"count"`
+	Comments []*PublicComment `json:"comments"`
+}
+
+// SettingsRequest enables or disables comments on a blog
+type SettingsRequest struct {
+	Enabled bool `json:"enabled" example:"false"`
+}
+
+// BulkRequest applies a moderation action to several comments
+type BulkRequest struct {
+	IDs    []uuid.UUID `json:"ids"`
+	Action string      `json:"action" example:"approve"` // approve, pending, spam, trash or delete
+}
+
+// Filter narrows down the comments of the moderation queue
+type Filter struct {
+	Status string
+	BlogID *uuid.UUID
+	Page   int
+	Limit  int
+}
@@ -0,0 +1,20 @@
+package comment
+
+import (
+	"cms-project/internal/user"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterBlogCommentRoutes registers the comment routes nested under a blog
+func RegisterBlogCommentRoutes(r *mux.Router) {
+	r.HandleFunc("", GetThreadHandler).Methods("GET")                                                    // Approved comments as a thread
+	r.HandleFunc("", CreateCommentHandler).Methods("POST")                                               // Submit a comment
+	r.Handle("/settings", user.Require(user.PermCommentsModerate, UpdateSettingsHandler)).Methods("PUT") // Enable or disable comments
+}
+
+// RegisterCommentRoutes registers the moderation routes
+func RegisterCommentRoutes(r *mux.Router) {
+	r.Handle("", user.Require(user.PermCommentsModerate, GetQueueHandler)).Methods("GET")                   // Moderation queue
+	r.Handle("/moderate", user.Require(user.PermCommentsModerate, ModerateCommentsHandler)).Methods("POST") // Bulk actions
+}
@@ -0,0 +1,343 @@
+package comment
+
+import (
+	"cms-project/internal/audit"
+	"cms-project/internal/blog"
+	"cms-project/internal/database"
+	"cms-project/internal/workflow"
+	"cms-project/pkg/markup"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	maxDepth         = 5
+	maxNameLength    = 100
+	maxContentLength = 5000
+	maxBulkSize      = 100
+)
+
+var (
+	ErrCommentsClosed = errors.New("comments are closed for this blog")
+	ErrInvalidParent  = errors.New("the comment replied to does not exist on this blog")
+	ErrTooDeep        = fmt.Errorf("replies can be nested at most %d levels deep", maxDepth)
+	ErrInvalidAction  = errors.New("action must be one of approve, pending, spam, trash or delete")
+	ErrTooManyIDs     = fmt.Errorf("at most %d comments can be moderated at once", maxBulkSize)
+)
+
+// bulkStatuses maps moderation actions to the status they set
+var bulkStatuses = map[string]string{
+	"approve": StatusApproved,
+	"pending": StatusPending,
+	"spam":    StatusSpam,
+	"trash":   StatusTrash,
+}
+
+// ValidationError describes an invalid comment submission
+type ValidationError struct {
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Reason
+}
+
+// CreateComment stores a reader comment on a published blog. The spam checker decides whether it
+// goes to the spam folder; other comments wait for moderation unless COMMENTS_AUTO_APPROVE is set.
+func CreateComment(ctx context.Context, blogID uuid.UUID, req CreateCommentRequest, ip, userAgent string) (*Comment, error) {
+	comment := &Comment{
+		ID:          uuid.New(),
+		BlogID:      blogID,
+		ParentID:    req.ParentID,
+		AuthorName:  strings.TrimSpace(req.AuthorName),
+		AuthorEmail: strings.TrimSpace(req.AuthorEmail),
+		AuthorURL:   strings.TrimSpace(req.AuthorURL),
+		Content:     strings.TrimSpace(req.Content),
+		IP:          ip,
+		UserAgent:   userAgent,
+	}
+	if err := validate(comment); err != nil {
+		return nil, err
+	}
+
+	open, err := commentsOpen(blogID)
+	if err != nil {
+		return nil, err
+	}
+	if !open {
+		return nil, ErrCommentsClosed
+	}
+	if comment.ParentID != nil {
+		var parent Comment
+		query := "SELECT * FROM comments WHERE id = $1 AND blog_id = $2 AND status = $3"
+		if err := database.DB.Get(&parent, query, *comment.ParentID, blogID, StatusApproved); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, ErrInvalidParent
+			}
+			log.Printf("Error fetching parent comment: %v", err)
+			return nil, err
+		}
+		if parent.Depth+1 > maxDepth {
+			return nil, ErrTooDeep
+		}
+		comment.Depth = parent.Depth + 1
+	}
+
+	verdict, err := checker.Check(ctx, Submission{Comment: comment, Honeypot: req.Honeypot})
+	if err != nil {
+		// A failing checker must not drop comments, leave them to the moderators
+		log.Printf("Error checking comment for spam: %v", err)
+		verdict = VerdictUnsure
+	}
+	switch {
+	case verdict == VerdictSpam:
+		comment.Status = StatusSpam
+	case verdict == VerdictHam && os.Getenv("COMMENTS_AUTO_APPROVE") == "true":
+		comment.Status = StatusApproved
+	default:
+		comment.Status = StatusPending
+	}
+
+	if comment.ContentHTML, err = markup.Convert(markup.FormatPlain, comment.Content); err != nil {
+		return nil, err
+	}
+	query := `
+		INSERT INTO comments (id, blog_id, parent_id, depth, author_name, author_email, author_url, content, content_html, status, ip, user_agent)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING created_at, updated_at`
+	err = database.DB.QueryRow(query, comment.ID, comment.BlogID, comment.ParentID, comment.Depth, comment.AuthorName, comment.AuthorEmail,
+		comment.AuthorURL, comment.Content, comment.ContentHTML, comment.Status, comment.IP, comment.UserAgent).Scan(&comment.CreatedAt, &comment.UpdatedAt)
+	if err != nil {
+		log.Printf("Error creating comment: %v", err)
+		return nil, err
+	}
+	return comment, nil
+}
+
+// GetThread retrieves the approved comments of a blog as a tree, oldest first
+func GetThread(blogID uuid.UUID) (*Thread, error) {
+	open, err := commentsOpen(blogID)
+	if err != nil {
+		return nil, err
+	}
+	var comments []Comment
+	query := "SELECT * FROM comments WHERE blog_id = $1 AND status = $2 ORDER BY created_at"
+	if err := database.DB.Select(&comments, query, blogID, StatusApproved); err != nil {
+		log.Printf("Error fetching comments: %v", err)
+		return nil, err
+	}
+	thread := buildThread(comments)
+	thread.Open = open
+	return thread, nil
+}
+
+// buildThread arranges approved comments, oldest first, into a tree
+func buildThread(comments []Comment) *Thread {
+	thread := &Thread{Comments: []*PublicComment{}}
+	nodes := make(map[uuid.UUID]*PublicComment, len(comments))
+	for _, c := range comments {
+		nodes[c.ID] = &PublicComment{
+			ID:          c.ID,
+			ParentID:    c.ParentID,
+			AuthorName:  c.AuthorName,
+			AuthorURL:   c.AuthorURL,
+			ContentHTML: c.ContentHTML,
+			CreatedAt:   c.CreatedAt,
+			Replies:     []*PublicComment{},
+		}
+	}
+	for _, c := range comments {
+		node := nodes[c.ID]
+		if c.ParentID == nil {
+			thread.Comments = append(thread.Comments, node)
+			thread.Count++
+			continue
+		}
+		// Replies to comments that are no longer approved are hidden with their parent
+		if parent, ok := nodes[*c.ParentID]; ok && visible(nodes, parent) {
+			parent.Replies = append(parent.Replies, node)
+			thread.Count++
+		}
+	}
+	return thread
+}
+
+// GetComments retrieves comments for moderation, newest first
+func GetComments(filter Filter) ([]Comment, error) {
+	comments := []Comment{}
+	var conditions []string
+	var args []interface{}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+	if filter.BlogID != nil {
+		args = append(args, *filter.BlogID)
+		conditions = append(conditions, fmt.Sprintf("blog_id = $%d", len(args)))
+	}
+
+	query := "SELECT * FROM comments"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	if err := database.DB.Select(&comments, query, args...); err != nil {
+		log.Printf("Error fetching comments for moderation: %v", err)
+		return nil, err
+	}
+	return comments, nil
+}
+
+// ModerateComments applies a moderation action to several comments in one transaction, together with
+// an audit event per comment, and returns the comments as they were before
+func ModerateComments(ids []uuid.UUID, action string, actor audit.Actor) ([]Comment, error) {
+	status, ok := bulkStatuses[action]
+	if !ok && action != "delete" {
+		return nil, ErrInvalidAction
+	}
+	if len(ids) > maxBulkSize {
+		return nil, ErrTooManyIDs
+	}
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, id.String())
+	}
+
+	tx, err := database.DB.Beginx()
+	if err != nil {
+		log.Printf("Error starting moderation transaction: %v", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var before []Comment
+	if err := tx.Select(&before, "SELECT * FROM comments WHERE id = ANY($1) FOR UPDATE", pq.Array(keys)); err != nil {
+		log.Printf("Error fetching comments to moderate: %v", err)
+		return nil, err
+	}
+	if action == "delete" {
+		_, err = tx.Exec("DELETE FROM comments WHERE id = ANY($1)", pq.Array(keys))
+	} else {
+		_, err = tx.Exec("UPDATE comments SET status = $1, updated_at = NOW() WHERE id = ANY($2) AND status <> $1", status, pq.Array(keys))
+	}
+	if err != nil {
+		log.Printf("Error moderating comments: %v", err)
+		return nil, err
+	}
+	for _, c := range before {
+		if action == "delete" {
+			err = audit.Write(tx, actor, audit.ActionDelete, "comment", c.ID.String(), c, nil)
+		} else {
+			after := c
+			after.Status = status
+			err = audit.Write(tx, actor, audit.ActionUpdate, "comment", c.ID.String(), c, after)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing moderation: %v", err)
+		return nil, err
+	}
+	return before, nil
+}
+
+// SetCommentsEnabled turns comments on a blog on or off, recording the audit event in the same
+// transaction
+func SetCommentsEnabled(blogID uuid.UUID, enabled bool, actor audit.Actor) error {
+	tx, err := database.DB.Beginx()
+	if err != nil {
+		log.Printf("Error starting comment settings transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("UPDATE blogs SET comments_enabled = $1 WHERE id = $2", enabled, blogID)
+	if err != nil {
+		log.Printf("Error updating comment settings: %v", err)
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	after := map[string]bool{"comments_enabled": enabled}
+	if err := audit.Write(tx, actor, audit.ActionUpdate, "blog", blogID.String(), nil, after); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing comment settings: %v", err)
+		return err
+	}
+	return nil
+}
+
+// commentsOpen reports whether a blog accepts new comments: it must be published, have comments
+// enabled and, with COMMENTS_CLOSE_AFTER_DAYS set, have been published recently enough
+func commentsOpen(blogID uuid.UUID) (bool, error) {
+	var state struct {
+		Status      string    `db:"status"`
+		Enabled     bool      `db:"comments_enabled"`
+		PublishedAt time.Time `db:"published_at"`
+	}
+	query := "SELECT b.status, b.comments_enabled, " + blog.PublishedAt + " AS published_at FROM blogs b WHERE b.id = $1"
+	if err := database.DB.Get(&state, query, blogID); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("Error fetching blog comment state: %v", err)
+		}
+		return false, err
+	}
+	if state.Status != workflow.Published || !state.Enabled {
+		return false, nil
+	}
+	if days, err := strconv.Atoi(os.Getenv("COMMENTS_CLOSE_AFTER_DAYS")); err == nil && days > 0 {
+		return time.Since(state.PublishedAt) < time.Duration(days)*24*time.Hour, nil
+	}
+	return true, nil
+}
+
+// validate checks the fields of a submitted comment
+func validate(c *Comment) error {
+	if c.AuthorName == "" || utf8.RuneCountInString(c.AuthorName) > maxNameLength {
+		return &ValidationError{fmt.Sprintf("author_name is required and can have at most %d characters", maxNameLength)}
+	}
+	if _, err := mail.ParseAddress(c.AuthorEmail); err != nil {
+		return &ValidationError{"author_email must be a valid email address"}
+	}
+	if c.AuthorURL != "" {
+		if u, err := url.Parse(c.AuthorURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return &ValidationError{"author_url must be an http or https URL"}
+		}
+	}
+	if c.Content == "" || utf8.RuneCountInString(c.Content) > maxContentLength {
+		return &ValidationError{fmt.Sprintf("content is required and can have at most %d characters", maxContentLength)}
+	}
+	return nil
+}
+
+// visible reports whether a node and all its ancestors are in the approved set
+func visible(nodes map[uuid.UUID]*PublicComment, node *PublicComment) bool {
+	for depth := 0; node.ParentID != nil && depth <= maxDepth; depth++ {
+		parent, ok := nodes[*node.ParentID]
+		if !ok {
+			return false
+		}
+		node = parent
+	}
+	return true
+}
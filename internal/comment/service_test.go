@@ -0,0 +1,57 @@
+package comment
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestBuildThread(t *testing.T) {
+	id := func(n byte) uuid.UUID { return uuid.UUID{15: n} }
+	reply := func(n, parent byte) Comment {
+		p := id(parent)
+		return Comment{ID: id(n), ParentID: &p}
+	}
+	// Only approved comments are loaded: 3 and 7 are pending, spam or trashed
+	comments := []Comment{
+		{ID: id(1)},
+		{ID: id(2)},
+		reply(4, 1),
+		reply(5, 4),
+		reply(6, 3), // parent not approved
+		reply(8, 6), // grandparent not approved
+		reply(9, 2),
+		reply(10, 7),
+	}
+	thread := buildThread(comments)
+
+	if thread.Count != 5 {
+		t.Errorf("Count = %d, want 5", thread.Count)
+	}
+	got := map[uuid.UUID][]uuid.UUID{}
+	var walk func(nodes []*PublicComment, parent uuid.UUID)
+	walk = func(nodes []*PublicComment, parent uuid.UUID) {
+		for _, n := range nodes {
+			got[parent] = append(got[parent], n.ID)
+			walk(n.Replies, n.ID)
+		}
+	}
+	walk(thread.Comments, uuid.Nil)
+	want := map[uuid.UUID][]uuid.UUID{
+		uuid.Nil: {id(1), id(2)},
+		id(1):    {id(4)},
+		id(4):    {id(5)},
+		id(2):    {id(9)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("buildThread tree = %v, want %v", got, want)
+	}
+}
+
+func TestBuildThreadEmpty(t *testing.T) {
+	thread := buildThread(nil)
+	if thread.Count != 0 || thread.Comments == nil || len(thread.Comments) != 0 {
+		t.Errorf("buildThread(nil) = %+v, want an empty list", thread)
+	}
+}
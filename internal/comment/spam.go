@@ -0,0 +1,57 @@
+package comment
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+// Verdict is the outcome of a spam check
+type Verdict int
+
+const (
+	VerdictHam    Verdict = iota // looks legitimate
+	VerdictUnsure                // needs a human look
+	VerdictSpam                  // rejected into the spam folder
+)
+
+// Submission is a comment being checked together with request details
+type Submission struct {
+	Comment  *Comment
+	Honeypot string
+}
+
+// SpamChecker decides whether a submitted comment is spam. Implementations can call external
+// services such as Akismet; the default one uses local heuristics only.
+type SpamChecker interface {
+	Check(ctx context.Context, s Submission) (Verdict, error)
+}
+
+// HeuristicChecker flags comments that fill in the honeypot field or contain too many links
+type HeuristicChecker struct {
+	MaxLinks int // more links than this is spam, any link at all needs moderation
+}
+
+var linkPattern = regexp.MustCompile(`(?i)(https?://|www\.|<a\s)`)
+
+// Check implements SpamChecker
+func (h HeuristicChecker) Check(_ context.Context, s Submission) (Verdict, error) {
+	if strings.TrimSpace(s.Honeypot) != "" {
+		return VerdictSpam, nil
+	}
+	links := len(linkPattern.FindAllStringIndex(s.Comment.Content, -1))
+	switch {
+	case links > h.MaxLinks:
+		return VerdictSpam, nil
+	case links > 0:
+		return VerdictUnsure, nil
+	}
+	return VerdictHam, nil
+}
+
+var checker SpamChecker = HeuristicChecker{MaxLinks: 2}
+
+// SetSpamChecker replaces the spam checker used for new comments
+func SetSpamChecker(c SpamChecker) {
+	checker = c
+}
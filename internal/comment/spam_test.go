@@ -0,0 +1,43 @@
+package comment
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestHeuristicCheckerCheck(t *testing.T) {
+	checker := HeuristicChecker{MaxLinks: 2}
+	tests := []struct {
+		name, content, honeypot string
+		want                    Verdict
+	}{
+		{"plain", "Great post, thanks!", "", VerdictHam},
+		{"honeypot", "Great post, thanks!", "http://spam.example", VerdictSpam},
+		{"blank honeypot", "Great post, thanks!", "  \n", VerdictHam},
+		{"one link", "See https://example.com", "", VerdictUnsure},
+		{"www link", "See www.example.com", "", VerdictUnsure},
+		{"anchor", `See <a href="/x">this</a>`, "", VerdictUnsure},
+		{"at the limit", "http://a.example and HTTPS://b.example", "", VerdictUnsure},
+		{"over the limit", "http://a.example http://b.example www.c.example", "", VerdictSpam},
+		{"many links", strings.Repeat("https://x.example ", 10), "", VerdictSpam},
+	}
+	for _, tt := range tests {
+		got, err := checker.Check(context.Background(), Submission{Comment: &Comment{Content: tt.content}, Honeypot: tt.honeypot})
+		if err != nil {
+			t.Errorf("%s: Check error = %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: Check = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHeuristicCheckerNoLinksAllowed(t *testing.T) {
+	checker := HeuristicChecker{}
+	got, _ := checker.Check(context.Background(), Submission{Comment: &Comment{Content: "https://example.com"}})
+	if got != VerdictSpam {
+		t.Errorf("Check = %v, want VerdictSpam", got)
+	}
+}
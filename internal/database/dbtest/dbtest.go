@@ -0,0 +1,26 @@
+// Package dbtest provides helpers for tests of code using the shared database connection
+package dbtest
+
+import (
+	"cms-project/internal/database"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+// Mock replaces the database with a mock for the duration of a test
+func Mock(t *testing.T) sqlmock.Sqlmock {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := database.DB
+	database.DB = sqlx.NewDb(db, "postgres")
+	t.Cleanup(func() {
+		database.DB = previous
+		db.Close()
+	})
+	return mock
+}
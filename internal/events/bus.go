@@ -0,0 +1,61 @@
+package events
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Event describes something that happened to a piece of content
+type Event struct {
+	ID         string      `json:"id,omitempty" example:"1042"` // outbox ID; stable across redeliveries, so receivers can skip duplicates
+	Type       string      `json:"type" example:"blog.published"`
+	EntityType string      `json:"entity_type" example:"blog"`
+	EntityID   string      `json:"entity_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Data       interface{} `json:"data,omitempty"`
+	OccurredAt time.Time   `json:"occurred_at"`
+}
+
+// Handler reacts to a published event
+type Handler func(Event)
+
+var (
+	mu       sync.RWMutex
+	nextID   int
+	handlers = map[int]Handler{}
+)
+
+// Subscribe registers a handler for every published event and returns a function that removes it
+func Subscribe(h Handler) func() {
+	mu.Lock()
+	defer mu.Unlock()
+	nextID++
+	id := nextID
+	handlers[id] = h
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		delete(handlers, id)
+	}
+}
+
+// Publish delivers an event to all subscribers. Each handler runs in its own goroutine so a slow
+// or panicking subscriber cannot hold up the request that caused the event.
+func Publish(e Event) {
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now()
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, h := range handlers {
+		go func(h Handler) {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("Event handler for %s panicked: %v", e.Type, r)
+				}
+			}()
+			h(e)
+		}(h)
+	}
+}
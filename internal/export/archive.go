@@ -0,0 +1,52 @@
+package export
+
+import (
+	"archive/zip"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// writeZip packs the output directory into a zip archive, leaving out the manifest
+func writeZip(dir, target string) error {
+	f, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	err = filepath.WalkDir(dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, name)
+		if err != nil || rel == manifestName {
+			return err
+		}
+		return addFile(zw, name, filepath.ToSlash(rel))
+	})
+	if err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// Helper to copy a file into a zip archive
+func addFile(zw *zip.Writer, name, rel string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	w, err := zw.Create(rel)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, src)
+	return err
+}
@@ -0,0 +1,412 @@
+package export
+
+import (
+	"bytes"
+	"cms-project/internal/blog"
+	"cms-project/internal/category"
+	"cms-project/internal/feed"
+	"cms-project/internal/media"
+	"cms-project/internal/site"
+	"cms-project/internal/sitemap"
+	"cms-project/internal/tag"
+	"cms-project/internal/theme"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// manifestName is the file in the output directory recording the previous export
+	manifestName  = ".export.json"
+	mediaPageSize = 100
+)
+
+// ErrZipInsideOutput is returned when the zip archive would be written into the output directory,
+// where it would end up packed into itself and removed as stale by the next export
+var ErrZipInsideOutput = errors.New("zip archive must not be inside the output directory")
+
+// feedFormats are the file names of the feeds written for every scope
+var feedFormats = []string{"rss.xml", "atom.xml", "feed.json"}
+
+// Options configures an export
+type Options struct {
+	Dir  string // output directory
+	Full bool   // render every blog instead of only those updated since the previous export
+	Zip  string // optional path of a zip archive of the output, outside Dir
+}
+
+// Result summarizes an export
+type Result struct {
+	Written   int // files created or changed
+	Unchanged int // files that were already current
+	Removed   int // files of the previous export that no longer exist
+}
+
+// manifest records what an export wrote so that the next one can be incremental
+type manifest struct {
+	ExportedAt time.Time `json:"exported_at"`
+	Files      []string  `json:"files"`
+}
+
+// exporter renders pages through the public routes and writes them to the output directory
+type exporter struct {
+	opts     Options
+	router   http.Handler
+	previous manifest
+	files    map[string]bool
+	result   Result
+}
+
+// Run renders every published blog, category and tag archive, paginated index, feed and sitemap
+// to a directory tree with pretty URLs, together with the theme assets and media files. Blogs
+// not updated since the previous export are kept as they are unless Full is set; a full export
+// is needed after changes that affect every page, such as menus or the theme.
+func Run(opts Options) (*Result, error) {
+	if opts.Zip != "" && inside(opts.Dir, opts.Zip) {
+		return nil, ErrZipInsideOutput
+	}
+	e := &exporter{opts: opts, router: newRouter(), files: make(map[string]bool)}
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, err
+	}
+	if err := e.loadManifest(); err != nil {
+		return nil, err
+	}
+	started := time.Now()
+
+	steps := []func() error{e.blogs, e.archives, e.feeds, e.sitemaps, e.assets, e.media}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return nil, err
+		}
+	}
+	if err := e.removeStale(); err != nil {
+		return nil, err
+	}
+	if err := e.saveManifest(started); err != nil {
+		return nil, err
+	}
+	if opts.Zip != "" {
+		if err := writeZip(opts.Dir, opts.Zip); err != nil {
+			return nil, err
+		}
+	}
+	return &e.result, nil
+}
+
+// newRouter registers the public routes the export renders, leaving out the API
+func newRouter() http.Handler {
+	r := mux.NewRouter()
+	media.RegisterFileRoutes(r.PathPrefix("/uploads").Subrouter())
+	feed.RegisterFeedRoutes(r.PathPrefix("/feeds").Subrouter())
+	sitemap.RegisterSitemapRoutes(r)
+	theme.RegisterThemeRoutes(r)
+	return r
+}
+
+// blogs renders every published blog, skipping those unchanged since the previous export
+func (e *exporter) blogs() error {
+	blogs, err := blog.GetPublishedBlogs(blog.PublishedFilter{}, 1, 0)
+	if err != nil {
+		return err
+	}
+	for _, b := range blogs {
+		p := site.BlogPath(b.Slug)
+		if !e.opts.Full && b.UpdatedAt.Before(e.previous.ExportedAt) && e.exists(filePath(p)) {
+			e.files[filePath(p)] = true
+			e.result.Unchanged++
+			continue
+		}
+		if err := e.page(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// archives renders the paginated index and the category and tag archives, along with the 404 page
+func (e *exporter) archives() error {
+	if err := e.paginated("/"); err != nil {
+		return err
+	}
+	categories, err := category.GetAllCategories()
+	if err != nil {
+		return err
+	}
+	for _, c := range categories {
+		if err := e.paginated(site.CategoryPath(c.Slug)); err != nil {
+			return err
+		}
+	}
+	tags, err := tag.GetTagCloud(math.MaxInt32)
+	if err != nil {
+		return err
+	}
+	for _, t := range tags {
+		if err := e.paginated(site.TagPath(t.Slug)); err != nil {
+			return err
+		}
+	}
+
+	// Static hosts commonly serve /404.html for unknown paths
+	body, _ := e.fetch("/404.html")
+	return e.write("404.html", body)
+}
+
+// feeds renders the site feed and the feeds of every category and author
+func (e *exporter) feeds() error {
+	prefixes := []string{"/feeds/"}
+	categories, err := category.GetAllCategories()
+	if err != nil {
+		return err
+	}
+	for _, c := range categories {
+		prefixes = append(prefixes, "/feeds/categories/"+c.Slug+"/")
+	}
+	blogs, err := blog.GetPublishedBlogs(blog.PublishedFilter{}, 1, 0)
+	if err != nil {
+		return err
+	}
+	authors := make(map[string]bool)
+	for _, b := range blogs {
+		if b.AuthorID != "" && !authors[b.AuthorID] {
+			authors[b.AuthorID] = true
+			prefixes = append(prefixes, "/feeds/authors/"+b.AuthorID+"/")
+		}
+	}
+
+	for _, prefix := range prefixes {
+		for _, format := range feedFormats {
+			if err := e.page(prefix + format); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// sitemaps renders the sitemap, the numbered sitemaps when it is an index, and robots.txt
+func (e *exporter) sitemaps() error {
+	pages, _, err := sitemap.Pages()
+	if err != nil {
+		return err
+	}
+	paths := []string{"/sitemap.xml", "/robots.txt"}
+	if len(pages) > 1 {
+		for n := 1; n <= len(pages); n++ {
+			paths = append(paths, fmt.Sprintf("/sitemaps/sitemap-%d.xml", n))
+		}
+	}
+	for _, p := range paths {
+		if err := e.page(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// assets copies the static files of the theme
+func (e *exporter) assets() error {
+	return fs.WalkDir(theme.Assets(), ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		return e.page("/assets/" + name)
+	})
+}
+
+// media copies the media files served by this application together with their derivatives.
+// Storage keys are content addressed, so files that were already exported are not fetched again.
+// Media on an external storage backend is linked by absolute URL and left where it is.
+func (e *exporter) media() error {
+	for page := 1; ; page++ {
+		items, err := media.GetMediaList(page, mediaPageSize)
+		if err != nil {
+			return err
+		}
+		for _, m := range items {
+			urls := []string{m.URL}
+			for _, v := range m.Variants {
+				urls = append(urls, v.URL)
+			}
+			for _, u := range urls {
+				if !strings.HasPrefix(u, "/") {
+					continue
+				}
+				name := filePath(u)
+				if e.exists(name) {
+					e.files[name] = true
+					e.result.Unchanged++
+					continue
+				}
+				body, status := e.fetch(u)
+				if status != http.StatusOK {
+					log.Printf("Skipping media file %s: status %d", u, status)
+					continue
+				}
+				if err := e.write(name, body); err != nil {
+					return err
+				}
+			}
+		}
+		if len(items) < mediaPageSize {
+			return nil
+		}
+	}
+}
+
+// paginated renders an archive and each of its following pages
+func (e *exporter) paginated(base string) error {
+	if err := e.page(base); err != nil {
+		return err
+	}
+	for n := 2; ; n++ {
+		p := fmt.Sprintf("%s/page/%d", strings.TrimSuffix(base, "/"), n)
+		body, status := e.fetch(p)
+		if status == http.StatusNotFound {
+			return nil
+		}
+		if status != http.StatusOK {
+			return fmt.Errorf("rendering %s: status %d", p, status)
+		}
+		if err := e.write(filePath(p), body); err != nil {
+			return err
+		}
+	}
+}
+
+// page renders a path that must exist and writes it to the output directory
+func (e *exporter) page(p string) error {
+	body, status := e.fetch(p)
+	if status != http.StatusOK {
+		return fmt.Errorf("rendering %s: status %d", p, status)
+	}
+	return e.write(filePath(p), body)
+}
+
+// fetch renders a path through the public routes as a browser would request it
+func (e *exporter) fetch(p string) ([]byte, int) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, p, nil)
+	req.Header.Set("Accept", "text/html")
+	e.router.ServeHTTP(rec, req)
+	return rec.Body.Bytes(), rec.Code
+}
+
+// write stores a file in the output directory unless it already has the same content
+func (e *exporter) write(name string, body []byte) error {
+	e.files[name] = true
+	target := filepath.Join(e.opts.Dir, filepath.FromSlash(name))
+	if current, err := os.ReadFile(target); err == nil && bytes.Equal(current, body) {
+		e.result.Unchanged++
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(target, body, 0o644); err != nil {
+		return err
+	}
+	e.result.Written++
+	return nil
+}
+
+// removeStale deletes the files of the previous export that were not written this time, such as
+// unpublished blogs
+func (e *exporter) removeStale() error {
+	for _, name := range e.previous.Files {
+		if e.files[name] {
+			continue
+		}
+		target := filepath.Join(e.opts.Dir, filepath.FromSlash(name))
+		if err := os.Remove(target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		e.result.Removed++
+		removeEmptyDirs(e.opts.Dir, filepath.Dir(target))
+	}
+	return nil
+}
+
+// loadManifest reads the manifest of the previous export, if any
+func (e *exporter) loadManifest() error {
+	data, err := os.ReadFile(filepath.Join(e.opts.Dir, manifestName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &e.previous)
+}
+
+// saveManifest records the files of this export and when it started
+func (e *exporter) saveManifest(started time.Time) error {
+	m := manifest{ExportedAt: started, Files: make([]string, 0, len(e.files))}
+	for name := range e.files {
+		m.Files = append(m.Files, name)
+	}
+	sort.Strings(m.Files)
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(e.opts.Dir, manifestName), data, 0o644)
+}
+
+// Helper to check whether a file of the output directory exists
+func (e *exporter) exists(name string) bool {
+	_, err := os.Stat(filepath.Join(e.opts.Dir, filepath.FromSlash(name)))
+	return err == nil
+}
+
+// Helper to map a URL path to a file: paths with an extension are kept, the others become
+// directories with an index.html so that they are served under the same pretty URL
+func filePath(p string) string {
+	p = strings.Trim(path.Clean(p), "/")
+	if p == "" || p == "." {
+		return "index.html"
+	}
+	if path.Ext(p) != "" {
+		return p
+	}
+	return p + "/index.html"
+}
+
+// Helper to check whether a path is inside a directory
+func inside(dir, name string) bool {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	absName, err := filepath.Abs(name)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(absDir, absName)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// Helper to remove directories left empty by deleted files, up to the output directory
+func removeEmptyDirs(root, dir string) {
+	root = filepath.Clean(root)
+	for dir != root && strings.HasPrefix(dir, root) {
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
@@ -0,0 +1,160 @@
+package export
+
+import (
+	"cms-project/internal/database/dbtest"
+	"cms-project/internal/workflow"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+func TestFilePath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/", "index.html"},
+		{"", "index.html"},
+		{"/blog/hello", "blog/hello/index.html"},
+		{"/blog/hello/", "blog/hello/index.html"},
+		{"/category/news/page/2", "category/news/page/2/index.html"},
+		{"/feeds/rss.xml", "feeds/rss.xml"},
+		{"/assets/css/site.css", "assets/css/site.css"},
+		{"/404.html", "404.html"},
+		{"/blog/../robots.txt", "robots.txt"},
+	}
+	for _, tt := range tests {
+		if got := filePath(tt.path); got != tt.want {
+			t.Errorf("filePath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestInside(t *testing.T) {
+	tests := []struct {
+		dir, name string
+		want      bool
+	}{
+		{"public", "public/site.zip", true},
+		{"public", "public/nested/site.zip", true},
+		{"public/", "./public/site.zip", true},
+		{"public", "site.zip", false},
+		{"public", "public.zip", false},
+		{"public", "../public/site.zip", false},
+		{"public", "..site.zip", false},
+	}
+	for _, tt := range tests {
+		if got := inside(tt.dir, tt.name); got != tt.want {
+			t.Errorf("inside(%q, %q) = %v, want %v", tt.dir, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRunRejectsZipInsideOutput(t *testing.T) {
+	dir := t.TempDir()
+	_, err := Run(Options{Dir: dir, Zip: filepath.Join(dir, "site.zip")})
+	if !errors.Is(err, ErrZipInsideOutput) {
+		t.Errorf("Run error = %v, want ErrZipInsideOutput", err)
+	}
+}
+
+func TestRemoveStale(t *testing.T) {
+	dir := t.TempDir()
+	previous := []string{"blog/kept/index.html", "blog/gone/index.html", "tag/old/page/2/index.html", "missing.xml"}
+	for _, name := range previous[:3] {
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(target, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	e := &exporter{
+		opts:     Options{Dir: dir},
+		previous: manifest{Files: previous},
+		files:    map[string]bool{"blog/kept/index.html": true},
+	}
+	if err := e.removeStale(); err != nil {
+		t.Fatalf("removeStale: %v", err)
+	}
+
+	if e.result.Removed != 3 {
+		t.Errorf("Removed = %d, want 3", e.result.Removed)
+	}
+	if !e.exists("blog/kept/index.html") {
+		t.Error("file written by this export was removed")
+	}
+	for _, name := range []string{"blog/gone", "tag"} {
+		if e.exists(name) {
+			t.Errorf("%s: directory left empty was not removed", name)
+		}
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("output directory was removed: %v", err)
+	}
+}
+
+func TestBlogsIncremental(t *testing.T) {
+	exportedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	before, after := exportedAt.Add(-time.Hour), exportedAt.Add(time.Hour)
+	tests := []struct {
+		name    string
+		full    bool
+		fetched []string
+	}{
+		{"incremental", false, []string{"/blog/missing", "/blog/updated"}},
+		{"full", true, []string{"/blog/missing", "/blog/unchanged", "/blog/updated"}},
+	}
+	for _, tt := range tests {
+		mock := dbtest.Mock(t)
+		mock.ExpectQuery(`FROM blogs b WHERE b.status = \$1 ORDER BY published_at DESC`).
+			WithArgs(workflow.Published).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "slug", "updated_at", "published_at"}).
+				AddRow(uuid.New(), "unchanged", before, before).
+				AddRow(uuid.New(), "updated", after, before).
+				AddRow(uuid.New(), "missing", before, before))
+
+		dir := t.TempDir()
+		unchanged := filepath.Join(dir, "blog", "unchanged", "index.html")
+		if err := os.MkdirAll(filepath.Dir(unchanged), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(unchanged, []byte("old"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		var fetched []string
+		e := &exporter{
+			opts:     Options{Dir: dir, Full: tt.full},
+			previous: manifest{ExportedAt: exportedAt},
+			files:    make(map[string]bool),
+			router: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fetched = append(fetched, r.URL.Path)
+				w.Write([]byte("new"))
+			}),
+		}
+		if err := e.blogs(); err != nil {
+			t.Fatalf("%s: blogs: %v", tt.name, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		sort.Strings(fetched)
+		if !reflect.DeepEqual(fetched, tt.fetched) {
+			t.Errorf("%s: rendered %v, want %v", tt.name, fetched, tt.fetched)
+		}
+		if len(e.files) != 3 {
+			t.Errorf("%s: recorded %d files, want all 3 so none is removed as stale", tt.name, len(e.files))
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testFeed() *Feed {
+	published := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	return &Feed{
+		Title:       "Example",
+		Description: "News & notes",
+		Link:        "https://example.com/",
+		SelfURL:     "https://example.com/feed",
+		Updated:     published.Add(time.Hour),
+		Items: []Item{{
+			ID:          "urn:uuid:550e8400-e29b-41d4-a716-446655440000",
+			Title:       "Hello <World>",
+			Link:        "https://example.com/blog/hello",
+			Summary:     "A short introduction.",
+			ContentHTML: "<p>Full &amp; text</p>",
+			Author:      "Ayşe",
+			Categories:  []string{"Technology", "go"},
+			Image:       "https://example.com/cover.jpg",
+			Enclosure:   &Enclosure{URL: "https://example.com/uploads/ab/cover.jpg", Type: "image/jpeg", Length: 12345},
+			Published:   published,
+			Updated:     published.Add(time.Hour),
+		}},
+	}
+}
+
+func TestWriteRSS(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatRSS, testFeed()); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	var got struct {
+		Channel struct {
+			Title string `xml:"title"`
+			Items []struct {
+				Title       string   `xml:"title"`
+				Link        string   `xml:"link"`
+				GUID        string   `xml:"guid"`
+				PubDate     string   `xml:"pubDate"`
+				Creator     string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+				Categories  []string `xml:"category"`
+				Description string   `xml:"description"`
+				Content     string   `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+				Enclosure   struct {
+					URL    string `xml:"url,attr"`
+					Length int64  `xml:"length,attr"`
+					Type   string `xml:"type,attr"`
+				} `xml:"enclosure"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Unmarshal: %v\n%s", err, buf.String())
+	}
+	if len(got.Channel.Items) != 1 {
+		t.Fatalf("items = %d, want 1", len(got.Channel.Items))
+	}
+	item := got.Channel.Items[0]
+	checks := []struct{ field, got, want string }{
+		{"title", item.Title, "Hello <World>"},
+		{"link", item.Link, "https://example.com/blog/hello"},
+		{"guid", item.GUID, "urn:uuid:550e8400-e29b-41d4-a716-446655440000"},
+		{"pubDate", item.PubDate, "Wed, 01 May 2024 09:00:00 +0000"},
+		{"creator", item.Creator, "Ayşe"},
+		{"description", item.Description, "A short introduction."},
+		{"content", item.Content, "<p>Full &amp; text</p>"},
+		{"enclosure url", item.Enclosure.URL, "https://example.com/uploads/ab/cover.jpg"},
+		{"enclosure type", item.Enclosure.Type, "image/jpeg"},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("rss %s = %q, want %q", c.field, c.got, c.want)
+		}
+	}
+	if item.Enclosure.Length != 12345 {
+		t.Errorf("rss enclosure length = %d, want 12345", item.Enclosure.Length)
+	}
+	if !reflect.DeepEqual(item.Categories, []string{"Technology", "go"}) {
+		t.Errorf("rss categories = %v, want [Technology go]", item.Categories)
+	}
+}
+
+func TestWriteAtom(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatAtom, testFeed()); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	var got atomFeed
+	if err := xml.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Unmarshal: %v\n%s", err, buf.String())
+	}
+	if got.Updated != "2024-05-01T10:00:00Z" {
+		t.Errorf("atom updated = %q, want 2024-05-01T10:00:00Z", got.Updated)
+	}
+	if len(got.Entries) != 1 {
+		t.Fatalf("entries = %d, want 1", len(got.Entries))
+	}
+	entry := got.Entries[0]
+	wantLinks := []atomLink{
+		{Href: "https://example.com/blog/hello", Rel: "alternate", Type: "text/html"},
+		{Href: "https://example.com/uploads/ab/cover.jpg", Rel: "enclosure", Type: "image/jpeg", Length: 12345},
+	}
+	if !reflect.DeepEqual(entry.Links, wantLinks) {
+		t.Errorf("atom links = %+v, want %+v", entry.Links, wantLinks)
+	}
+	if entry.Author == nil || entry.Author.Name != "Ayşe" {
+		t.Errorf("atom author = %+v, want Ayşe", entry.Author)
+	}
+	if entry.Content == nil || entry.Content.Type != "html" || entry.Content.Body != "<p>Full &amp; text</p>" {
+		t.Errorf("atom content = %+v, want the escaped HTML", entry.Content)
+	}
+	if entry.Summary == nil || entry.Summary.Body != "A short introduction." {
+		t.Errorf("atom summary = %+v, want the excerpt", entry.Summary)
+	}
+	if entry.Published != "2024-05-01T09:00:00Z" || entry.Updated != "2024-05-01T10:00:00Z" {
+		t.Errorf("atom published, updated = %q, %q", entry.Published, entry.Updated)
+	}
+	if len(entry.Categories) != 2 || entry.Categories[1].Term != "go" {
+		t.Errorf("atom categories = %+v, want Technology and go", entry.Categories)
+	}
+}
+
+func TestWriteJSONFeed(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatJSON, testFeed()); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	var got jsonFeed
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Unmarshal: %v\n%s", err, buf.String())
+	}
+	if len(got.Items) != 1 {
+		t.Fatalf("items = %d, want 1", len(got.Items))
+	}
+	want := jsonItem{
+		ID:            "urn:uuid:550e8400-e29b-41d4-a716-446655440000",
+		URL:           "https://example.com/blog/hello",
+		Title:         "Hello <World>",
+		ContentHTML:   "<p>Full &amp; text</p>",
+		Summary:       "A short introduction.",
+		Image:         "https://example.com/cover.jpg",
+		DatePublished: time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC),
+		DateModified:  time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		Authors:       []jsonAuthor{{Name: "Ayşe"}},
+		Tags:          []string{"Technology", "go"},
+		Attachments:   []jsonAttachment{{URL: "https://example.com/uploads/ab/cover.jpg", MimeType: "image/jpeg", Size: 12345}},
+	}
+	if !reflect.DeepEqual(got.Items[0], want) {
+		t.Errorf("json item = %+v, want %+v", got.Items[0], want)
+	}
+	if got.Version != "https://jsonfeed.org/version/1.1" || got.FeedURL != "https://example.com/feed" {
+		t.Errorf("json feed = %+v", got)
+	}
+}
+
+func TestWriteExcerptOnly(t *testing.T) {
+	feed := testFeed()
+	feed.Items[0].ContentHTML = ""
+	feed.Items[0].Enclosure = nil
+
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatJSON, feed); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	var got jsonFeed
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if item := got.Items[0]; item.ContentText != "A short introduction." || item.Attachments != nil {
+		t.Errorf("json item = %+v, want the excerpt as content_text and no attachments", item)
+	}
+}
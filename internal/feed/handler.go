@@ -0,0 +1,106 @@
+package feed
+
+import (
+	"bytes"
+	"cms-project/internal/site"
+	"cms-project/pkg/response"
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// GetFeedHandler handles serving the feed of all published blogs
+// @Summary Get the site feed
+// @Description Retrieve the most recently published blogs as RSS 2.0 (rss.xml), Atom (atom.xml) or JSON Feed (feed.json). FEED_CONTENT selects full content or excerpts. Supports conditional GET with ETag and Last-Modified.
+// @Tags Feed
+// @Produce xml
+// @Produce json
+// @Param format path string true "rss.xml, atom.xml or feed.json"
+// @Success 200 {string} string "The feed"
+// @Success 304 {string} string "Not modified"
+// @Failure 500 {object} response.APIResponse
+// @Router /feeds/{format} [get]
+func GetFeedHandler(w http.ResponseWriter, r *http.Request) {
+	serveFeed(w, r, SiteScope())
+}
+
+// GetCategoryFeedHandler handles serving the feed of a category
+// @Summary Get a category feed
+// @Description Retrieve the most recently published blogs of a category as RSS 2.0, Atom or JSON Feed
+// @Tags Feed
+// @Produce xml
+// @Produce json
+// @Param slug path string true "Category slug"
+// @Param format path string true "rss.xml, atom.xml or feed.json"
+// @Success 200 {string} string "The feed"
+// @Success 304 {string} string "Not modified"
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /feeds/categories/{slug}/{format} [get]
+func GetCategoryFeedHandler(w http.ResponseWriter, r *http.Request) {
+	scope, err := CategoryScope(mux.Vars(r)["slug"])
+	if err != nil {
+		response.JSON(w, http.StatusNotFound, false, "Category not found", nil)
+		return
+	}
+	serveFeed(w, r, scope)
+}
+
+// GetAuthorFeedHandler handles serving the feed of an author
+// @Summary Get an author feed
+// @Description Retrieve the most recently published blogs of an author as RSS 2.0, Atom or JSON Feed
+// @Tags Feed
+// @Produce xml
+// @Produce json
+// @Param id path string true "Author ID"
+// @Param format path string true "rss.xml, atom.xml or feed.json"
+// @Success 200 {string} string "The feed"
+// @Success 304 {string} string "Not modified"
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /feeds/authors/{id}/{format} [get]
+func GetAuthorFeedHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid author ID format", nil)
+		return
+	}
+	scope, err := AuthorScope(id)
+	if err != nil {
+		response.JSON(w, http.StatusNotFound, false, "Author not found", nil)
+		return
+	}
+	serveFeed(w, r, scope)
+}
+
+// serveFeed writes the feed of a scope in the format named by the route, unless the client has it
+func serveFeed(w http.ResponseWriter, r *http.Request, scope Scope) {
+	format := mux.Vars(r)["format"]
+	etag, updated, err := Version(scope, format)
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to build feed", nil)
+		return
+	}
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if response.NotModified(w, r, etag, updated) {
+		return
+	}
+
+	feed, err := Build(scope, site.URL(r.URL.Path), updated)
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to build feed", nil)
+		return
+	}
+	// Encode fully before writing so that a failure can still be reported
+	var buf bytes.Buffer
+	if err := Write(&buf, format, feed); err != nil {
+		log.Printf("Error encoding %s feed: %v", format, err)
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to build feed", nil)
+		return
+	}
+	w.Header().Set("Content-Type", contentTypes[format])
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,47 @@
+package feed
+
+import (
+	"cms-project/internal/blog"
+	"time"
+)
+
+// Feed is a format independent feed of published blogs
+type Feed struct {
+	Title       string
+	Description string
+	Link        string // the page the feed belongs to
+	SelfURL     string // where the feed itself is served
+	Updated     time.Time
+	Items       []Item
+}
+
+// Item is one blog in a feed
+type Item struct {
+	ID          string
+	Title       string
+	Link        string
+	Summary     string
+	ContentHTML string // empty unless FEED_CONTENT is full
+	Author      string
+	Categories  []string
+	Image       string
+	Enclosure   *Enclosure
+	Published   time.Time
+	Updated     time.Time
+}
+
+// Enclosure is a file attached to a feed item, such as the cover image
+type Enclosure struct {
+	URL    string
+	Type   string
+	Length int64
+}
+
+// Scope selects the blogs a feed contains and describes the feed
+type Scope struct {
+	Key         string // identifies the scope in validators, e.g. category:<id>
+	Title       string
+	Description string
+	Link        string
+	Filter      blog.PublishedFilter
+}
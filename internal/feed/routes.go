@@ -0,0 +1,15 @@
+package feed
+
+import (
+	"github.com/gorilla/mux"
+)
+
+// formatPattern matches the file names of the supported feed formats
+const formatPattern = `{format:rss\.xml|atom\.xml|feed\.json}`
+
+// RegisterFeedRoutes registers all feed routes
+func RegisterFeedRoutes(r *mux.Router) {
+	r.HandleFunc("/"+formatPattern, GetFeedHandler).Methods("GET", "HEAD")                                  // Feed of all published blogs
+	r.HandleFunc("/categories/{slug}/"+formatPattern, GetCategoryFeedHandler).Methods("GET", "HEAD")        // Feed of a category
+	r.HandleFunc("/authors/{id:[a-fA-F0-9-]+}/"+formatPattern, GetAuthorFeedHandler).Methods("GET", "HEAD") // Feed of an author
+}
@@ -0,0 +1,155 @@
+package feed
+
+import (
+	"cms-project/internal/blog"
+	"cms-project/internal/category"
+	"cms-project/internal/site"
+	"cms-project/internal/tag"
+	"cms-project/internal/user"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const defaultFeedSize = 20
+
+// Content modes, selected with FEED_CONTENT
+const (
+	ContentExcerpt = "excerpt"
+	ContentFull    = "full"
+)
+
+// SiteScope returns the scope of the feed of all published blogs
+func SiteScope() Scope {
+	return Scope{Key: "site", Title: site.Name(), Description: site.Description(), Link: site.URL("/")}
+}
+
+// CategoryScope returns the scope of the feed of a category
+func CategoryScope(categorySlug string) (Scope, error) {
+	c, err := category.GetCategoryBySlug(categorySlug)
+	if err != nil {
+		return Scope{}, err
+	}
+	scope := Scope{
+		Key:    "category:" + c.ID.String(),
+		Title:  c.Name + " - " + site.Name(),
+		Link:   site.URL(site.CategoryPath(c.Slug)),
+		Filter: blog.PublishedFilter{CategoryID: &c.ID},
+	}
+	if c.Description != nil {
+		scope.Description = *c.Description
+	}
+	return scope, nil
+}
+
+// AuthorScope returns the scope of the feed of an author
+func AuthorScope(id uuid.UUID) (Scope, error) {
+	u, err := user.GetUserByID(id)
+	if err != nil {
+		return Scope{}, err
+	}
+	return Scope{
+		Key:         "author:" + u.ID.String(),
+		Title:       u.Name + " - " + site.Name(),
+		Description: "Posts by " + u.Name,
+		Link:        site.URL("/"),
+		Filter:      blog.PublishedFilter{AuthorID: &u.ID},
+	}, nil
+}
+
+// Version returns a validator for the feed of a scope and when its content last changed. Both
+// are cheap to compute, so unchanged feeds can be answered without building them.
+func Version(scope Scope, format string) (string, time.Time, error) {
+	count, updated, err := blog.GetPublishedState(scope.Filter)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%s|%s|%d|%d|%s|%d",
+		format, scope.Key, scope.Title, scope.Description, count, updated.UnixNano(), contentMode(), feedSize())))
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`, updated, nil
+}
+
+// Build assembles the feed of a scope from its most recently published blogs
+func Build(scope Scope, selfURL string, updated time.Time) (*Feed, error) {
+	published, err := blog.GetPublishedBlogs(scope.Filter, 1, feedSize())
+	if err != nil {
+		return nil, err
+	}
+	blogs := make([]blog.Blog, len(published))
+	ids := make([]uuid.UUID, len(published))
+	for i, b := range published {
+		blogs[i] = b.Blog
+		ids[i] = b.ID
+	}
+	authors, err := blog.GetAuthorsForBlogs(blogs)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := blog.GetCategoriesForBlogs(ids)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := tag.GetTagsForBlogs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &Feed{
+		Title:       scope.Title,
+		Description: scope.Description,
+		Link:        scope.Link,
+		SelfURL:     selfURL,
+		Updated:     updated,
+		Items:       make([]Item, 0, len(published)),
+	}
+	full := contentMode() == ContentFull
+	for _, b := range published {
+		item := Item{
+			ID:        "urn:uuid:" + b.ID.String(),
+			Title:     b.Title,
+			Link:      site.URL(site.BlogPath(b.Slug)),
+			Summary:   b.Excerpt,
+			Author:    authors[b.AuthorID].Name,
+			Published: b.PublishedAt,
+			Updated:   b.UpdatedAt,
+		}
+		if full {
+			item.ContentHTML = b.ContentHTML
+		}
+		for _, c := range categories[b.ID] {
+			item.Categories = append(item.Categories, c.Name)
+		}
+		for _, t := range tags[b.ID] {
+			item.Categories = append(item.Categories, t.Name)
+		}
+		if b.CoverImage != "" {
+			item.Image = site.URL(b.CoverImage)
+		}
+		if b.Cover != nil {
+			item.Enclosure = &Enclosure{URL: site.URL(b.Cover.URL), Type: b.Cover.MimeType, Length: b.Cover.Size}
+		}
+		feed.Items = append(feed.Items, item)
+	}
+	return feed, nil
+}
+
+// contentMode returns whether feeds carry the full content or only the excerpt, from FEED_CONTENT
+func contentMode() string {
+	if os.Getenv("FEED_CONTENT") == ContentFull {
+		return ContentFull
+	}
+	return ContentExcerpt
+}
+
+// feedSize returns the number of blogs in a feed from FEED_SIZE
+func feedSize() int {
+	if n, err := strconv.Atoi(os.Getenv("FEED_SIZE")); err == nil && n > 0 {
+		return n
+	}
+	return defaultFeedSize
+}
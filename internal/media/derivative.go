@@ -0,0 +1,237 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/sync/singleflight"
+)
+
+// Default derivative sizes, overridable with MEDIA_IMAGE_WIDTHS and MEDIA_IMAGE_SQUARE
+const (
+	defaultWidths     = "320,640,1280"
+	defaultSquareSize = 320
+	jpegQuality       = 82
+)
+
+// Variant is a resized copy of an image
+type Variant struct {
+	Name   string `json:"name" example:"w640"`
+	URL    string `json:"url" example:"/uploads/ab/ab12...-w640.jpg"`
+	Width  int    `json:"width" example:"640"`
+	Height int    `json:"height" example:"427"`
+	square bool
+}
+
+// variantKeyPattern matches storage keys of derivatives: <hash>-<variant>.<ext>
+var variantKeyPattern = regexp.MustCompile(`^[a-f0-9]{2}/([a-f0-9]{64})-((?:w\d+)|(?:sq\d+-f\d{4}))\.(?:jpg|png)$`)
+
+// generating deduplicates concurrent builds of the same derivative
+var generating singleflight.Group
+
+// resolve sets the URLs and derivatives of a media item from the storage backend
+func (m *Media) resolve() {
+	m.URL = store.URL(m.StorageKey)
+	m.Variants = variants(m)
+	if len(m.Variants) == 0 {
+		return
+	}
+	var srcset []string
+	for _, v := range m.Variants {
+		if !v.square {
+			srcset = append(srcset, fmt.Sprintf("%s %dw", v.URL, v.Width))
+		}
+	}
+	m.SrcSet = strings.Join(append(srcset, fmt.Sprintf("%s %dw", m.URL, m.Width)), ", ")
+}
+
+// variants lists the configured derivatives of an image. Images are never scaled up, so widths
+// at or above the original are left out.
+func variants(m *Media) []Variant {
+	if !resizable(m.MimeType) || m.Width == 0 || m.Height == 0 {
+		return nil
+	}
+	var list []Variant
+	for _, field := range strings.Split(envOr("MEDIA_IMAGE_WIDTHS", defaultWidths), ",") {
+		w, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || w <= 0 || w >= m.Width {
+			continue
+		}
+		name := "w" + strconv.Itoa(w)
+		h := int(math.Round(float64(m.Height) * float64(w) / float64(m.Width)))
+		list = append(list, Variant{Name: name, URL: store.URL(variantKey(m, name)), Width: w, Height: max(h, 1)})
+	}
+	size := defaultSquareSize
+	if n, err := strconv.Atoi(envOr("MEDIA_IMAGE_SQUARE", "")); err == nil {
+		size = n
+	}
+	if size > 0 {
+		size = min(size, m.Width, m.Height)
+		// The focal point is part of the name so that moving it yields a new URL
+		name := fmt.Sprintf("sq%d-f%02d%02d", size, percent(m.FocalX), percent(m.FocalY))
+		list = append(list, Variant{Name: name, URL: store.URL(variantKey(m, name)), Width: size, Height: size, square: true})
+	}
+	return list
+}
+
+// generateVariants builds all derivatives of an image that do not exist yet
+func generateVariants(ctx context.Context, m Media) {
+	for _, v := range variants(&m) {
+		if err := ensureVariant(ctx, &m, v); err != nil {
+			log.Printf("Error generating %s of media %s: %v", v.Name, m.ID, err)
+		}
+	}
+}
+
+// deleteVariants removes the stored derivatives of an image
+func deleteVariants(ctx context.Context, m *Media) {
+	for _, v := range variants(m) {
+		if err := store.Delete(ctx, variantKey(m, v.Name)); err != nil {
+			log.Printf("Error deleting %s of media %s: %v", v.Name, m.ID, err)
+		}
+	}
+}
+
+// replaceVariants removes the derivatives an update made obsolete and builds the new ones
+func replaceVariants(ctx context.Context, before, after Media) {
+	current := make(map[string]bool)
+	for _, v := range variants(&after) {
+		current[v.Name] = true
+	}
+	for _, v := range variants(&before) {
+		if !current[v.Name] {
+			if err := store.Delete(ctx, variantKey(&before, v.Name)); err != nil {
+				log.Printf("Error deleting %s of media %s: %v", v.Name, before.ID, err)
+			}
+		}
+	}
+	generateVariants(ctx, after)
+}
+
+// openVariant opens a derivative by storage key, building it first when it is one of the
+// configured derivatives of an existing image but was not built yet
+func openVariant(ctx context.Context, key string) (io.ReadCloser, error) {
+	match := variantKeyPattern.FindStringSubmatch(key)
+	if match == nil {
+		return nil, ErrObjectNotFound
+	}
+	m, err := getMediaByHash(match[1])
+	if err != nil {
+		return nil, ErrObjectNotFound
+	}
+	for _, v := range variants(m) {
+		if v.Name == match[2] && variantKey(m, v.Name) == key {
+			if err := ensureVariant(ctx, m, v); err != nil {
+				return nil, err
+			}
+			return store.Open(ctx, key)
+		}
+	}
+	return nil, ErrObjectNotFound
+}
+
+// ensureVariant builds and stores a derivative unless it exists
+func ensureVariant(ctx context.Context, m *Media, v Variant) error {
+	key := variantKey(m, v.Name)
+	_, err, _ := generating.Do(key, func() (interface{}, error) {
+		if f, err := store.Open(ctx, key); err == nil {
+			f.Close()
+			return nil, nil
+		}
+		src, err := store.Open(ctx, m.StorageKey)
+		if err != nil {
+			return nil, err
+		}
+		defer src.Close()
+		img, _, err := image.Decode(src)
+		if err != nil {
+			return nil, ErrCorruptImage
+		}
+
+		var dst image.Image
+		if v.square {
+			dst = scale(cropSquare(img, m.FocalX, m.FocalY), v.Width, v.Height)
+		} else {
+			dst = scale(img, v.Width, v.Height)
+		}
+		var buf bytes.Buffer
+		if variantExt(m.MimeType) == ".png" {
+			err = png.Encode(&buf, dst)
+		} else {
+			err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: jpegQuality})
+		}
+		if err != nil {
+			return nil, err
+		}
+		contentType := "image/jpeg"
+		if variantExt(m.MimeType) == ".png" {
+			contentType = "image/png"
+		}
+		return nil, store.Put(ctx, key, &buf, int64(buf.Len()), contentType)
+	})
+	return err
+}
+
+// variantKey returns the storage key of a derivative
+func variantKey(m *Media, name string) string {
+	return m.Hash[:2] + "/" + m.Hash + "-" + name + variantExt(m.MimeType)
+}
+
+// variantExt returns the extension derivatives are encoded with: formats that may be transparent
+// become PNG, the others JPEG since there is no pure Go WebP encoder
+func variantExt(mimeType string) string {
+	if mimeType == "image/png" || mimeType == "image/gif" {
+		return ".png"
+	}
+	return ".jpg"
+}
+
+// resizable reports whether derivatives can be built for a MIME type
+func resizable(mimeType string) bool {
+	switch mimeType {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+		return true
+	}
+	return false
+}
+
+// scale resizes an image to the given dimensions
+func scale(img image.Image, w, h int) image.Image {
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, img.Bounds(), draw.Src, nil)
+	return dst
+}
+
+// cropSquare cuts the largest square out of an image, centred on the focal point as far as the
+// image edges allow
+func cropSquare(img image.Image, focalX, focalY float64) image.Image {
+	b := img.Bounds()
+	side := min(b.Dx(), b.Dy())
+	x := clamp(int(focalX*float64(b.Dx()))-side/2, 0, b.Dx()-side)
+	y := clamp(int(focalY*float64(b.Dy()))-side/2, 0, b.Dy()-side)
+	rect := image.Rect(b.Min.X+x, b.Min.Y+y, b.Min.X+x+side, b.Min.Y+y+side)
+	dst := image.NewNRGBA(image.Rect(0, 0, side, side))
+	draw.Copy(dst, image.Point{}, img, rect, draw.Src, nil)
+	return dst
+}
+
+// Helper to keep a value within bounds
+func clamp(v, lo, hi int) int {
+	return max(lo, min(v, hi))
+}
+
+// Helper to turn a 0..1 coordinate into a whole percentage
+func percent(f float64) int {
+	return clamp(int(math.Round(f*100)), 0, 99)
+}
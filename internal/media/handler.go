@@ -0,0 +1,236 @@
+package media
+
+import (
+	"cms-project/internal/audit"
+	"cms-project/pkg/response"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// filesPrefix is the path under which stored files are served
+const filesPrefix = "/uploads"
+
+// UploadMediaHandler handles uploading a file
+// @Summary Upload media
+// @Description Upload a file as multipart/form-data. Files with the same content are stored once; uploading one again returns the existing media. EXIF and similar metadata is stripped from images and resized derivatives are generated.
+// @Tags Media
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Param file formData file true "File to upload"
+// @Param alt formData string false "Alternative text"
+// @Param caption formData string false "Caption"
+// @Success 201 {object} response.APIResponse
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 413 {object} response.APIResponse
+// @Failure 415 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /media [post]
+func UploadMediaHandler(w http.ResponseWriter, r *http.Request) {
+	limit := MaxUploadSize()
+	// Leave room for the multipart envelope and the other form fields
+	r.Body = http.MaxBytesReader(w, r.Body, limit+1<<20)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			response.JSON(w, http.StatusRequestEntityTooLarge, false, fmt.Sprintf("Files can be at most %d bytes", limit), nil)
+			return
+		}
+		response.JSON(w, http.StatusBadRequest, false, "A file is required in the file field", nil)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, limit+1))
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Failed to read uploaded file", nil)
+		return
+	}
+	if int64(len(data)) > limit {
+		response.JSON(w, http.StatusRequestEntityTooLarge, false, fmt.Sprintf("Files can be at most %d bytes", limit), nil)
+		return
+	}
+
+	alt, caption := r.FormValue("alt"), r.FormValue("caption")
+	meta := UpdateMediaRequest{Alt: &alt, Caption: &caption}
+	m, created, err := Upload(r.Context(), header.Filename, data, meta, audit.ActorFrom(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrUnsupportedType):
+			response.JSON(w, http.StatusUnsupportedMediaType, false, err.Error(), nil)
+		case errors.Is(err, ErrEmptyFile), errors.Is(err, ErrInvalidMetadata), errors.Is(err, ErrInvalidFocus),
+			errors.Is(err, ErrCorruptImage), errors.Is(err, ErrImageTooLarge):
+			response.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
+		default:
+			response.JSON(w, http.StatusInternalServerError, false, "Failed to upload media", nil)
+		}
+		return
+	}
+	if !created {
+		response.JSON(w, http.StatusOK, true, "File already uploaded", m)
+		return
+	}
+	response.JSON(w, http.StatusCreated, true, "Media uploaded successfully", m)
+}
+
+// GetMediaListHandler handles listing media
+// @Summary Get media
+// @Description Retrieve uploaded media with pagination, newest first
+// @Tags Media
+// @Security BearerAuth
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of items per page"
+// @Success 200 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /media [get]
+func GetMediaListHandler(w http.ResponseWriter, r *http.Request) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+	items, err := GetMediaList(page, limit)
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to fetch media", nil)
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "Media retrieved successfully", items)
+}
+
+// GetMediaHandler handles retrieving a single media item
+// @Summary Get a media item
+// @Description Retrieve a media item with its URL
+// @Tags Media
+// @Security BearerAuth
+// @Param id path string true "Media ID"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /media/{id} [get]
+func GetMediaHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid media ID format", nil)
+		return
+	}
+	m, err := GetMediaByID(id)
+	if err != nil {
+		response.JSON(w, http.StatusNotFound, false, "Media not found", nil)
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "Media retrieved successfully", m)
+}
+
+// UpdateMediaHandler handles changing media metadata
+// @Summary Update media metadata
+// @Description Change the alt text, caption and focal point of a media item. Fields left out are kept. The focal point decides where square crops are cut.
+// @Tags Media
+// @Security BearerAuth
+// @Param id path string true "Media ID"
+// @Param media body media.UpdateMediaRequest true "Metadata"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /media/{id} [put]
+func UpdateMediaHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid media ID format", nil)
+		return
+	}
+	var req UpdateMediaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid input", nil)
+		return
+	}
+	m, err := UpdateMedia(id, req, audit.ActorFrom(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidMetadata), errors.Is(err, ErrInvalidFocus):
+			response.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
+		case errors.Is(err, sql.ErrNoRows):
+			response.JSON(w, http.StatusNotFound, false, "Media not found", nil)
+		default:
+			response.JSON(w, http.StatusInternalServerError, false, "Failed to update media", nil)
+		}
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "Media updated successfully", m)
+}
+
+// DeleteMediaHandler handles deleting a media item
+// @Summary Delete media
+// @Description Delete a media item and its file. Blogs using it as cover image lose their cover.
+// @Tags Media
+// @Security BearerAuth
+// @Param id path string true "Media ID"
+// @Success 204 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /media/{id} [delete]
+func DeleteMediaHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid media ID format", nil)
+		return
+	}
+	if err := DeleteMedia(r.Context(), id, audit.ActorFrom(r)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.JSON(w, http.StatusNotFound, false, "Media not found", nil)
+			return
+		}
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to delete media", nil)
+		return
+	}
+	response.JSON(w, http.StatusNoContent, true, "Media deleted successfully", nil)
+}
+
+// ServeFileHandler serves stored files from any backend. Keys are content hashes, so responses
+// can be cached forever. Image derivatives that were not built yet are built on first request.
+func ServeFileHandler(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+	f, err := store.Open(r.Context(), key)
+	if errors.Is(err, ErrObjectNotFound) {
+		f, err = openVariant(r.Context(), key)
+	}
+	if err != nil {
+		if errors.Is(err, ErrObjectNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		log.Printf("Error opening stored media %s: %v", key, err)
+		http.Error(w, "Failed to read file", http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+
+	if contentType := mime.TypeByExtension(path.Ext(key)); contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	if _, err := io.Copy(w, f); err != nil {
+		log.Printf("Error serving stored media %s: %v", key, err)
+	}
+}
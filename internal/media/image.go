@@ -0,0 +1,237 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/jpeg"
+
+	// Decoders for the image formats accepted for upload
+	_ "image/gif"
+	_ "image/png"
+
+	_ "golang.org/x/image/webp"
+)
+
+// maxPixels guards against decompression bombs
+const maxPixels = 50_000_000
+
+var (
+	ErrCorruptImage  = errors.New("the image could not be decoded")
+	ErrImageTooLarge = errors.New("the image has too many pixels")
+)
+
+// imageSize returns the dimensions of an encoded image without decoding it
+func imageSize(data []byte) (int, int, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, ErrCorruptImage
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return 0, 0, ErrImageTooLarge
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
+// stripMetadata removes EXIF, XMP and similar metadata, which may contain GPS coordinates, from an
+// image. JPEGs with an EXIF orientation are re-encoded upright since the orientation tag is lost.
+func stripMetadata(mimeType string, data []byte) ([]byte, error) {
+	switch mimeType {
+	case "image/jpeg":
+		if o := jpegOrientation(data); o > 1 {
+			img, _, err := image.Decode(bytes.NewReader(data))
+			if err != nil {
+				return nil, ErrCorruptImage
+			}
+			var buf bytes.Buffer
+			if err := jpeg.Encode(&buf, orient(img, o), &jpeg.Options{Quality: 92}); err != nil {
+				return nil, err
+			}
+			return buf.Bytes(), nil
+		}
+		return stripJPEG(data)
+	case "image/png":
+		return stripPNG(data)
+	case "image/webp":
+		return stripWebP(data)
+	}
+	return data, nil
+}
+
+// stripJPEG drops APP1 (EXIF, XMP), APP13 (IPTC) and comment segments, keeping the image data
+func stripJPEG(data []byte) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, ErrCorruptImage
+	}
+	out := append(make([]byte, 0, len(data)), 0xFF, 0xD8)
+	for i := 2; i < len(data); {
+		if data[i] != 0xFF || i+1 >= len(data) {
+			return nil, ErrCorruptImage
+		}
+		marker := data[i+1]
+		if marker == 0xFF { // fill byte
+			i++
+			continue
+		}
+		if marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7) {
+			out = append(out, data[i:i+2]...)
+			i += 2
+			continue
+		}
+		if i+4 > len(data) {
+			return nil, ErrCorruptImage
+		}
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:i+4]))
+		if end < i+4 || end > len(data) {
+			return nil, ErrCorruptImage
+		}
+		if marker == 0xDA { // start of scan, the rest is image data
+			return append(out, data[i:]...), nil
+		}
+		if marker != 0xE1 && marker != 0xED && marker != 0xFE {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	return out, nil
+}
+
+// stripPNG drops the eXIf chunk and text chunks
+func stripPNG(data []byte) ([]byte, error) {
+	if len(data) < 8 {
+		return nil, ErrCorruptImage
+	}
+	out := append(make([]byte, 0, len(data)), data[:8]...)
+	for i := 8; i < len(data); {
+		if i+8 > len(data) {
+			return nil, ErrCorruptImage
+		}
+		end := i + 12 + int(binary.BigEndian.Uint32(data[i:i+4]))
+		if end > len(data) || end < i {
+			return nil, ErrCorruptImage
+		}
+		switch string(data[i+4 : i+8]) {
+		case "eXIf", "tEXt", "zTXt", "iTXt", "tIME":
+		default:
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	return out, nil
+}
+
+// stripWebP drops the EXIF and XMP chunks and clears their flags in the VP8X header
+func stripWebP(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, ErrCorruptImage
+	}
+	out := append(make([]byte, 0, len(data)), data[:12]...)
+	for i := 12; i < len(data); {
+		if i+8 > len(data) {
+			return nil, ErrCorruptImage
+		}
+		size := int(binary.LittleEndian.Uint32(data[i+4 : i+8]))
+		end := i + 8 + size + size%2
+		if end > len(data) || end < i {
+			return nil, ErrCorruptImage
+		}
+		switch fourCC := string(data[i : i+4]); fourCC {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			start := len(out)
+			out = append(out, data[i:end]...)
+			if size > 0 {
+				out[start+8] &^= 0x08 | 0x04
+			}
+		default:
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	binary.LittleEndian.PutUint32(out[4:8], uint32(len(out)-8))
+	return out, nil
+}
+
+// jpegOrientation returns the EXIF orientation of a JPEG, or 0 when there is none
+func jpegOrientation(data []byte) int {
+	for i := 2; i+4 <= len(data) && data[i] == 0xFF; {
+		marker := data[i+1]
+		// Segment lengths include their own two bytes, so anything below 2 is corrupt
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:i+4]))
+		if marker == 0xDA || end < i+4 || end > len(data) {
+			return 0
+		}
+		if marker == 0xE1 && bytes.HasPrefix(data[i+4:end], []byte("Exif\x00\x00")) {
+			return tiffOrientation(data[i+10 : end])
+		}
+		i = end
+	}
+	return 0
+}
+
+// tiffOrientation reads the orientation tag from the first IFD of TIFF encoded EXIF data
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd+2 > len(tiff) || ifd < 0 {
+		return 0
+	}
+	count := int(order.Uint16(tiff[ifd : ifd+2]))
+	for n := 0; n < count; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:entry+2]) == 0x0112 {
+			return int(order.Uint16(tiff[entry+8 : entry+10]))
+		}
+	}
+	return 0
+}
+
+// orient transforms an image so that it displays upright for the given EXIF orientation
+func orient(img image.Image, orientation int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if orientation >= 5 {
+		w, h = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = b.Dx()-1-x, y
+			case 3:
+				dx, dy = b.Dx()-1-x, b.Dy()-1-y
+			case 4:
+				dx, dy = x, b.Dy()-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = b.Dy()-1-y, x
+			case 7:
+				dx, dy = b.Dy()-1-y, b.Dx()-1-x
+			case 8:
+				dx, dy = y, b.Dx()-1-x
+			default:
+				dx, dy = x, y
+			}
+			dst.Set(dx, dy, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
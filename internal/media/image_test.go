@@ -0,0 +1,63 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"testing"
+)
+
+// Regression test: an APP1 segment whose length field is below 2 used to make jpegOrientation
+// slice past the segment and panic
+func TestJPEGOrientationTruncatedAPP1(t *testing.T) {
+	for _, length := range []byte{0, 1} {
+		data := []byte{0xFF, 0xD8, 0xFF, 0xE1, 0x00, length, 'E', 'x', 'i', 'f', 0x00, 0x00}
+		if got := jpegOrientation(data); got != 0 {
+			t.Errorf("length %d: orientation = %d, want 0", length, got)
+		}
+		if _, err := stripMetadata("image/jpeg", data); !errors.Is(err, ErrCorruptImage) {
+			t.Errorf("length %d: stripMetadata error = %v, want %v", length, err, ErrCorruptImage)
+		}
+	}
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	// EXIF with a big endian TIFF header and a single orientation entry of 6
+	exif := []byte{
+		'E', 'x', 'i', 'f', 0x00, 0x00,
+		'M', 'M', 0x00, 0x2A, 0x00, 0x00, 0x00, 0x08,
+		0x00, 0x01,
+		0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, 0x00, 0x06, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00,
+	}
+	length := len(exif) + 2
+	data := append([]byte{0xFF, 0xD8, 0xFF, 0xE1, byte(length >> 8), byte(length)}, exif...)
+	data = append(data, 0xFF, 0xDA)
+
+	if got := jpegOrientation(data); got != 6 {
+		t.Errorf("orientation = %d, want 6", got)
+	}
+}
+
+func TestStripJPEGKeepsImage(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, 4, 4)), nil); err != nil {
+		t.Fatal(err)
+	}
+	src := buf.Bytes()
+	// Insert an EXIF segment right after the SOI marker
+	exif := []byte{0xFF, 0xE1, 0x00, 0x08, 'E', 'x', 'i', 'f', 0x00, 0x00}
+	data := append(append(append([]byte{}, src[:2]...), exif...), src[2:]...)
+
+	out, err := stripMetadata("image/jpeg", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out, src) {
+		t.Error("stripped JPEG differs from the original without EXIF")
+	}
+	if _, _, err := image.Decode(bytes.NewReader(out)); err != nil {
+		t.Errorf("stripped JPEG does not decode: %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+)
+
+// MemoryStorage keeps media in memory. It is meant for tests and local experiments.
+type MemoryStorage struct {
+	mu      sync.RWMutex
+	objects map[string][]byte
+	baseURL string
+}
+
+// NewMemoryStorage creates an empty in-process storage
+func NewMemoryStorage(baseURL string) *MemoryStorage {
+	return &MemoryStorage{objects: make(map[string][]byte), baseURL: baseURL}
+}
+
+// Put implements Storage
+func (s *MemoryStorage) Put(_ context.Context, key string, r io.Reader, _ int64, _ string) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = data
+	return nil
+}
+
+// Open implements Storage
+func (s *MemoryStorage) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, ok := s.objects[key]
+	if !ok {
+		return nil, ErrObjectNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// Delete implements Storage
+func (s *MemoryStorage) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+// URL implements Storage
+func (s *MemoryStorage) URL(key string) string {
+	return joinURL(s.baseURL, key)
+}
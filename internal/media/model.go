@@ -0,0 +1,37 @@
+package media
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Media is an uploaded file stored in the configured storage backend
+type Media struct {
+	ID         uuid.UUID  `db:"id" json:"id"`
+	StorageKey string     `db:"storage_key" json:"-"`
+	Filename   string     `db:"filename" json:"filename" example:"sunset.jpg"` // original file name
+	MimeType   string     `db:"mime_type" json:"mime_type" example:"image/jpeg"`
+	Size       int64      `db:"size" json:"size" example:"204800"` // in bytes
+	Hash       string     `db:"hash" json:"hash"`                  // SHA-256 of the content, used for deduplication
+	Alt        string     `db:"alt" json:"alt" example:"Sunset over the Bosphorus"`
+	Caption    string     `db:"caption" json:"caption" example:"Istanbul, 2024"`
+	Width      int        `db:"width" json:"width,omitempty" example:"1920"` // images only
+	Height     int        `db:"height" json:"height,omitempty" example:"1280"`
+	FocalX     float64    `db:"focal_x" json:"focal_x" example:"0.5"` // focal point for square crops, 0..1 from the left
+	FocalY     float64    `db:"focal_y" json:"focal_y" example:"0.5"` // 0..1 from the top
+	UploadedBy *uuid.UUID `db:"uploaded_by" json:"uploaded_by,omitempty"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time  `db:"updated_at" json:"updated_at"`
+	URL        string     `db:"-" json:"url" example:"/uploads/ab/ab12....jpg"` // resolved from the storage backend
+	Variants   []Variant  `db:"-" json:"variants,omitempty"`
+	SrcSet     string     `db:"-" json:"srcset,omitempty" example:"/uploads/ab/ab12...-w320.jpg 320w, /uploads/ab/ab12....jpg 1920w"`
+}
+
+// UpdateMediaRequest represents the editable metadata of a media item
+type UpdateMediaRequest struct {
+	Alt     *string  `json:"alt,omitempty" example:"Sunset over the Bosphorus"` // unchanged when omitted
+	Caption *string  `json:"caption,omitempty" example:"Istanbul, 2024"`        // unchanged when omitted
+	FocalX  *float64 `json:"focal_x,omitempty" example:"0.5"`                   // unchanged when omitted
+	FocalY  *float64 `json:"focal_y,omitempty" example:"0.3"`
+}
@@ -0,0 +1,21 @@
+package media
+
+import (
+	"cms-project/internal/user"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterMediaRoutes registers all media routes
+func RegisterMediaRoutes(r *mux.Router) {
+	r.Handle("", user.Require(user.PermMediaRead, GetMediaListHandler)).Methods("GET")                        // List media
+	r.Handle("", user.Require(user.PermMediaUpload, UploadMediaHandler)).Methods("POST")                      // Upload a file
+	r.Handle("/{id:[a-fA-F0-9-]+}", user.Require(user.PermMediaRead, GetMediaHandler)).Methods("GET")         // Get media by ID
+	r.Handle("/{id:[a-fA-F0-9-]+}", user.Require(user.PermMediaUpload, UpdateMediaHandler)).Methods("PUT")    // Alt text, caption and focal point
+	r.Handle("/{id:[a-fA-F0-9-]+}", user.Require(user.PermMediaDelete, DeleteMediaHandler)).Methods("DELETE") // Delete media
+}
+
+// RegisterFileRoutes registers the route serving stored files, mounted at /uploads
+func RegisterFileRoutes(r *mux.Router) {
+	r.HandleFunc("/{key:[a-f0-9]{2}/[a-f0-9]{64}(?:-[a-z0-9-]+)?\\.[a-z0-9]+}", ServeFileHandler).Methods("GET", "HEAD")
+}
@@ -0,0 +1,319 @@
+package media
+
+import (
+	"bytes"
+	"cms-project/internal/audit"
+	"cms-project/internal/database"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	defaultMaxUploadSize = 10 << 20
+	maxAltLength         = 300
+	maxCaptionLength     = 1000
+)
+
+// allowedTypes maps the sniffed MIME types accepted for upload to their file extension.
+// SVG is left out on purpose since it can carry scripts.
+var allowedTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+	"video/mp4":       ".mp4",
+}
+
+var (
+	ErrEmptyFile       = errors.New("the uploaded file is empty")
+	ErrUnsupportedType = errors.New("unsupported file type, allowed are JPEG, PNG, GIF, WebP, PDF and MP4")
+	ErrInvalidMetadata = fmt.Errorf("alt can have at most %d and caption at most %d characters", maxAltLength, maxCaptionLength)
+	ErrInvalidFocus    = errors.New("focal_x and focal_y must be between 0 and 1")
+)
+
+// MaxUploadSize returns the upload limit in bytes from MEDIA_MAX_UPLOAD_BYTES
+func MaxUploadSize() int64 {
+	if n, err := strconv.ParseInt(envOr("MEDIA_MAX_UPLOAD_BYTES", ""), 10, 64); err == nil && n > 0 {
+		return n
+	}
+	return defaultMaxUploadSize
+}
+
+// Upload stores a file unless a file with the same content exists already, in which case the
+// existing media is returned and created is false. The type is sniffed from the content, the
+// client supplied type and extension are ignored. Metadata is stripped from images before hashing
+// and their derivatives are generated in the background. The upload is credited to the actor, who
+// is also recorded in the audit log together with the new media.
+func Upload(ctx context.Context, filename string, data []byte, meta UpdateMediaRequest, actor audit.Actor) (m *Media, created bool, err error) {
+	if len(data) == 0 {
+		return nil, false, ErrEmptyFile
+	}
+	if err := validateMetadata(meta); err != nil {
+		return nil, false, err
+	}
+	mimeType := http.DetectContentType(data)
+	if i := strings.IndexByte(mimeType, ';'); i >= 0 {
+		mimeType = mimeType[:i]
+	}
+	ext, ok := allowedTypes[mimeType]
+	if !ok {
+		return nil, false, ErrUnsupportedType
+	}
+	var width, height int
+	if resizable(mimeType) {
+		// Check the dimensions before anything decodes the image
+		if _, _, err := imageSize(data); err != nil {
+			return nil, false, err
+		}
+		if data, err = stripMetadata(mimeType, data); err != nil {
+			return nil, false, err
+		}
+		if width, height, err = imageSize(data); err != nil {
+			return nil, false, err
+		}
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	if existing, err := getMediaByHash(hash); err == nil {
+		return existing, false, nil
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, false, err
+	}
+
+	key := hash[:2] + "/" + hash + ext
+	if err := store.Put(ctx, key, bytes.NewReader(data), int64(len(data)), mimeType); err != nil {
+		log.Printf("Error storing media: %v", err)
+		return nil, false, err
+	}
+
+	m = &Media{
+		ID:         uuid.New(),
+		StorageKey: key,
+		Filename:   filepath.Base(filename),
+		MimeType:   mimeType,
+		Size:       int64(len(data)),
+		Hash:       hash,
+		Width:      width,
+		Height:     height,
+		FocalX:     0.5,
+		FocalY:     0.5,
+		UploadedBy: actor.UserID,
+	}
+	if meta.Alt != nil {
+		m.Alt = *meta.Alt
+	}
+	if meta.Caption != nil {
+		m.Caption = *meta.Caption
+	}
+	if meta.FocalX != nil {
+		m.FocalX = *meta.FocalX
+	}
+	if meta.FocalY != nil {
+		m.FocalY = *meta.FocalY
+	}
+	query := `
+		INSERT INTO media (id, storage_key, filename, mime_type, size, hash, alt, caption, width, height, focal_x, focal_y, uploaded_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (hash) DO NOTHING
+		RETURNING created_at, updated_at`
+	tx, err := database.DB.Beginx()
+	if err != nil {
+		log.Printf("Error starting media transaction: %v", err)
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(query, m.ID, m.StorageKey, m.Filename, m.MimeType, m.Size, m.Hash, m.Alt, m.Caption,
+		m.Width, m.Height, m.FocalX, m.FocalY, m.UploadedBy).Scan(&m.CreatedAt, &m.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		// The same file was uploaded concurrently
+		tx.Rollback()
+		existing, err := getMediaByHash(hash)
+		return existing, false, err
+	}
+	if err != nil {
+		log.Printf("Error creating media: %v", err)
+		return nil, false, err
+	}
+	m.resolve()
+	if err := audit.Write(tx, actor, audit.ActionCreate, "media", m.ID.String(), nil, m); err != nil {
+		return nil, false, err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing media: %v", err)
+		return nil, false, err
+	}
+	go generateVariants(context.Background(), *m)
+	return m, true, nil
+}
+
+// GetMediaList retrieves media, newest first
+func GetMediaList(page, limit int) ([]Media, error) {
+	items := []Media{}
+	offset := (page - 1) * limit
+	query := "SELECT * FROM media ORDER BY created_at DESC LIMIT $1 OFFSET $2"
+	if err := database.DB.Select(&items, query, limit, offset); err != nil {
+		log.Printf("Error fetching media: %v", err)
+		return nil, err
+	}
+	for i := range items {
+		items[i].resolve()
+	}
+	return items, nil
+}
+
+// GetMediaByID retrieves a single media item by ID
+func GetMediaByID(id uuid.UUID) (*Media, error) {
+	var m Media
+	query := "SELECT * FROM media WHERE id = $1"
+	if err := database.DB.Get(&m, query, id); err != nil {
+		log.Printf("Error fetching media by ID: %v", err)
+		return nil, err
+	}
+	m.resolve()
+	return &m, nil
+}
+
+// GetMediaByIDs retrieves several media items in a single query, keyed by ID
+func GetMediaByIDs(ids []uuid.UUID) (map[uuid.UUID]Media, error) {
+	result := make(map[uuid.UUID]Media)
+	if len(ids) == 0 {
+		return result, nil
+	}
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, id.String())
+	}
+	var items []Media
+	query := "SELECT * FROM media WHERE id = ANY($1)"
+	if err := database.DB.Select(&items, query, pq.Array(keys)); err != nil {
+		log.Printf("Error fetching media by IDs: %v", err)
+		return nil, err
+	}
+	for _, m := range items {
+		m.resolve()
+		result[m.ID] = m
+	}
+	return result, nil
+}
+
+// UpdateMedia changes the alt text, caption and focal point of a media item, keeping the fields
+// the request leaves out, and records the audit event in the same transaction. Moving the focal
+// point replaces the square crop.
+func UpdateMedia(id uuid.UUID, req UpdateMediaRequest, actor audit.Actor) (*Media, error) {
+	if err := validateMetadata(req); err != nil {
+		return nil, err
+	}
+	tx, err := database.DB.Beginx()
+	if err != nil {
+		log.Printf("Error starting media transaction: %v", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var before Media
+	if err := tx.Get(&before, "SELECT * FROM media WHERE id = $1 FOR UPDATE", id); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("Error fetching media by ID: %v", err)
+		}
+		return nil, err
+	}
+	before.resolve()
+	var m Media
+	query := `
+		UPDATE media SET alt = COALESCE($1, alt), caption = COALESCE($2, caption), focal_x = COALESCE($3, focal_x), focal_y = COALESCE($4, focal_y), updated_at = NOW()
+		WHERE id = $5 RETURNING *`
+	if err := tx.Get(&m, query, req.Alt, req.Caption, req.FocalX, req.FocalY, id); err != nil {
+		log.Printf("Error updating media: %v", err)
+		return nil, err
+	}
+	m.resolve()
+	if err := audit.Write(tx, actor, audit.ActionUpdate, "media", id.String(), before, m); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing media update: %v", err)
+		return nil, err
+	}
+	if m.FocalX != before.FocalX || m.FocalY != before.FocalY {
+		go replaceVariants(context.Background(), before, m)
+	}
+	return &m, nil
+}
+
+// DeleteMedia deletes a media item, its stored file and its derivatives, recording the audit event
+// in the same transaction as the row. Blogs using it as cover lose the cover.
+func DeleteMedia(ctx context.Context, id uuid.UUID, actor audit.Actor) error {
+	tx, err := database.DB.Beginx()
+	if err != nil {
+		log.Printf("Error starting media transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	var m Media
+	query := "DELETE FROM media WHERE id = $1 RETURNING *"
+	if err := tx.Get(&m, query, id); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("Error deleting media: %v", err)
+		}
+		return err
+	}
+	m.resolve()
+	if err := audit.Write(tx, actor, audit.ActionDelete, "media", id.String(), m, nil); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing media deletion: %v", err)
+		return err
+	}
+	// Keys are unique per content, so no other media shares the file
+	if err := store.Delete(ctx, m.StorageKey); err != nil {
+		log.Printf("Error deleting stored media %s: %v", m.StorageKey, err)
+	}
+	deleteVariants(ctx, &m)
+	return nil
+}
+
+// getMediaByHash retrieves the media item with the given content hash
+func getMediaByHash(hash string) (*Media, error) {
+	var m Media
+	query := "SELECT * FROM media WHERE hash = $1"
+	if err := database.DB.Get(&m, query, hash); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("Error fetching media by hash: %v", err)
+		}
+		return nil, err
+	}
+	m.resolve()
+	return &m, nil
+}
+
+// validateMetadata checks the lengths of alt text and caption and the focal point
+func validateMetadata(meta UpdateMediaRequest) error {
+	if meta.Alt != nil && utf8.RuneCountInString(*meta.Alt) > maxAltLength ||
+		meta.Caption != nil && utf8.RuneCountInString(*meta.Caption) > maxCaptionLength {
+		return ErrInvalidMetadata
+	}
+	for _, f := range []*float64{meta.FocalX, meta.FocalY} {
+		if f != nil && (*f < 0 || *f > 1) {
+			return ErrInvalidFocus
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+// ErrObjectNotFound is returned by storage backends for missing keys
+var ErrObjectNotFound = errors.New("object not found")
+
+// Storage stores the content of media files
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	// URL returns the public URL of the object stored under key
+	URL(key string) string
+}
+
+// store is the storage backend used by the media service
+var store Storage
+
+// Init configures the storage backend from MEDIA_STORAGE (local, s3 or memory)
+func Init() {
+	var err error
+	switch backend := os.Getenv("MEDIA_STORAGE"); backend {
+	case "", "local":
+		store, err = NewLocalStorage(envOr("MEDIA_DIR", "uploads"), envOr("MEDIA_BASE_URL", filesPrefix))
+	case "s3":
+		store, err = NewS3Storage(S3Config{
+			Endpoint:  os.Getenv("MEDIA_S3_ENDPOINT"),
+			Region:    os.Getenv("MEDIA_S3_REGION"),
+			Bucket:    os.Getenv("MEDIA_S3_BUCKET"),
+			AccessKey: os.Getenv("MEDIA_S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("MEDIA_S3_SECRET_KEY"),
+			UseSSL:    os.Getenv("MEDIA_S3_USE_SSL") != "false",
+			BaseURL:   os.Getenv("MEDIA_BASE_URL"),
+		})
+	case "memory":
+		store = NewMemoryStorage(envOr("MEDIA_BASE_URL", filesPrefix))
+	default:
+		log.Fatalf("Unknown MEDIA_STORAGE %q, expected local, s3 or memory", backend)
+	}
+	if err != nil {
+		log.Fatalf("Failed to initialize media storage: %v", err)
+	}
+}
+
+// SetStorage replaces the storage backend, for tests and embedding applications
+func SetStorage(s Storage) {
+	store = s
+}
+
+// Helper to read a setting with a default
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// Helper to join a base URL and a key
+func joinURL(base, key string) string {
+	return strings.TrimSuffix(base, "/") + "/" + key
+}
@@ -1,8 +1,11 @@
 package menu
 
 import (
+	"cms-project/internal/audit"
 	"cms-project/pkg/response"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -14,6 +17,7 @@ import (
 // @Description Retrieve all menus, optionally filter by parent_id
 // @Tags Menu
 // @Param parent_id query int false "Parent menu ID"
+// @Param hide_unavailable query bool false "Hide items whose link target is unpublished or deleted"
 // @Success 200 {object} response.APIResponse
 // @Failure 500 {object} response.APIResponse
 // @Router /menus [get]
@@ -33,6 +37,9 @@ func GetMenusHandler(w http.ResponseWriter, r *http.Request) {
 
 		return
 	}
+	if hideUnavailable(r) {
+		menus = availableOnly(menus)
+	}
 	response.JSON(w, http.StatusOK, true, "Menus retrieved successfully", menus)
 }
 
@@ -40,6 +47,7 @@ func GetMenusHandler(w http.ResponseWriter, r *http.Request) {
 // @Summary Create a new menu
 // @Description Add a new menu to the database
 // @Tags Menu
+// @Security BearerAuth
 // @Param menu body menu.CreateMenuRequest true "Menu data"
 // @Success 201 {object} response.APIResponse
 // @Failure 400 {object} response.APIResponse
@@ -51,10 +59,14 @@ func CreateMenuHandler(w http.ResponseWriter, r *http.Request) {
 		response.JSON(w, http.StatusBadRequest, false, "Invalid input", nil)
 		return
 	}
+	if err := validateLink(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
+		return
+	}
 	menu := Menu{
 		CreateMenuRequest: req,
 	}
-	if err := CreateMenu(menu); err != nil {
+	if err := CreateMenu(&menu, audit.ActorFrom(r)); err != nil {
 		response.JSON(w, http.StatusInternalServerError, false, "Failed to create menu", nil)
 		return
 	}
@@ -90,6 +102,7 @@ func GetMenuByIDHandler(w http.ResponseWriter, r *http.Request) {
 // @Summary Delete a menu
 // @Description Remove a menu from the database
 // @Tags Menu
+// @Security BearerAuth
 // @Param id path int true "Menu ID"
 // @Success 204 {object} response.APIResponse
 // @Failure 400 {object} response.APIResponse
@@ -102,7 +115,12 @@ func DeleteMenuHandler(w http.ResponseWriter, r *http.Request) {
 		response.JSON(w, http.StatusBadRequest, false, "Invalid menu ID", nil)
 		return
 	}
-	if err := DeleteMenu(id); err != nil {
+	existing, err := GetMenuByID(id)
+	if err != nil {
+		response.JSON(w, http.StatusNotFound, false, "Menu not found", nil)
+		return
+	}
+	if err := DeleteMenu(existing, audit.ActorFrom(r)); err != nil {
 		response.JSON(w, http.StatusInternalServerError, false, "Failed to delete menu", nil)
 		return
 	}
@@ -112,6 +130,7 @@ func DeleteMenuHandler(w http.ResponseWriter, r *http.Request) {
 // @Summary Update a menu
 // @Description Update a menu's name or parent_id using its ID
 // @Tags Menu
+// @Security BearerAuth
 // @Param id path int true "Menu ID"
 // @Param menu body menu.CreateMenuRequest true "Menu data to update"
 // @Success 200 {object} response.APIResponse
@@ -132,13 +151,23 @@ func UpdateMenuHandler(w http.ResponseWriter, r *http.Request) {
 		response.JSON(w, http.StatusBadRequest, false, "Invalid input", nil)
 		return
 	}
+	if err := validateLink(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
+		return
+	}
+
+	existing, err := GetMenuByID(id)
+	if err != nil {
+		response.JSON(w, http.StatusNotFound, false, "Menu not found", nil)
+		return
+	}
 
 	menu := Menu{
 		ID:                id,
 		CreateMenuRequest: req,
 	}
 
-	if err := UpdateMenu(menu); err != nil {
+	if err := UpdateMenu(&menu, existing, audit.ActorFrom(r)); err != nil {
 		response.JSON(w, http.StatusInternalServerError, false, "Failed to update menu", nil)
 		return
 	}
@@ -150,6 +179,7 @@ func UpdateMenuHandler(w http.ResponseWriter, r *http.Request) {
 // @Description Retrieve menus filtered by parent_id
 // @Tags Menu
 // @Param parent_id query int false "Parent menu ID"
+// @Param hide_unavailable query bool false "Hide items whose link target is unpublished or deleted"
 // @Success 200 {object} response.APIResponse
 // @Failure 400 {object} response.APIResponse
 // @Failure 500 {object} response.APIResponse
@@ -173,7 +203,154 @@ func FilterMenusHandler(w http.ResponseWriter, r *http.Request) {
 		response.JSON(w, http.StatusInternalServerError, false, "Failed to filter menus", nil)
 		return
 	}
+	if hideUnavailable(r) {
+		menus = availableOnly(menus)
+	}
 
 	// Success response
 	response.JSON(w, http.StatusOK, true, "Menus retrieved successfully", menus)
 }
+
+// Helper to check whether unresolvable menu items should be left out
+func hideUnavailable(r *http.Request) bool {
+	hide, _ := strconv.ParseBool(r.URL.Query().Get("hide_unavailable"))
+	return hide
+}
+
+// GetLocationsHandler handles retrieving all menu locations
+// @Summary Get all menu locations
+// @Description Retrieve the named menu containers such as header, footer and sidebar
+// @Tags Menu
+// @Success 200 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /menus/locations [get]
+func GetLocationsHandler(w http.ResponseWriter, r *http.Request) {
+	locations, err := GetLocations()
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to fetch menu locations", nil)
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "Menu locations retrieved successfully", locations)
+}
+
+// CreateLocationHandler handles creating a new menu location
+// @Summary Create a menu location
+// @Description Add a named menu container
+// @Tags Menu
+// @Security BearerAuth
+// @Param location body menu.CreateLocationRequest true "Location data"
+// @Success 201 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /menus/locations [post]
+func CreateLocationHandler(w http.ResponseWriter, r *http.Request) {
+	var req CreateLocationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid input", nil)
+		return
+	}
+	location := Location{CreateLocationRequest: req}
+	if err := CreateLocation(&location, audit.ActorFrom(r)); err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidLocationKey):
+			response.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
+		case errors.Is(err, ErrLocationKeyTaken):
+			response.JSON(w, http.StatusConflict, false, err.Error(), nil)
+		default:
+			response.JSON(w, http.StatusInternalServerError, false, "Failed to create menu location", nil)
+		}
+		return
+	}
+	response.JSON(w, http.StatusCreated, true, "Menu location created successfully", nil)
+}
+
+// GetLocationTreeHandler handles retrieving the menu tree of a location
+// @Summary Get a menu location tree
+// @Description Retrieve the nested menu items of a location
+// @Tags Menu
+// @Param key path string true "Location key"
+// @Param hide_unavailable query bool false "Hide items whose link target is unpublished or deleted"
+// @Success 200 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /menus/locations/{key} [get]
+func GetLocationTreeHandler(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+	tree, err := GetLocationTree(key, hideUnavailable(r))
+	if err != nil {
+		if errors.Is(err, ErrLocationNotFound) {
+			response.JSON(w, http.StatusNotFound, false, "Menu location not found", nil)
+			return
+		}
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to fetch menu location", nil)
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "Menu location retrieved successfully", tree)
+}
+
+// UpdateLocationStructureHandler handles replacing the tree of a location
+// @Summary Reorder a menu location
+// @Description Atomically rewrite parent IDs and positions of a location's items from a nested tree. Items left out of the tree are detached from the location.
+// @Tags Menu
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param key path string true "Location key"
+// @Param structure body []menu.StructureNode true "Nested menu tree"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /menus/locations/{key}/structure [put]
+func UpdateLocationStructureHandler(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+
+	var nodes []StructureNode
+	if err := json.NewDecoder(r.Body).Decode(&nodes); err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid input", nil)
+		return
+	}
+
+	changed, err := UpdateLocationStructure(key, nodes, audit.ActorFrom(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrLocationNotFound):
+			response.JSON(w, http.StatusNotFound, false, "Menu location not found", nil)
+		case errors.Is(err, ErrInvalidStructure):
+			response.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
+		default:
+			response.JSON(w, http.StatusInternalServerError, false, "Failed to update menu structure", nil)
+		}
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "Menu structure updated successfully", map[string]int{"updated": changed})
+}
+
+// GetMenuAncestorsHandler handles retrieving the ancestor chain of a menu
+// @Summary Get menu ancestors
+// @Description Retrieve the menus from the root down to the given menu, for breadcrumbs
+// @Tags Menu
+// @Param id path int true "Menu ID"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /menus/{id}/ancestors [get]
+func GetMenuAncestorsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid menu ID", nil)
+		return
+	}
+	menus, err := GetMenuAncestors(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.JSON(w, http.StatusNotFound, false, "Menu not found", nil)
+			return
+		}
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to fetch menu ancestors", nil)
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "Menu ancestors retrieved successfully", menus)
+}
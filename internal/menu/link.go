@@ -0,0 +1,134 @@
+package menu
+
+import (
+	"cms-project/internal/database"
+	"cms-project/internal/site"
+	"errors"
+	"log"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+var allowedTargets = map[string]bool{"": true, "_self": true, "_blank": true, "_parent": true, "_top": true}
+
+// validateLink checks that the link fields of a menu request are consistent with its link type
+func validateLink(req *CreateMenuRequest) error {
+	if !allowedTargets[req.Target] {
+		return errors.New("target must be one of _self, _blank, _parent or _top")
+	}
+
+	switch req.LinkType {
+	case LinkNone:
+		if req.BlogID != nil || req.CategoryID != nil || req.LinkURL != "" {
+			return errors.New("link_type is required when a link target is given")
+		}
+	case LinkBlog:
+		if req.BlogID == nil {
+			return errors.New("blog_id is required for blog links")
+		}
+		req.CategoryID, req.LinkURL = nil, ""
+	case LinkCategory:
+		if req.CategoryID == nil {
+			return errors.New("category_id is required for category links")
+		}
+		req.BlogID, req.LinkURL = nil, ""
+	case LinkPath:
+		if !strings.HasPrefix(req.LinkURL, "/") || strings.HasPrefix(req.LinkURL, "//") {
+			return errors.New("link_url must be an absolute path for path links")
+		}
+		req.BlogID, req.CategoryID = nil, nil
+	case LinkURL:
+		u, err := url.Parse(req.LinkURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("link_url must be an absolute http or https URL for url links")
+		}
+		req.BlogID, req.CategoryID = nil, nil
+	default:
+		return errors.New("link_type must be one of blog, category, path or url")
+	}
+	return nil
+}
+
+// resolveLinks fills in Href and Available for each menu using the current blog and category slugs.
+// Items pointing at a blog that was unpublished or deleted are marked unavailable.
+func resolveLinks(menus []Menu) error {
+	var blogIDs, categoryIDs []string
+	for _, m := range menus {
+		if m.LinkType == LinkBlog && m.BlogID != nil {
+			blogIDs = append(blogIDs, m.BlogID.String())
+		}
+		if m.LinkType == LinkCategory && m.CategoryID != nil {
+			categoryIDs = append(categoryIDs, m.CategoryID.String())
+		}
+	}
+
+	blogSlugs := map[uuid.UUID]string{}
+	if len(blogIDs) > 0 {
+		var rows []struct {
+			ID   uuid.UUID `db:"id"`
+			Slug string    `db:"slug"`
+		}
+		query := "SELECT id, slug FROM blogs WHERE id = ANY($1) AND status = 'published' AND slug <> ''"
+		if err := database.DB.Select(&rows, query, pq.Array(blogIDs)); err != nil {
+			log.Printf("Error resolving menu blog links: %v", err)
+			return err
+		}
+		for _, row := range rows {
+			blogSlugs[row.ID] = row.Slug
+		}
+	}
+
+	categorySlugs := map[uuid.UUID]string{}
+	if len(categoryIDs) > 0 {
+		var rows []struct {
+			ID   uuid.UUID `db:"id"`
+			Slug string    `db:"slug"`
+		}
+		query := "SELECT id, slug FROM categories WHERE id = ANY($1) AND slug <> ''"
+		if err := database.DB.Select(&rows, query, pq.Array(categoryIDs)); err != nil {
+			log.Printf("Error resolving menu category links: %v", err)
+			return err
+		}
+		for _, row := range rows {
+			categorySlugs[row.ID] = row.Slug
+		}
+	}
+
+	for i := range menus {
+		m := &menus[i]
+		m.Href, m.Available = "", true
+		switch m.LinkType {
+		case LinkBlog:
+			m.Available = false
+			if m.BlogID != nil {
+				if slug, ok := blogSlugs[*m.BlogID]; ok {
+					m.Href, m.Available = site.BlogPath(slug), true
+				}
+			}
+		case LinkCategory:
+			m.Available = false
+			if m.CategoryID != nil {
+				if slug, ok := categorySlugs[*m.CategoryID]; ok {
+					m.Href, m.Available = site.CategoryPath(slug), true
+				}
+			}
+		case LinkPath, LinkURL:
+			m.Href = m.LinkURL
+		}
+	}
+	return nil
+}
+
+// availableOnly drops menu items whose link target could not be resolved
+func availableOnly(menus []Menu) []Menu {
+	filtered := make([]Menu, 0, len(menus))
+	for _, m := range menus {
+		if m.Available {
+			filtered = append(filtered, m)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,297 @@
+package menu
+
+import (
+	"cms-project/internal/audit"
+	"cms-project/internal/database"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrLocationNotFound is returned when no location exists for a key
+	ErrLocationNotFound = errors.New("menu location not found")
+	// ErrInvalidStructure is returned when a submitted tree cannot be applied
+	ErrInvalidStructure = errors.New("invalid menu structure")
+	// ErrInvalidLocationKey is returned when a location key is not URL friendly
+	ErrInvalidLocationKey = errors.New("location key may only contain lowercase letters, digits, '-' and '_'")
+	// ErrLocationKeyTaken is returned when another location already uses the key
+	ErrLocationKeyTaken = errors.New("key is already used by another menu location")
+)
+
+var locationKeyPattern = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// placement is the position of a menu item within its location
+type placement struct {
+	ID         int  `db:"id"`
+	ParentID   *int `db:"parent_id"`
+	Position   int  `db:"position"`
+	LocationID *int `db:"location_id"`
+}
+
+// GetLocations retrieves all menu locations
+func GetLocations() ([]Location, error) {
+	var locations []Location
+	query := "SELECT * FROM menu_locations ORDER BY key"
+	err := database.DB.Select(&locations, query)
+	if err != nil {
+		log.Printf("Error fetching menu locations: %v", err)
+		return nil, err
+	}
+	return locations, nil
+}
+
+// CreateLocation inserts a new menu location, filling in its ID and creation time, and records
+// menu.created and the audit event in the same transaction
+func CreateLocation(location *Location, actor audit.Actor) error {
+	if !locationKeyPattern.MatchString(location.Key) {
+		return ErrInvalidLocationKey
+	}
+	tx, err := database.DB.Beginx()
+	if err != nil {
+		log.Printf("Error starting menu location transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "INSERT INTO menu_locations (key, name) VALUES ($1, $2) RETURNING id, created_at"
+	err = tx.QueryRow(query, location.Key, location.Name).Scan(&location.ID, &location.CreatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrLocationKeyTaken
+		}
+		log.Printf("Error creating menu location: %v", err)
+		return err
+	}
+	if err := publishMenuEvent(tx, "menu.created", "menu_location", location.Key, location); err != nil {
+		return err
+	}
+	if err := audit.Write(tx, actor, audit.ActionCreate, "menu_location", location.Key, nil, location); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing menu location: %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetLocationTree retrieves the items of a location as a nested tree.
+// When hideUnavailable is set, items with unresolvable links are left out together with their children.
+func GetLocationTree(key string, hideUnavailable bool) ([]MenuTree, error) {
+	var menus []Menu
+	query := `
+		SELECT m.* FROM menus m
+		JOIN menu_locations l ON l.id = m.location_id
+		WHERE l.key = $1
+		ORDER BY m.position, m.id`
+	if err := database.DB.Select(&menus, query, key); err != nil {
+		log.Printf("Error fetching menu location tree: %v", err)
+		return nil, err
+	}
+	if len(menus) == 0 {
+		if _, err := getLocationByKey(key); err != nil {
+			return nil, err
+		}
+	}
+	if err := resolveLinks(menus); err != nil {
+		return nil, err
+	}
+	tree := buildTree(menus)
+	if hideUnavailable {
+		tree = pruneUnavailable(tree)
+	}
+	return tree, nil
+}
+
+// UpdateLocationStructure rewrites parent IDs and positions of a location's items in one transaction,
+// together with the menu.updated and audit events. Items missing from the tree are detached from
+// the location; rows whose placement did not change are left untouched. It returns the number of
+// rows updated.
+func UpdateLocationStructure(key string, nodes []StructureNode, actor audit.Actor) (int, error) {
+	desired := map[int]placement{}
+	if err := flattenStructure(nodes, nil, desired); err != nil {
+		return 0, err
+	}
+
+	tx, err := database.DB.Beginx()
+	if err != nil {
+		log.Printf("Error starting menu structure transaction: %v", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var location Location
+	if err := tx.Get(&location, "SELECT * FROM menu_locations WHERE key = $1 FOR UPDATE", key); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrLocationNotFound
+		}
+		log.Printf("Error fetching menu location: %v", err)
+		return 0, err
+	}
+
+	ids := make([]int64, 0, len(desired))
+	for id := range desired {
+		ids = append(ids, int64(id))
+	}
+	var current []placement
+	query := `
+		SELECT id, parent_id, position, location_id FROM menus
+		WHERE location_id = $1 OR id = ANY($2)
+		ORDER BY id
+		FOR UPDATE`
+	if err := tx.Select(&current, query, location.ID, pq.Array(ids)); err != nil {
+		log.Printf("Error locking menu items: %v", err)
+		return 0, err
+	}
+
+	var before []Menu
+	if err := tx.Select(&before, "SELECT * FROM menus WHERE location_id = $1 ORDER BY position, id", location.ID); err != nil {
+		log.Printf("Error fetching menu location items: %v", err)
+		return 0, err
+	}
+
+	found := map[int]bool{}
+	for _, p := range current {
+		found[p.ID] = true
+		if p.LocationID != nil && *p.LocationID != location.ID {
+			if _, ok := desired[p.ID]; ok {
+				return 0, fmt.Errorf("%w: menu %d belongs to another location", ErrInvalidStructure, p.ID)
+			}
+		}
+	}
+	for id := range desired {
+		if !found[id] {
+			return 0, fmt.Errorf("%w: menu %d does not exist", ErrInvalidStructure, id)
+		}
+	}
+
+	changed := 0
+	for _, p := range current {
+		next, ok := desired[p.ID]
+		if !ok {
+			// Dropped from the tree: detach it from the location
+			next = placement{ID: p.ID}
+		} else {
+			next.LocationID = &location.ID
+		}
+		if samePlacement(p, next) {
+			continue
+		}
+		query := "UPDATE menus SET parent_id = $1, position = $2, location_id = $3 WHERE id = $4"
+		if _, err := tx.Exec(query, next.ParentID, next.Position, next.LocationID, p.ID); err != nil {
+			log.Printf("Error updating menu placement: %v", err)
+			return 0, err
+		}
+		changed++
+	}
+
+	if changed > 0 {
+		if err := publishMenuEvent(tx, "menu.updated", "menu_location", key, nil); err != nil {
+			return 0, err
+		}
+		err := audit.Write(tx, actor, audit.ActionUpdate, "menu_location", key,
+			map[string][]StructureNode{"structure": structureOf(buildTree(before))},
+			map[string][]StructureNode{"structure": nodes})
+		if err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing menu structure: %v", err)
+		return 0, err
+	}
+	return changed, nil
+}
+
+func getLocationByKey(key string) (*Location, error) {
+	var location Location
+	err := database.DB.Get(&location, "SELECT * FROM menu_locations WHERE key = $1", key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrLocationNotFound
+	}
+	if err != nil {
+		log.Printf("Error fetching menu location: %v", err)
+		return nil, err
+	}
+	return &location, nil
+}
+
+// flattenStructure records the desired parent and position of every node in the tree
+func flattenStructure(nodes []StructureNode, parentID *int, out map[int]placement) error {
+	for i, node := range nodes {
+		if _, dup := out[node.ID]; dup {
+			return fmt.Errorf("%w: menu %d appears more than once", ErrInvalidStructure, node.ID)
+		}
+		out[node.ID] = placement{ID: node.ID, ParentID: parentID, Position: i}
+		id := node.ID
+		if err := flattenStructure(node.Children, &id, out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func samePlacement(a, b placement) bool {
+	return equalIntPtr(a.ParentID, b.ParentID) && a.Position == b.Position && equalIntPtr(a.LocationID, b.LocationID)
+}
+
+func equalIntPtr(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// buildTree nests menu items under their parents, keeping the order of the input
+func buildTree(menus []Menu) []MenuTree {
+	children := map[int][]Menu{}
+	inSet := map[int]bool{}
+	for _, m := range menus {
+		inSet[m.ID] = true
+	}
+	var roots []Menu
+	for _, m := range menus {
+		if m.ParentID != nil && inSet[*m.ParentID] {
+			children[*m.ParentID] = append(children[*m.ParentID], m)
+		} else {
+			roots = append(roots, m)
+		}
+	}
+
+	var build func(items []Menu) []MenuTree
+	build = func(items []Menu) []MenuTree {
+		nodes := make([]MenuTree, 0, len(items))
+		for _, m := range items {
+			nodes = append(nodes, MenuTree{Menu: m, Children: build(children[m.ID])})
+		}
+		return nodes
+	}
+	return build(roots)
+}
+
+func pruneUnavailable(nodes []MenuTree) []MenuTree {
+	pruned := make([]MenuTree, 0, len(nodes))
+	for _, node := range nodes {
+		if !node.Available {
+			continue
+		}
+		node.Children = pruneUnavailable(node.Children)
+		pruned = append(pruned, node)
+	}
+	return pruned
+}
+
+// structureOf strips a menu tree down to the IDs that make up its shape
+func structureOf(tree []MenuTree) []StructureNode {
+	nodes := make([]StructureNode, 0, len(tree))
+	for _, node := range tree {
+		nodes = append(nodes, StructureNode{ID: node.ID, Children: structureOf(node.Children)})
+	}
+	return nodes
+}
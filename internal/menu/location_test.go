@@ -0,0 +1,97 @@
+package menu
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func intPtr(i int) *int { return &i }
+
+func TestFlattenStructure(t *testing.T) {
+	nodes := []StructureNode{
+		{ID: 1, Children: []StructureNode{
+			{ID: 3},
+			{ID: 4, Children: []StructureNode{{ID: 5}}},
+		}},
+		{ID: 2},
+	}
+	got := map[int]placement{}
+	if err := flattenStructure(nodes, nil, got); err != nil {
+		t.Fatalf("flattenStructure: %v", err)
+	}
+	want := map[int]placement{
+		1: {ID: 1, Position: 0},
+		2: {ID: 2, Position: 1},
+		3: {ID: 3, ParentID: intPtr(1), Position: 0},
+		4: {ID: 4, ParentID: intPtr(1), Position: 1},
+		5: {ID: 5, ParentID: intPtr(4), Position: 0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("flattenStructure = %+v, want %+v", got, want)
+	}
+}
+
+func TestFlattenStructureRejectsDuplicates(t *testing.T) {
+	tests := []struct {
+		name  string
+		nodes []StructureNode
+	}{
+		{"sibling", []StructureNode{{ID: 1}, {ID: 1}}},
+		{"own child", []StructureNode{{ID: 1, Children: []StructureNode{{ID: 1}}}}},
+		{"nested elsewhere", []StructureNode{{ID: 1, Children: []StructureNode{{ID: 2}}}, {ID: 2}}},
+	}
+	for _, tt := range tests {
+		err := flattenStructure(tt.nodes, nil, map[int]placement{})
+		if !errors.Is(err, ErrInvalidStructure) {
+			t.Errorf("%s: error = %v, want ErrInvalidStructure", tt.name, err)
+		}
+	}
+}
+
+func TestSamePlacement(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b placement
+		want bool
+	}{
+		{"both at root", placement{Position: 1}, placement{Position: 1}, true},
+		{"same parent and location", placement{ParentID: intPtr(2), Position: 0, LocationID: intPtr(1)}, placement{ParentID: intPtr(2), Position: 0, LocationID: intPtr(1)}, true},
+		{"moved", placement{Position: 0}, placement{Position: 1}, false},
+		{"reparented", placement{ParentID: intPtr(2)}, placement{ParentID: intPtr(3)}, false},
+		{"moved to root", placement{ParentID: intPtr(2)}, placement{}, false},
+		{"attached to location", placement{}, placement{LocationID: intPtr(1)}, false},
+		{"detached from location", placement{LocationID: intPtr(1)}, placement{}, false},
+	}
+	for _, tt := range tests {
+		if got := samePlacement(tt.a, tt.b); got != tt.want {
+			t.Errorf("%s: samePlacement = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBuildTree(t *testing.T) {
+	menu := func(id int, parentID *int) Menu {
+		return Menu{ID: id, CreateMenuRequest: CreateMenuRequest{ParentID: parentID}}
+	}
+	menus := []Menu{
+		menu(1, nil),
+		menu(2, intPtr(1)),
+		menu(3, nil),
+		menu(4, intPtr(2)),
+		menu(5, intPtr(1)),
+		menu(6, intPtr(99)), // parent outside the set becomes a root
+	}
+	got := structureOf(buildTree(menus))
+	want := []StructureNode{
+		{ID: 1, Children: []StructureNode{
+			{ID: 2, Children: []StructureNode{{ID: 4, Children: []StructureNode{}}}},
+			{ID: 5, Children: []StructureNode{}},
+		}},
+		{ID: 3, Children: []StructureNode{}},
+		{ID: 6, Children: []StructureNode{}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("buildTree = %+v, want %+v", got, want)
+	}
+}
@@ -1,11 +1,32 @@
 package menu
 
-import "time"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Link types a menu item can point to
+const (
+	LinkNone     = ""
+	LinkBlog     = "blog"
+	LinkCategory = "category"
+	LinkPath     = "path"
+	LinkURL      = "url"
+)
 
 // CreateMenuRequest represents the required fields for creating a menu
 type CreateMenuRequest struct {
-	Name     string `db:"name" json:"name" example:"Main Menu"`
-	ParentID *int   `db:"parent_id,omitempty" json:"parent_id,omitempty" example:"1"`
+	Name       string     `db:"name" json:"name" example:"Main Menu"`
+	ParentID   *int       `db:"parent_id,omitempty" json:"parent_id,omitempty" example:"1"`
+	LinkType   string     `db:"link_type" json:"link_type,omitempty" example:"blog"` // blog, category, path, url
+	BlogID     *uuid.UUID `db:"blog_id" json:"blog_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	CategoryID *uuid.UUID `db:"category_id" json:"category_id,omitempty"`
+	LinkURL    string     `db:"link_url" json:"link_url,omitempty" example:"https://example.com"` // internal path or external URL
+	Target     string     `db:"target" json:"target,omitempty" example:"_blank"`
+	Rel        string     `db:"rel" json:"rel,omitempty" example:"noopener noreferrer"`
+	LocationID *int       `db:"location_id" json:"location_id,omitempty" example:"1"`
+	Position   int        `db:"position" json:"position" example:"0"`
 }
 
 // Menu represents a menu item
@@ -13,4 +34,33 @@ type Menu struct {
 	ID                int              `db:"id" json:"id"`
 	CreateMenuRequest `json:",inline"` // Embed CreateMenuRequest
 	CreatedAt         time.Time        `db:"created_at" json:"created_at"`
+
+	// Resolved at read time from the link target
+	Href      string `db:"-" json:"href,omitempty"`
+	Available bool   `db:"-" json:"available"`
+}
+
+// CreateLocationRequest represents the required fields for creating a menu location
+type CreateLocationRequest struct {
+	Key  string `db:"key" json:"key" example:"header"`
+	Name string `db:"name" json:"name" example:"Header navigation"`
+}
+
+// Location is a named menu container such as header, footer or sidebar
+type Location struct {
+	ID                    int              `db:"id" json:"id"`
+	CreateLocationRequest `json:",inline"` // Embed CreateLocationRequest
+	CreatedAt             time.Time        `db:"created_at" json:"created_at"`
+}
+
+// MenuTree represents a menu item together with its nested children
+type MenuTree struct {
+	Menu
+	Children []MenuTree `json:"children"`
+}
+
+// StructureNode describes the place of a menu item in a submitted tree
+type StructureNode struct {
+	ID       int             `json:"id" example:"1"`
+	Children []StructureNode `json:"children,omitempty"`
 }
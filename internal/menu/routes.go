@@ -1,15 +1,22 @@
 package menu
 
 import (
+	"cms-project/internal/user"
+
 	"github.com/gorilla/mux"
 )
 
 // RegisterMenuRoutes registers all menu routes
 func RegisterMenuRoutes(r *mux.Router) {
 	r.HandleFunc("", GetMenusHandler).Methods("GET")
-	r.HandleFunc("", CreateMenuHandler).Methods("POST")
+	r.Handle("", user.Require(user.PermMenusWrite, CreateMenuHandler)).Methods("POST")
 	r.HandleFunc("/{id:[0-9]+}", GetMenuByIDHandler).Methods("GET")
-	r.HandleFunc("/{id:[0-9]+}", UpdateMenuHandler).Methods("PUT")
-	r.HandleFunc("/{id:[0-9]+}", DeleteMenuHandler).Methods("DELETE")
+	r.Handle("/{id:[0-9]+}", user.Require(user.PermMenusWrite, UpdateMenuHandler)).Methods("PUT")
+	r.Handle("/{id:[0-9]+}", user.Require(user.PermMenusWrite, DeleteMenuHandler)).Methods("DELETE")
+	r.HandleFunc("/{id:[0-9]+}/ancestors", GetMenuAncestorsHandler).Methods("GET")
 	r.HandleFunc("/filter", FilterMenusHandler).Methods("GET")
+	r.HandleFunc("/locations", GetLocationsHandler).Methods("GET")
+	r.Handle("/locations", user.Require(user.PermMenusWrite, CreateLocationHandler)).Methods("POST")
+	r.HandleFunc("/locations/{key:[a-z0-9_-]+}", GetLocationTreeHandler).Methods("GET")
+	r.Handle("/locations/{key:[a-z0-9_-]+}/structure", user.Require(user.PermMenusWrite, UpdateLocationStructureHandler)).Methods("PUT")
 }
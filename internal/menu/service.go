@@ -1,10 +1,20 @@
 package menu
 
 import (
+	"cms-project/internal/audit"
 	"cms-project/internal/database"
+	"cms-project/internal/events"
+	"cms-project/internal/outbox"
+	"database/sql"
 	"log"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
 )
 
+// maxAncestorDepth guards ancestor queries against parent_id cycles
+const maxAncestorDepth = 32
+
 // GetMenus retrieves all menus from the database
 func GetMenus(page, limit int) ([]Menu, error) {
 	var menus []Menu
@@ -15,17 +25,41 @@ func GetMenus(page, limit int) ([]Menu, error) {
 		log.Printf("Error fetching menus: %v", err)
 		return nil, err
 	}
+	if err := resolveLinks(menus); err != nil {
+		return nil, err
+	}
 	return menus, nil
 }
 
-// CreateMenu inserts a new menu into the database
-func CreateMenu(menu Menu) error {
-	query := "INSERT INTO menus (name, parent_id) VALUES ($1, $2)"
-	_, err := database.DB.Exec(query, menu.Name, menu.ParentID)
+// CreateMenu inserts a new menu into the database, filling in its ID and creation time. The
+// menu.created event and the audit event are written in the same transaction.
+func CreateMenu(menu *Menu, actor audit.Actor) error {
+	query := `
+		INSERT INTO menus (name, parent_id, link_type, blog_id, category_id, link_url, target, rel, location_id, position)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at`
+	tx, err := database.DB.Beginx()
+	if err != nil {
+		log.Printf("Error starting menu transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(query, menu.Name, menu.ParentID, menu.LinkType, menu.BlogID, menu.CategoryID, menu.LinkURL, menu.Target, menu.Rel, menu.LocationID, menu.Position).Scan(&menu.ID, &menu.CreatedAt)
 	if err != nil {
 		log.Printf("Error creating menu: %v", err)
 		return err
 	}
+	if err := publishMenuEvent(tx, "menu.created", "menu", strconv.Itoa(menu.ID), menu); err != nil {
+		return err
+	}
+	if err := audit.Write(tx, actor, audit.ActionCreate, "menu", strconv.Itoa(menu.ID), nil, menu); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing menu: %v", err)
+		return err
+	}
 	return nil
 }
 
@@ -38,31 +72,106 @@ func GetMenuByID(id int) (*Menu, error) {
 		log.Printf("Error fetching menu by ID: %v", err)
 		return nil, err
 	}
-	return &menu, nil
+	menus := []Menu{menu}
+	if err := resolveLinks(menus); err != nil {
+		return nil, err
+	}
+	return &menus[0], nil
 }
 
-// DeleteMenu removes a menu from the database
-func DeleteMenu(id int) error {
-	query := "DELETE FROM menus WHERE id = $1"
-	_, err := database.DB.Exec(query, id)
+// DeleteMenu removes a menu from the database, recording menu.deleted and the audit event in the
+// same transaction
+func DeleteMenu(menu *Menu, actor audit.Actor) error {
+	tx, err := database.DB.Beginx()
 	if err != nil {
+		log.Printf("Error starting menu transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	query := "DELETE FROM menus WHERE id = $1"
+	if _, err := tx.Exec(query, menu.ID); err != nil {
 		log.Printf("Error deleting menu: %v", err)
 		return err
 	}
+	if err := publishMenuEvent(tx, "menu.deleted", "menu", strconv.Itoa(menu.ID), nil); err != nil {
+		return err
+	}
+	if err := audit.Write(tx, actor, audit.ActionDelete, "menu", strconv.Itoa(menu.ID), menu, nil); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing menu deletion: %v", err)
+		return err
+	}
 	return nil
 }
 
-// UpdateMenu updates an existing menu
-func UpdateMenu(menu Menu) error {
-	query := "UPDATE menus SET name = $1, parent_id ? $2 WHERE id = $3"
-	_, err := database.DB.Exec(query, menu.Name, menu.ParentID, menu.ID)
+// UpdateMenu updates existing with the fields of menu, recording menu.updated and the audit event
+// in the same transaction. menu is filled in with the stored menu.
+func UpdateMenu(menu *Menu, existing *Menu, actor audit.Actor) error {
+	query := `
+		UPDATE menus SET name = $1, parent_id = $2, link_type = $3, blog_id = $4, category_id = $5,
+			link_url = $6, target = $7, rel = $8, location_id = $9, position = $10
+		WHERE id = $11
+		RETURNING *`
+	tx, err := database.DB.Beginx()
+	if err != nil {
+		log.Printf("Error starting menu transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.Get(menu, query, menu.Name, menu.ParentID, menu.LinkType, menu.BlogID, menu.CategoryID, menu.LinkURL, menu.Target, menu.Rel, menu.LocationID, menu.Position, menu.ID)
 	if err != nil {
 		log.Printf("Error updating menu: %v", err)
 		return err
 	}
+	menus := []Menu{*menu}
+	if err := resolveLinks(menus); err != nil {
+		return err
+	}
+	*menu = menus[0]
+	if err := publishMenuEvent(tx, "menu.updated", "menu", strconv.Itoa(menu.ID), menu); err != nil {
+		return err
+	}
+	if err := audit.Write(tx, actor, audit.ActionUpdate, "menu", strconv.Itoa(menu.ID), existing, menu); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing menu update: %v", err)
+		return err
+	}
 	return nil
 }
 
+// GetMenuAncestors retrieves the chain of menus from the root down to the given menu
+func GetMenuAncestors(id int) ([]Menu, error) {
+	var menus []Menu
+	query := `
+		WITH RECURSIVE ancestors AS (
+			SELECT id, parent_id, 0 AS depth FROM menus WHERE id = $1
+			UNION ALL
+			SELECT m.id, m.parent_id, a.depth + 1
+			FROM menus m JOIN ancestors a ON m.id = a.parent_id
+			WHERE a.depth < $2
+		)
+		SELECT m.* FROM ancestors a JOIN menus m ON m.id = a.id
+		ORDER BY a.depth DESC`
+	err := database.DB.Select(&menus, query, id, maxAncestorDepth)
+	if err != nil {
+		log.Printf("Error fetching menu ancestors: %v", err)
+		return nil, err
+	}
+	if len(menus) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	if err := resolveLinks(menus); err != nil {
+		return nil, err
+	}
+	return menus, nil
+}
+
 // FilterMenus filters menus by parent_id
 func FilterMenus(parentID *int) ([]Menu, error) {
 	var menus []Menu
@@ -73,5 +182,13 @@ func FilterMenus(parentID *int) ([]Menu, error) {
 		log.Printf("Error filtering menus: %v", err)
 		return nil, err
 	}
+	if err := resolveLinks(menus); err != nil {
+		return nil, err
+	}
 	return menus, nil
 }
+
+// publishMenuEvent writes an event about a menu or a menu location to the outbox as part of tx
+func publishMenuEvent(tx *sqlx.Tx, eventType, entityType, id string, data interface{}) error {
+	return outbox.Write(tx, events.Event{Type: eventType, EntityType: entityType, EntityID: id, Data: data})
+}
@@ -0,0 +1,43 @@
+package outbox
+
+import (
+	"cms-project/internal/events"
+	"context"
+	"encoding/json"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaSink writes events to a Kafka topic, keyed by entity so that the events of one entity keep
+// their order within a partition
+type KafkaSink struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaSink creates a sink writing to topic on the given brokers
+func NewKafkaSink(brokers []string, topic string) *KafkaSink {
+	return &KafkaSink{writer: &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+	}}
+}
+
+func (s *KafkaSink) Name() string { return "kafka" }
+
+// Send writes the event and waits until all in-sync replicas have it
+func (s *KafkaSink) Send(ctx context.Context, e events.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return s.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(e.EntityType + ":" + e.EntityID),
+		Value: data,
+		Headers: []kafka.Header{
+			{Key: "event-id", Value: []byte(e.ID)},
+			{Key: "event-type", Value: []byte(e.Type)},
+		},
+	})
+}
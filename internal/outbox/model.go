@@ -0,0 +1,21 @@
+package outbox
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx/types"
+)
+
+// Entry is an event waiting in the outbox or already dispatched
+type Entry struct {
+	ID            int64          `db:"id" json:"id"`
+	EventType     string         `db:"event_type" json:"event_type"`
+	EntityType    string         `db:"entity_type" json:"entity_type"`
+	EntityID      string         `db:"entity_id" json:"entity_id"`
+	Payload       types.JSONText `db:"payload" json:"payload" swaggertype:"object"`
+	Attempts      int            `db:"attempts" json:"attempts"`
+	LastError     string         `db:"last_error" json:"last_error"`
+	NextAttemptAt time.Time      `db:"next_attempt_at" json:"next_attempt_at"`
+	DispatchedAt  *time.Time     `db:"dispatched_at" json:"dispatched_at,omitempty"`
+	CreatedAt     time.Time      `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,42 @@
+package outbox
+
+import (
+	"cms-project/internal/events"
+	"context"
+	"encoding/json"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSSink publishes events to NATS on <prefix>.<event type>, for example cms.blog.published
+type NATSSink struct {
+	conn   *nats.Conn
+	prefix string
+}
+
+// NewNATSSink connects to the NATS server at url
+func NewNATSSink(url, prefix string) (*NATSSink, error) {
+	conn, err := nats.Connect(url, nats.Name("cms-outbox"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, err
+	}
+	return &NATSSink{conn: conn, prefix: prefix}, nil
+}
+
+func (s *NATSSink) Name() string { return "nats" }
+
+// Send publishes the event and waits for the server to acknowledge it, so that an event is only
+// marked dispatched once the server has it
+func (s *NATSSink) Send(ctx context.Context, e events.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	msg := nats.NewMsg(s.prefix + "." + e.Type)
+	msg.Header.Set(nats.MsgIdHdr, e.ID) // lets JetStream streams drop duplicates
+	msg.Data = data
+	if err := s.conn.PublishMsg(msg); err != nil {
+		return err
+	}
+	return s.conn.FlushWithContext(ctx)
+}
@@ -0,0 +1,47 @@
+package outbox
+
+import (
+	"cms-project/internal/events"
+	"encoding/json"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// channel is the Postgres notification channel that wakes up relays when entries are committed
+const channel = "outbox"
+
+// Write records an event in the outbox as part of tx. It is dispatched only once tx commits, so
+// rolled back changes never produce events.
+func Write(tx *sqlx.Tx, e events.Event) error {
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now()
+	}
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	query := "INSERT INTO outbox (event_type, entity_type, entity_id, payload) VALUES ($1, $2, $3, $4)"
+	if _, err := tx.Exec(query, e.Type, e.EntityType, e.EntityID, payload); err != nil {
+		log.Printf("Error writing outbox entry: %v", err)
+		return err
+	}
+	// Notifications are delivered on commit and dropped on rollback
+	if _, err := tx.Exec("SELECT pg_notify($1, '')", channel); err != nil {
+		log.Printf("Error notifying outbox relay: %v", err)
+		return err
+	}
+	return nil
+}
+
+// Event decodes the event stored in an entry, identified by the entry ID
+func (en *Entry) Event() (events.Event, error) {
+	var e events.Event
+	if err := json.Unmarshal(en.Payload, &e); err != nil {
+		return e, err
+	}
+	e.ID = strconv.FormatInt(en.ID, 10)
+	return e, nil
+}
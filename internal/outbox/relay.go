@@ -0,0 +1,260 @@
+package outbox
+
+import (
+	"cms-project/internal/database"
+	"cms-project/internal/events"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Relay settings, overridable with OUTBOX_POLL and OUTBOX_RETENTION
+const (
+	defaultPoll      = 5 * time.Second
+	defaultRetention = 7 * 24 * time.Hour
+	batchSize        = 20
+	sendTimeout      = 10 * time.Second
+	maxRetryDelay    = 10 * time.Minute
+)
+
+// wakeup tells the relay that entries were committed without waiting for the next poll
+var wakeup = make(chan struct{}, 1)
+
+// Init registers the sinks configured in the environment and starts relaying committed entries:
+// the in-process bus always, NATS when OUTBOX_NATS_URL is set and Kafka when OUTBOX_KAFKA_BROKERS
+// is. Other sinks, such as webhooks, are added with AddSink.
+func Init() {
+	AddSink(BusSink{})
+	if url := os.Getenv("OUTBOX_NATS_URL"); url != "" {
+		s, err := NewNATSSink(url, envOr("OUTBOX_NATS_SUBJECT", "cms"))
+		if err != nil {
+			log.Fatalf("Failed to connect to NATS: %v", err)
+		}
+		AddSink(s)
+	}
+	if brokers := os.Getenv("OUTBOX_KAFKA_BROKERS"); brokers != "" {
+		AddSink(NewKafkaSink(strings.Split(brokers, ","), envOr("OUTBOX_KAFKA_TOPIC", "cms-events")))
+	}
+
+	go listen()
+	go relay()
+	go purge()
+}
+
+// listen wakes up the relay whenever a transaction with outbox entries commits, on this or any
+// other instance. The relay also polls, so entries are dispatched while the listener reconnects.
+func listen() {
+	listener := pq.NewListener(os.Getenv("DATABASE_URL"), time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Outbox listener: %v", err)
+		}
+	})
+	if err := listener.Listen(channel); err != nil {
+		log.Printf("Error listening for outbox notifications: %v", err)
+		return
+	}
+	for range listener.Notify {
+		// A nil notification follows a reconnect, after which entries may have been missed
+		wake()
+	}
+}
+
+// relay dispatches due entries whenever it is woken up and every OUTBOX_POLL
+func relay() {
+	poll := defaultPoll
+	if d, err := time.ParseDuration(os.Getenv("OUTBOX_POLL")); err == nil && d > 0 {
+		poll = d
+	}
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+	for {
+		for {
+			entries, err := claim()
+			if err != nil || len(entries) == 0 {
+				break
+			}
+			// After a failure, wait rather than claim entries that would overtake the failed ones
+			if !dispatchAll(entries) {
+				break
+			}
+		}
+		select {
+		case <-wakeup:
+		case <-ticker.C:
+		}
+	}
+}
+
+// claimedEntry is a claimed entry together with the sinks that already accepted it
+type claimedEntry struct {
+	Entry
+	Done pq.StringArray `db:"done"`
+}
+
+// claim picks due entries in order and pushes their next attempt past the time sending them may
+// take, so that relays of other instances skip them meanwhile
+func claim() ([]claimedEntry, error) {
+	var entries []claimedEntry
+	query := `
+		WITH claimed AS (
+			UPDATE outbox SET next_attempt_at = NOW() + $1 * INTERVAL '1 millisecond'
+			WHERE id IN (
+				SELECT id FROM outbox
+				WHERE dispatched_at IS NULL AND next_attempt_at <= NOW()
+				ORDER BY id
+				LIMIT $2
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT c.*, ARRAY(SELECT d.sink FROM outbox_dispatches d WHERE d.entry_id = c.id) AS done
+		FROM claimed c
+		ORDER BY c.id`
+	lease := time.Duration(batchSize*max(len(currentSinks()), 1)) * sendTimeout
+	if err := database.DB.Select(&entries, query, lease.Milliseconds(), batchSize); err != nil {
+		log.Printf("Error claiming outbox entries: %v", err)
+		return nil, err
+	}
+	return entries, nil
+}
+
+// dispatchAll sends claimed entries in ID order, each to the sinks that have not accepted it yet,
+// and reports whether all of them were dispatched. A sink that fails is skipped for the rest of
+// the batch, so it does not receive later entries before the failed one while the other sinks
+// keep receiving them; the skipped entries are retried for it after the same delay. Order is not
+// guaranteed beyond that: entries claimed later may reach a sink that recovered before the retry.
+func dispatchAll(entries []claimedEntry) bool {
+	sinks := currentSinks()
+	down := map[string]time.Duration{} // sinks that failed in this batch and their retry delay
+	complete := true
+	for i := range entries {
+		en := &entries[i]
+		e, err := en.Event()
+		if err != nil {
+			markFailed(&en.Entry, err, backoff(en.Attempts+1))
+			complete = false
+			continue
+		}
+
+		var failed []string
+		var wait time.Duration
+		for _, s := range sinks {
+			name := s.Name()
+			if slices.Contains(en.Done, name) {
+				continue
+			}
+			if delay, ok := down[name]; ok {
+				wait = max(wait, delay)
+				continue
+			}
+			if err := send(s, e); err != nil {
+				failed = append(failed, name+": "+err.Error())
+				down[name] = backoff(en.Attempts + 1)
+				continue
+			}
+			markSent(en.ID, name)
+		}
+
+		switch {
+		case len(failed) > 0:
+			delay := max(backoff(en.Attempts+1), wait)
+			log.Printf("Error dispatching outbox entry %d (%s), retrying in %s: %s", en.ID, en.EventType, delay, strings.Join(failed, "; "))
+			markFailed(&en.Entry, errors.New(strings.Join(failed, "; ")), delay)
+			complete = false
+		case wait > 0:
+			release(&en.Entry, wait)
+			complete = false
+		default:
+			markDispatched(&en.Entry)
+		}
+	}
+	return complete
+}
+
+// send hands an event to a sink within the send timeout
+func send(s Sink, e events.Event) error {
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+	return s.Send(ctx, e)
+}
+
+// markSent records that a sink accepted an entry so that it is not sent there again
+func markSent(id int64, sink string) {
+	query := "INSERT INTO outbox_dispatches (entry_id, sink) VALUES ($1, $2) ON CONFLICT DO NOTHING"
+	if _, err := database.DB.Exec(query, id, sink); err != nil {
+		log.Printf("Error recording outbox dispatch to %s: %v", sink, err)
+	}
+}
+
+// markDispatched records that every sink accepted an entry
+func markDispatched(en *Entry) {
+	query := "UPDATE outbox SET dispatched_at = NOW(), attempts = attempts + 1, last_error = '' WHERE id = $1"
+	if _, err := database.DB.Exec(query, en.ID); err != nil {
+		log.Printf("Error marking outbox entry dispatched: %v", err)
+	}
+}
+
+// markFailed records a failed attempt and when to try again
+func markFailed(en *Entry, cause error, delay time.Duration) {
+	query := `
+		UPDATE outbox SET attempts = attempts + 1, last_error = $1, next_attempt_at = NOW() + $2 * INTERVAL '1 millisecond'
+		WHERE id = $3`
+	if _, err := database.DB.Exec(query, cause.Error(), delay.Milliseconds(), en.ID); err != nil {
+		log.Printf("Error recording outbox failure: %v", err)
+	}
+}
+
+// release gives up the claim on an entry that was not attempted for every sink, without counting
+// an attempt
+func release(en *Entry, delay time.Duration) {
+	query := "UPDATE outbox SET next_attempt_at = NOW() + $1 * INTERVAL '1 millisecond' WHERE id = $2"
+	if _, err := database.DB.Exec(query, delay.Milliseconds(), en.ID); err != nil {
+		log.Printf("Error releasing outbox entry: %v", err)
+	}
+}
+
+// purge deletes dispatched entries older than OUTBOX_RETENTION (default 7 days) once an hour
+func purge() {
+	retention := defaultRetention
+	if d, err := time.ParseDuration(os.Getenv("OUTBOX_RETENTION")); err == nil && d > 0 {
+		retention = d
+	}
+	for {
+		query := "DELETE FROM outbox WHERE dispatched_at < NOW() - $1 * INTERVAL '1 millisecond'"
+		if _, err := database.DB.Exec(query, retention.Milliseconds()); err != nil {
+			log.Printf("Error purging outbox: %v", err)
+		}
+		time.Sleep(time.Hour)
+	}
+}
+
+// backoff returns the delay before the next attempt, doubling from one second up to ten minutes
+func backoff(attempts int) time.Duration {
+	delay := time.Second
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
+
+// Helper to wake up the relay without blocking when it is already awake
+func wake() {
+	select {
+	case wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// Helper to read a setting with a default
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
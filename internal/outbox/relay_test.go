@@ -0,0 +1,96 @@
+package outbox
+
+import (
+	"cms-project/internal/database/dbtest"
+	"cms-project/internal/events"
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// recordingSink remembers the events it was sent and fails when err is set
+type recordingSink struct {
+	name string
+	err  error
+	sent []string
+}
+
+func (s *recordingSink) Name() string { return s.name }
+
+func (s *recordingSink) Send(ctx context.Context, e events.Event) error {
+	s.sent = append(s.sent, e.ID)
+	return s.err
+}
+
+// useSinks replaces the registered sinks for the duration of a test
+func useSinks(t *testing.T, registered ...Sink) {
+	previous := sinks
+	sinks = registered
+	t.Cleanup(func() { sinks = previous })
+}
+
+func entry(id int64, done ...string) claimedEntry {
+	return claimedEntry{
+		Entry: Entry{ID: id, EventType: "blog.updated", Payload: []byte(`{"type":"blog.updated","entity_type":"blog"}`)},
+		Done:  done,
+	}
+}
+
+func TestDispatchAllRetriesOnlyFailedSink(t *testing.T) {
+	healthy := &recordingSink{name: "healthy"}
+	failing := &recordingSink{name: "failing", err: errors.New("connection refused")}
+	useSinks(t, healthy, failing)
+
+	mock := dbtest.Mock(t)
+	sent := regexp.QuoteMeta("INSERT INTO outbox_dispatches (entry_id, sink)")
+	mock.ExpectExec(sent).WithArgs(1, "healthy").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE outbox SET attempts = attempts + 1, last_error = $1")).
+		WithArgs("failing: connection refused", backoff(1).Milliseconds(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(sent).WithArgs(2, "healthy").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE outbox SET next_attempt_at = NOW() + $1")).
+		WithArgs(backoff(1).Milliseconds(), 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if dispatchAll([]claimedEntry{entry(1), entry(2)}) {
+		t.Error("dispatchAll reported success although a sink failed")
+	}
+	if len(healthy.sent) != 2 {
+		t.Errorf("healthy sink received %v, want both entries", healthy.sent)
+	}
+	// The failing sink must not receive the second entry ahead of the first one
+	if len(failing.sent) != 1 || failing.sent[0] != "1" {
+		t.Errorf("failing sink received %v, want only entry 1", failing.sent)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDispatchAllSkipsSinksThatAcceptedEntry(t *testing.T) {
+	bus := &recordingSink{name: "bus"}
+	webhooks := &recordingSink{name: "webhooks"}
+	useSinks(t, bus, webhooks)
+
+	mock := dbtest.Mock(t)
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO outbox_dispatches (entry_id, sink)")).
+		WithArgs(3, "webhooks").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE outbox SET dispatched_at = NOW()")).
+		WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if !dispatchAll([]claimedEntry{entry(3, "bus")}) {
+		t.Error("dispatchAll reported a failure")
+	}
+	if len(bus.sent) != 0 {
+		t.Errorf("bus received %v again after accepting it", bus.sent)
+	}
+	if len(webhooks.sent) != 1 {
+		t.Errorf("webhooks received %v, want the entry once", webhooks.sent)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
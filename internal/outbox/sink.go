@@ -0,0 +1,48 @@
+package outbox
+
+import (
+	"cms-project/internal/events"
+	"context"
+	"sync"
+)
+
+// Sink receives the events dispatched from the outbox. Delivery is at least once: an event is sent
+// to a sink again when its acceptance could not be recorded, so sinks and their consumers should
+// skip event IDs they have already seen.
+type Sink interface {
+	// Name identifies the sink in logs and is the key its deliveries are recorded under in
+	// outbox_dispatches, so it must be stable across restarts: a renamed sink is sent again the
+	// entries it accepted under its old name that are still pending for other sinks.
+	Name() string
+	Send(ctx context.Context, e events.Event) error
+}
+
+var (
+	sinksMu sync.RWMutex
+	sinks   []Sink
+)
+
+// AddSink registers a sink for dispatched events
+func AddSink(s Sink) {
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+	sinks = append(sinks, s)
+}
+
+// Helper to copy the registered sinks
+func currentSinks() []Sink {
+	sinksMu.RLock()
+	defer sinksMu.RUnlock()
+	return append([]Sink(nil), sinks...)
+}
+
+// BusSink publishes events to in-process subscribers such as the sitemap
+type BusSink struct{}
+
+func (BusSink) Name() string { return "bus" }
+
+// Send publishes the event on the in-process bus
+func (BusSink) Send(ctx context.Context, e events.Event) error {
+	events.Publish(e)
+	return nil
+}
@@ -1,9 +1,20 @@
 package routes
 
 import (
+	"cms-project/internal/audit"
 	"cms-project/internal/blog"
 	"cms-project/internal/category"
+	"cms-project/internal/comment"
+	"cms-project/internal/feed"
+	"cms-project/internal/media"
 	"cms-project/internal/menu"
+	"cms-project/internal/sitemap"
+	"cms-project/internal/stream"
+	"cms-project/internal/tag"
+	"cms-project/internal/theme"
+	"cms-project/internal/user"
+	"cms-project/internal/webhook"
+	middleware "cms-project/pkg"
 
 	"github.com/gorilla/mux"
 )
@@ -11,6 +22,25 @@ import (
 // InitializeRoutes initializes all application routes
 func InitializeRoutes() *mux.Router {
 	r := mux.NewRouter()
+	r.Use(middleware.RequestIDMiddleware)
+
+	// Auth routes
+	authRouter := r.PathPrefix("/auth").Subrouter()
+	user.RegisterAuthRoutes(authRouter)
+
+	// User routes
+	userRouter := r.PathPrefix("/users").Subrouter()
+	user.RegisterUserRoutes(userRouter)
+
+	// API key routes
+	apiKeyRouter := r.PathPrefix("/api-keys").Subrouter()
+	user.RegisterAPIKeyRoutes(apiKeyRouter)
+
+	// Comment routes; the nested blog routes are registered first so they take precedence
+	blogCommentRouter := r.PathPrefix("/blogs/{id:[a-fA-F0-9-]+}/comments").Subrouter()
+	comment.RegisterBlogCommentRoutes(blogCommentRouter)
+	commentRouter := r.PathPrefix("/comments").Subrouter()
+	comment.RegisterCommentRoutes(commentRouter)
 
 	// Blog routes
 	blogRouter := r.PathPrefix("/blogs").Subrouter()
@@ -24,5 +54,39 @@ func InitializeRoutes() *mux.Router {
 	categoryRouter := r.PathPrefix("/categories").Subrouter()
 	category.RegisterCategoryRoutes(categoryRouter)
 
+	// Tag routes
+	tagRouter := r.PathPrefix("/tags").Subrouter()
+	tag.RegisterTagRoutes(tagRouter)
+
+	// Media routes
+	mediaRouter := r.PathPrefix("/media").Subrouter()
+	media.RegisterMediaRoutes(mediaRouter)
+	fileRouter := r.PathPrefix("/uploads").Subrouter()
+	media.RegisterFileRoutes(fileRouter)
+
+	// Feed routes
+	feedRouter := r.PathPrefix("/feeds").Subrouter()
+	feed.RegisterFeedRoutes(feedRouter)
+
+	// Sitemap and robots.txt routes
+	sitemap.RegisterSitemapRoutes(r)
+
+	// Audit routes
+	auditRouter := r.PathPrefix("/audit").Subrouter()
+	audit.RegisterAuditRoutes(auditRouter)
+
+	// Event stream routes
+	streamRouter := r.PathPrefix("/events").Subrouter()
+	stream.RegisterStreamRoutes(streamRouter)
+
+	// Webhook routes
+	webhookRouter := r.PathPrefix("/webhooks").Subrouter()
+	webhook.RegisterWebhookRoutes(webhookRouter)
+
+	// Public HTML pages, registered last so that the API routes take precedence
+	if theme.Enabled() {
+		theme.RegisterThemeRoutes(r)
+	}
+
 	return r
 }
@@ -0,0 +1,42 @@
+package site
+
+import (
+	"os"
+	"strings"
+)
+
+// BlogPath returns the public path of a blog post
+func BlogPath(slug string) string {
+	return "/blog/" + slug
+}
+
+// CategoryPath returns the public path of a category archive
+func CategoryPath(slug string) string {
+	return "/category/" + slug
+}
+
+// TagPath returns the public path of a tag archive
+func TagPath(slug string) string {
+	return "/tag/" + slug
+}
+
+// URL turns a path into an absolute URL on SITE_URL. Absolute URLs are returned unchanged.
+func URL(path string) string {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	return strings.TrimSuffix(os.Getenv("SITE_URL"), "/") + path
+}
+
+// Name returns the name of the site from SITE_NAME
+func Name() string {
+	if name := os.Getenv("SITE_NAME"); name != "" {
+		return name
+	}
+	return "CMS"
+}
+
+// Description returns the tagline of the site from SITE_DESCRIPTION
+func Description() string {
+	return os.Getenv("SITE_DESCRIPTION")
+}
@@ -0,0 +1,118 @@
+package sitemap
+
+import (
+	"bytes"
+	"cms-project/internal/site"
+	"cms-project/pkg/response"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// sitemapPath is the path of the numbered sitemaps listed in the sitemap index
+const sitemapPath = "/sitemaps/sitemap-%d.xml"
+
+// GetSitemapHandler handles serving the sitemap
+// @Summary Get the sitemap
+// @Description Retrieve the sitemap of the home page, categories and published blogs with their cover images. Once there are more than 50,000 URLs this is a sitemap index pointing to numbered sitemaps.
+// @Tags Sitemap
+// @Produce xml
+// @Success 200 {string} string "The sitemap or sitemap index"
+// @Success 304 {string} string "Not modified"
+// @Failure 500 {object} response.APIResponse
+// @Router /sitemap.xml [get]
+func GetSitemapHandler(w http.ResponseWriter, r *http.Request) {
+	pages, version, err := Pages()
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to build sitemap", nil)
+		return
+	}
+	if len(pages) == 1 {
+		writeURLSet(w, r, pages[0], version)
+		return
+	}
+
+	var modified time.Time
+	index := sitemapIndex{}
+	for i, page := range pages {
+		pageModified := lastMod(page)
+		if pageModified.After(modified) {
+			modified = pageModified
+		}
+		index.Sitemaps = append(index.Sitemaps, sitemapRef{
+			Loc:     site.URL(fmt.Sprintf(sitemapPath, i+1)),
+			LastMod: formatTime(pageModified),
+		})
+	}
+	if response.NotModified(w, r, `W/"`+version+`-index"`, modified) {
+		return
+	}
+	writeXML(w, index)
+}
+
+// GetSitemapPageHandler handles serving one of the sitemaps listed in the sitemap index
+// @Summary Get a numbered sitemap
+// @Description Retrieve one of the sitemaps listed in the sitemap index, numbered from 1
+// @Tags Sitemap
+// @Produce xml
+// @Param n path int true "Sitemap number"
+// @Success 200 {string} string "The sitemap"
+// @Success 304 {string} string "Not modified"
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /sitemaps/sitemap-{n}.xml [get]
+func GetSitemapPageHandler(w http.ResponseWriter, r *http.Request) {
+	pages, version, err := Pages()
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to build sitemap", nil)
+		return
+	}
+	n, err := strconv.Atoi(mux.Vars(r)["n"])
+	if err != nil || n < 1 || n > len(pages) {
+		response.JSON(w, http.StatusNotFound, false, "Sitemap not found", nil)
+		return
+	}
+	writeURLSet(w, r, pages[n-1], fmt.Sprintf("%s-%d", version, n))
+}
+
+// writeURLSet writes a sitemap of entries unless the client has it
+func writeURLSet(w http.ResponseWriter, r *http.Request, entries []Entry, version string) {
+	if response.NotModified(w, r, `W/"`+version+`"`, lastMod(entries)) {
+		return
+	}
+	set := urlSet{ImageNS: "http://www.google.com/schemas/sitemap-image/1.1", URLs: make([]url, 0, len(entries))}
+	for _, e := range entries {
+		u := url{Loc: e.Loc, LastMod: formatTime(e.LastMod)}
+		for _, img := range e.Images {
+			u.Images = append(u.Images, image{Loc: img})
+		}
+		set.URLs = append(set.URLs, u)
+	}
+	writeXML(w, set)
+}
+
+// Helper to write an XML document, encoded fully first so that a failure can still be reported
+func writeXML(w http.ResponseWriter, v interface{}) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	if err := xml.NewEncoder(&buf).Encode(v); err != nil {
+		log.Printf("Error encoding sitemap: %v", err)
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to build sitemap", nil)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Write(buf.Bytes())
+}
+
+// Helper to format a lastmod date, empty when unknown
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
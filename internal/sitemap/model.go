@@ -0,0 +1,41 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// Entry is one page listed in the sitemap
+type Entry struct {
+	Loc     string
+	LastMod time.Time
+	Images  []string
+}
+
+// urlSet is a sitemap document with the image extension
+type urlSet struct {
+	XMLName xml.Name `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	ImageNS string   `xml:"xmlns:image,attr"`
+	URLs    []url    `xml:"url"`
+}
+
+type url struct {
+	Loc     string  `xml:"loc"`
+	LastMod string  `xml:"lastmod,omitempty"`
+	Images  []image `xml:"image:image"`
+}
+
+type image struct {
+	Loc string `xml:"image:loc"`
+}
+
+// sitemapIndex lists the sitemaps once the URLs no longer fit in one
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 sitemapindex"`
+	Sitemaps []sitemapRef `xml:"sitemap"`
+}
+
+type sitemapRef struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
@@ -0,0 +1,47 @@
+package sitemap
+
+import (
+	"cms-project/internal/site"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// defaultDisallow keeps crawlers out of the parts of the API that are not content
+const defaultDisallow = "/auth/,/users/,/api-keys/,/audit/,/swagger/"
+
+// RobotsHandler serves robots.txt. ROBOTS_FILE serves a file as is; otherwise the file allows
+// everything except the paths in ROBOTS_DISALLOW and points to the sitemap.
+func RobotsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if path := os.Getenv("ROBOTS_FILE"); path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			log.Printf("Error reading robots file: %v", err)
+			http.Error(w, "Failed to read robots.txt", http.StatusInternalServerError)
+			return
+		}
+		w.Write(content)
+		return
+	}
+
+	disallow, ok := os.LookupEnv("ROBOTS_DISALLOW")
+	if !ok {
+		disallow = defaultDisallow
+	}
+	var b strings.Builder
+	b.WriteString("User-agent: *\n")
+	written := false
+	for _, path := range strings.Split(disallow, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			b.WriteString("Disallow: " + path + "\n")
+			written = true
+		}
+	}
+	if !written {
+		b.WriteString("Disallow:\n")
+	}
+	b.WriteString("\nSitemap: " + site.URL("/sitemap.xml") + "\n")
+	w.Write([]byte(b.String()))
+}
@@ -0,0 +1,12 @@
+package sitemap
+
+import (
+	"github.com/gorilla/mux"
+)
+
+// RegisterSitemapRoutes registers the sitemap and robots.txt routes at the root of the site
+func RegisterSitemapRoutes(r *mux.Router) {
+	r.HandleFunc("/sitemap.xml", GetSitemapHandler).Methods("GET", "HEAD")                         // Sitemap or sitemap index
+	r.HandleFunc("/sitemaps/sitemap-{n:[0-9]+}.xml", GetSitemapPageHandler).Methods("GET", "HEAD") // Numbered sitemap
+	r.HandleFunc("/robots.txt", RobotsHandler).Methods("GET", "HEAD")
+}
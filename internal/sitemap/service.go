@@ -0,0 +1,350 @@
+package sitemap
+
+import (
+	"cms-project/internal/database"
+	"cms-project/internal/events"
+	"cms-project/internal/media"
+	"cms-project/internal/site"
+	"cms-project/internal/workflow"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	// maxURLs is the limit of URLs in one sitemap set by the protocol
+	maxURLs               = 50000
+	defaultRefreshEvery   = time.Hour
+	categoryLastModSelect = `
+		SELECT c.id, c.slug, GREATEST(c.created_at, COALESCE(MAX(b.updated_at), c.created_at)) AS last_mod
+		FROM categories c
+		LEFT JOIN blog_categories bc ON bc.category_id = c.id
+		LEFT JOIN blogs b ON b.id = bc.blog_id AND b.status = $1`
+)
+
+// index keeps the sitemap entries in memory. It is loaded once and then kept current from content
+// events, so requests never scan the blogs table.
+type index struct {
+	update sync.Mutex // serializes loads and refreshes
+	mu     sync.RWMutex
+
+	loaded     bool
+	generation int64 // when the index was last loaded, part of the ETag
+	version    int64 // incremented on every change
+	blogs      map[uuid.UUID]Entry
+	categories map[uuid.UUID]Entry
+	pages      [][]Entry // sorted and split entries, rebuilt after changes
+}
+
+var sitemap = &index{}
+
+// Init keeps the sitemap current by reacting to blog and category events, and reloads it every
+// SITEMAP_REFRESH (default 1h) to pick up changes made by other instances
+func Init() {
+	events.Subscribe(sitemap.handle)
+
+	every := defaultRefreshEvery
+	if d, err := time.ParseDuration(os.Getenv("SITEMAP_REFRESH")); err == nil && d > 0 {
+		every = d
+	}
+	go func() {
+		for range time.Tick(every) {
+			if err := sitemap.load(); err != nil {
+				log.Printf("Error reloading sitemap: %v", err)
+			}
+		}
+	}()
+}
+
+// Pages returns the sitemap entries sorted by location and split into sitemaps, with a version
+// that changes whenever the entries do
+func Pages() ([][]Entry, string, error) {
+	sitemap.mu.RLock()
+	loaded := sitemap.loaded
+	sitemap.mu.RUnlock()
+	if !loaded {
+		if err := sitemap.load(); err != nil {
+			return nil, "", err
+		}
+	}
+
+	sitemap.mu.Lock()
+	defer sitemap.mu.Unlock()
+	if sitemap.pages == nil {
+		sitemap.pages = paginate(sitemap.entries(), pageSize())
+	}
+	return sitemap.pages, fmt.Sprintf("%d-%d", sitemap.generation, sitemap.version), nil
+}
+
+// handle refreshes the entries affected by a content event
+func (idx *index) handle(e events.Event) {
+	id, err := uuid.Parse(e.EntityID)
+	if err != nil {
+		return
+	}
+	switch {
+	case e.Type == "blog.deleted":
+		// The blog's category links are already gone, so the event names its categories
+		idx.refreshBlog(id, deletedCategoryIDs(e))
+	case e.EntityType == "blog":
+		idx.refreshBlog(id, nil)
+	case e.EntityType == "category":
+		idx.refreshCategories([]uuid.UUID{id})
+	}
+}
+
+// load reads all published blogs and categories
+func (idx *index) load() error {
+	idx.update.Lock()
+	defer idx.update.Unlock()
+
+	blogs, err := loadBlogs(nil)
+	if err != nil {
+		return err
+	}
+	categories, err := loadCategories(nil)
+	if err != nil {
+		return err
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.blogs, idx.categories = blogs, categories
+	idx.loaded = true
+	idx.generation = time.Now().UnixNano()
+	idx.version = 0
+	idx.pages = nil
+	return nil
+}
+
+// refreshBlog reloads the entry of a single blog and of its categories, which are looked up unless
+// given
+func (idx *index) refreshBlog(id uuid.UUID, categoryIDs []uuid.UUID) {
+	idx.update.Lock()
+	defer idx.update.Unlock()
+	if !idx.isLoaded() {
+		return
+	}
+
+	entries, err := loadBlogs([]uuid.UUID{id})
+	if err != nil {
+		log.Printf("Error refreshing sitemap entry of blog %s: %v", id, err)
+		return
+	}
+	idx.mu.Lock()
+	if entry, ok := entries[id]; ok {
+		idx.blogs[id] = entry
+	} else {
+		// No longer published or deleted
+		delete(idx.blogs, id)
+	}
+	idx.changed()
+	idx.mu.Unlock()
+
+	if categoryIDs == nil {
+		if err := database.DB.Select(&categoryIDs, "SELECT category_id FROM blog_categories WHERE blog_id = $1", id); err != nil {
+			log.Printf("Error fetching categories of blog %s for sitemap: %v", id, err)
+			return
+		}
+	}
+	idx.refreshCategoriesLocked(categoryIDs)
+}
+
+// refreshCategories reloads the entries of categories
+func (idx *index) refreshCategories(ids []uuid.UUID) {
+	idx.update.Lock()
+	defer idx.update.Unlock()
+	if !idx.isLoaded() {
+		return
+	}
+	idx.refreshCategoriesLocked(ids)
+}
+
+// refreshCategoriesLocked reloads the entries of categories while holding the update lock
+func (idx *index) refreshCategoriesLocked(ids []uuid.UUID) {
+	if len(ids) == 0 {
+		return
+	}
+	entries, err := loadCategories(ids)
+	if err != nil {
+		log.Printf("Error refreshing sitemap entries of categories: %v", err)
+		return
+	}
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for _, id := range ids {
+		if entry, ok := entries[id]; ok {
+			idx.categories[id] = entry
+		} else {
+			delete(idx.categories, id)
+		}
+	}
+	idx.changed()
+}
+
+// changed invalidates the split pages after the entries changed; mu must be held
+func (idx *index) changed() {
+	idx.version++
+	idx.pages = nil
+}
+
+// Helper to read the loaded flag
+func (idx *index) isLoaded() bool {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.loaded
+}
+
+// entries returns the home page, categories and blogs sorted by location; mu must be held
+func (idx *index) entries() []Entry {
+	home := Entry{Loc: site.URL("/")}
+	list := make([]Entry, 0, len(idx.blogs)+len(idx.categories)+1)
+	for _, e := range idx.categories {
+		list = append(list, e)
+	}
+	for _, e := range idx.blogs {
+		list = append(list, e)
+		if e.LastMod.After(home.LastMod) {
+			home.LastMod = e.LastMod
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Loc < list[j].Loc })
+	return append([]Entry{home}, list...)
+}
+
+// loadBlogs reads the entries of published blogs that may be indexed, all of them when ids is nil
+func loadBlogs(ids []uuid.UUID) (map[uuid.UUID]Entry, error) {
+	var rows []struct {
+		ID           uuid.UUID  `db:"id"`
+		Slug         string     `db:"slug"`
+		UpdatedAt    time.Time  `db:"updated_at"`
+		CoverImage   string     `db:"cover_image"`
+		CoverMediaID *uuid.UUID `db:"cover_media_id"`
+	}
+	query := "SELECT id, slug, updated_at, cover_image, cover_media_id FROM blogs WHERE status = $1 AND NOT noindex"
+	args := []interface{}{workflow.Published}
+	if ids != nil {
+		query += " AND id = ANY($2)"
+		args = append(args, pq.Array(uuidStrings(ids)))
+	}
+	if err := database.DB.Select(&rows, query, args...); err != nil {
+		log.Printf("Error fetching blogs for sitemap: %v", err)
+		return nil, err
+	}
+
+	var mediaIDs []uuid.UUID
+	for _, row := range rows {
+		if row.CoverMediaID != nil {
+			mediaIDs = append(mediaIDs, *row.CoverMediaID)
+		}
+	}
+	covers, err := media.GetMediaByIDs(mediaIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[uuid.UUID]Entry, len(rows))
+	for _, row := range rows {
+		entry := Entry{Loc: site.URL(site.BlogPath(row.Slug)), LastMod: row.UpdatedAt}
+		cover := row.CoverImage
+		if row.CoverMediaID != nil {
+			if m, ok := covers[*row.CoverMediaID]; ok {
+				cover = m.URL
+			}
+		}
+		if cover != "" {
+			entry.Images = []string{site.URL(cover)}
+		}
+		entries[row.ID] = entry
+	}
+	return entries, nil
+}
+
+// loadCategories reads the entries of categories, all of them when ids is nil. A category was
+// last modified when its most recently updated published blog was.
+func loadCategories(ids []uuid.UUID) (map[uuid.UUID]Entry, error) {
+	var rows []struct {
+		ID      uuid.UUID `db:"id"`
+		Slug    string    `db:"slug"`
+		LastMod time.Time `db:"last_mod"`
+	}
+	query := categoryLastModSelect
+	args := []interface{}{workflow.Published}
+	if ids != nil {
+		query += " WHERE c.id = ANY($2)"
+		args = append(args, pq.Array(uuidStrings(ids)))
+	}
+	query += " GROUP BY c.id"
+	if err := database.DB.Select(&rows, query, args...); err != nil {
+		log.Printf("Error fetching categories for sitemap: %v", err)
+		return nil, err
+	}
+	entries := make(map[uuid.UUID]Entry, len(rows))
+	for _, row := range rows {
+		entries[row.ID] = Entry{Loc: site.URL(site.CategoryPath(row.Slug)), LastMod: row.LastMod}
+	}
+	return entries, nil
+}
+
+// paginate splits entries into sitemaps of at most size entries
+func paginate(entries []Entry, size int) [][]Entry {
+	var pages [][]Entry
+	for len(entries) > size {
+		pages = append(pages, entries[:size])
+		entries = entries[size:]
+	}
+	return append(pages, entries)
+}
+
+// pageSize returns the number of URLs per sitemap from SITEMAP_MAX_URLS, at most the protocol limit
+func pageSize() int {
+	if n, err := strconv.Atoi(os.Getenv("SITEMAP_MAX_URLS")); err == nil && n > 0 && n < maxURLs {
+		return n
+	}
+	return maxURLs
+}
+
+// Helper to read the category IDs carried by a blog.deleted event. The event data may have been
+// decoded from JSON by the outbox, so it is re-encoded rather than asserted to a type.
+func deletedCategoryIDs(e events.Event) []uuid.UUID {
+	var data struct {
+		CategoryIDs []uuid.UUID `json:"category_ids"`
+	}
+	raw, err := json.Marshal(e.Data)
+	if err != nil {
+		return nil
+	}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		log.Printf("Error reading categories of deleted blog %s: %v", e.EntityID, err)
+		return nil
+	}
+	return data.CategoryIDs
+}
+
+// Helper to convert IDs for use with ANY()
+func uuidStrings(ids []uuid.UUID) []string {
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, id.String())
+	}
+	return keys
+}
+
+// lastMod returns the most recent modification among entries
+func lastMod(entries []Entry) time.Time {
+	var latest time.Time
+	for _, e := range entries {
+		if e.LastMod.After(latest) {
+			latest = e.LastMod
+		}
+	}
+	return latest
+}
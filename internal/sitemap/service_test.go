@@ -0,0 +1,146 @@
+package sitemap
+
+import (
+	"cms-project/internal/database/dbtest"
+	"cms-project/internal/events"
+	"cms-project/internal/workflow"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+func TestPaginate(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries int
+		want    []int
+	}{
+		{"empty", 0, []int{0}},
+		{"one", 1, []int{1}},
+		{"exactly full", maxURLs, []int{maxURLs}},
+		{"one over", maxURLs + 1, []int{maxURLs, 1}},
+		{"several", 120000, []int{maxURLs, maxURLs, 20000}},
+	}
+	for _, tt := range tests {
+		pages := paginate(make([]Entry, tt.entries), maxURLs)
+		got := make([]int, len(pages))
+		for i, page := range pages {
+			got[i] = len(page)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: page sizes = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDeletedCategoryIDs(t *testing.T) {
+	id := uuid.New()
+	tests := []struct {
+		name string
+		data interface{}
+		want []uuid.UUID
+	}{
+		{"published", map[string][]uuid.UUID{"category_ids": {id}}, []uuid.UUID{id}},
+		{"decoded by the outbox", map[string]interface{}{"category_ids": []interface{}{id.String()}}, []uuid.UUID{id}},
+		{"no data", nil, nil},
+		{"no categories", map[string]interface{}{"title": "x"}, nil},
+		{"malformed", map[string]interface{}{"category_ids": []interface{}{"not-a-uuid"}}, nil},
+	}
+	for _, tt := range tests {
+		got := deletedCategoryIDs(events.Event{Type: "blog.deleted", EntityID: uuid.NewString(), Data: tt.data})
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: deletedCategoryIDs = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHandleBlogDeleted(t *testing.T) {
+	mock := dbtest.Mock(t)
+	blogID, otherID, categoryID := uuid.New(), uuid.New(), uuid.New()
+	updated := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	idx := &index{
+		loaded:     true,
+		blogs:      map[uuid.UUID]Entry{blogID: {Loc: "deleted"}, otherID: {Loc: "kept"}},
+		categories: map[uuid.UUID]Entry{categoryID: {Loc: "category"}},
+		pages:      [][]Entry{{}},
+	}
+
+	mock.ExpectQuery(`SELECT id, slug, updated_at, cover_image, cover_media_id FROM blogs WHERE status = \$1 AND NOT noindex AND id = ANY\(\$2\)`).
+		WithArgs(workflow.Published, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "slug", "updated_at", "cover_image", "cover_media_id"}))
+	// The categories come from the event, so the deleted links are not looked up
+	mock.ExpectQuery(`FROM categories c .* WHERE c.id = ANY\(\$2\) GROUP BY c.id`).
+		WithArgs(workflow.Published, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "slug", "last_mod"}).AddRow(categoryID, "news", updated))
+
+	idx.handle(events.Event{
+		Type:       "blog.deleted",
+		EntityType: "blog",
+		EntityID:   blogID.String(),
+		Data:       map[string][]uuid.UUID{"category_ids": {categoryID}},
+	})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := idx.blogs[blogID]; ok {
+		t.Error("deleted blog is still in the index")
+	}
+	if _, ok := idx.blogs[otherID]; !ok {
+		t.Error("other blog was removed from the index")
+	}
+	if got := idx.categories[categoryID].LastMod; !got.Equal(updated) {
+		t.Errorf("category last modified = %v, want %v", got, updated)
+	}
+	if idx.version == 0 || idx.pages != nil {
+		t.Errorf("version = %d, pages = %v, want the pages invalidated", idx.version, idx.pages)
+	}
+}
+
+func TestHandleBlogUpdated(t *testing.T) {
+	mock := dbtest.Mock(t)
+	blogID, categoryID := uuid.New(), uuid.New()
+	updated := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	idx := &index{loaded: true, blogs: map[uuid.UUID]Entry{}, categories: map[uuid.UUID]Entry{}}
+
+	mock.ExpectQuery(`FROM blogs WHERE status = \$1 AND NOT noindex AND id = ANY\(\$2\)`).
+		WithArgs(workflow.Published, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "slug", "updated_at", "cover_image", "cover_media_id"}).
+			AddRow(blogID, "hello", updated, "/uploads/cover.jpg", nil))
+	mock.ExpectQuery(`SELECT category_id FROM blog_categories WHERE blog_id = \$1`).
+		WithArgs(blogID).
+		WillReturnRows(sqlmock.NewRows([]string{"category_id"}).AddRow(categoryID))
+	mock.ExpectQuery(`FROM categories c .* WHERE c.id = ANY\(\$2\) GROUP BY c.id`).
+		WithArgs(workflow.Published, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "slug", "last_mod"}).AddRow(categoryID, "news", updated))
+
+	idx.handle(events.Event{Type: "blog.updated", EntityType: "blog", EntityID: blogID.String()})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+	entry, ok := idx.blogs[blogID]
+	if !ok {
+		t.Fatal("published blog is not in the index")
+	}
+	if !entry.LastMod.Equal(updated) || len(entry.Images) != 1 {
+		t.Errorf("entry = %+v, want last modified %v with the cover image", entry, updated)
+	}
+	if _, ok := idx.categories[categoryID]; !ok {
+		t.Error("category of the blog is not in the index")
+	}
+}
+
+func TestHandleBeforeLoad(t *testing.T) {
+	mock := dbtest.Mock(t)
+	idx := &index{}
+	idx.handle(events.Event{Type: "blog.updated", EntityType: "blog", EntityID: uuid.NewString()})
+	idx.handle(events.Event{Type: "category.updated", EntityType: "category", EntityID: uuid.NewString()})
+	// No queries are expected until the index is loaded
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
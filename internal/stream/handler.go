@@ -0,0 +1,98 @@
+package stream
+
+import (
+	"cms-project/internal/events"
+	"cms-project/pkg/fields"
+	"cms-project/pkg/response"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"time"
+)
+
+const (
+	defaultHeartbeat = 25 * time.Second
+	retryMillis      = 3000
+)
+
+// topicPattern matches the topics a client may select: an event type such as blog.published,
+// every event of an entity as blog or blog.*, or * for all events
+var topicPattern = regexp.MustCompile(`^(\*|[a-z_]+(\.(\*|[a-z_]+))?)$`)
+
+// StreamEventsHandler handles streaming content changes to live previews and dashboards
+// @Summary Stream content changes
+// @Description Server-Sent Events stream of blog, category and menu changes such as blog.created, blog.updated, blog.deleted, blog.published, category.created and menu.updated. Every message carries the event ID, type, entity and time; fetch the entity for its content. Reconnecting clients send Last-Event-ID to receive what they missed; a stream.reset message means some events are no longer available and the client should reload.
+// @Tags Events
+// @Security BearerAuth
+// @Produce text/event-stream
+// @Param topics query string false "Comma separated topics, e.g. blog.published,category.*,menu; all events when empty"
+// @Param Last-Event-ID header string false "ID of the last event received"
+// @Success 200 {string} string "Event stream"
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Router /events [get]
+func StreamEventsHandler(w http.ResponseWriter, r *http.Request) {
+	topics := fields.Parse(r.URL.Query().Get("topics"))
+	for _, t := range topics {
+		if !topicPattern.MatchString(t) {
+			response.JSON(w, http.StatusBadRequest, false, fmt.Sprintf("Invalid topic %q", t), nil)
+			return
+		}
+	}
+
+	c, missed, reset := live.subscribe(topics, r.Header.Get("Last-Event-ID"))
+	defer live.unsubscribe(c)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // keep proxies such as nginx from buffering the stream
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", retryMillis)
+	if reset {
+		fmt.Fprint(w, "data: {\"type\":\"stream.reset\"}\n\n")
+	}
+	for _, e := range missed {
+		writeEvent(w, e)
+	}
+	flusher := http.NewResponseController(w)
+	if err := flusher.Flush(); err != nil {
+		return
+	}
+
+	every := defaultHeartbeat
+	if d, err := time.ParseDuration(os.Getenv("STREAM_HEARTBEAT")); err == nil && d > 0 {
+		every = d
+	}
+	heartbeat := time.NewTicker(every)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-c.events:
+			if !ok {
+				// Dropped for falling behind; the client resumes with Last-Event-ID
+				return
+			}
+			writeEvent(w, e)
+		case <-heartbeat.C:
+			// Comments keep idle connections from being closed by proxies
+			fmt.Fprint(w, ": ping\n\n")
+		}
+		if err := flusher.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// Helper to write an event as a Server-Sent Events message
+func writeEvent(w http.ResponseWriter, e events.Event) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "id: %s\ndata: %s\n\n", e.ID, data)
+}
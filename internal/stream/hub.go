@@ -0,0 +1,151 @@
+package stream
+
+import (
+	"cms-project/internal/events"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// clientBuffer is how many events a client may fall behind before it is disconnected; it then
+// reconnects with Last-Event-ID and catches up from the replay buffer
+const clientBuffer = 64
+
+// client is a connected event stream
+type client struct {
+	topics []string
+	events chan events.Event
+}
+
+// hub fans events out to the connected clients of this instance and keeps the latest of them
+// for clients resuming with Last-Event-ID
+type hub struct {
+	mu      sync.Mutex
+	clients map[*client]bool
+	replay  []events.Event // oldest first, at most size entries
+	seen    map[string]bool
+	size    int
+	evicted int64 // highest ID dropped from the replay buffer
+}
+
+func newHub(size int) *hub {
+	return &hub{clients: make(map[*client]bool), seen: make(map[string]bool), size: size}
+}
+
+// broadcast stores an event for replay and sends it to the clients subscribed to it. Events the
+// outbox dispatched again are ignored.
+func (h *hub) broadcast(e events.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.seen[e.ID] {
+		return
+	}
+	h.remember(e)
+	for c := range h.clients {
+		if !matches(c.topics, e.Type) {
+			continue
+		}
+		select {
+		case c.events <- e:
+		default:
+			// Too slow: drop it rather than hold up everyone else
+			h.drop(c)
+		}
+	}
+}
+
+// subscribe registers a client and returns the buffered events it missed after lastID. reset is
+// true when some of them are no longer buffered, in which case the client should reload.
+func (h *hub) subscribe(topics []string, lastID string) (*client, []events.Event, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	c := &client{topics: topics, events: make(chan events.Event, clientBuffer)}
+	h.clients[c] = true
+	if lastID == "" {
+		return c, nil, false
+	}
+
+	// IDs are assigned when events are written but arrive in commit order, so resume from the
+	// position of the last event the client saw when it is still buffered
+	start := -1
+	for i, e := range h.replay {
+		if e.ID == lastID {
+			start = i + 1
+			break
+		}
+	}
+	if start < 0 {
+		last, err := strconv.ParseInt(lastID, 10, 64)
+		if err != nil || last < h.evicted {
+			return c, nil, true
+		}
+		start = len(h.replay)
+		for i, e := range h.replay {
+			if id, _ := strconv.ParseInt(e.ID, 10, 64); id > last {
+				start = i
+				break
+			}
+		}
+	}
+	var missed []events.Event
+	for _, e := range h.replay[start:] {
+		if matches(topics, e.Type) {
+			missed = append(missed, e)
+		}
+	}
+	return c, missed, false
+}
+
+// unsubscribe removes a client that disconnected
+func (h *hub) unsubscribe(c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.drop(c)
+}
+
+// remember appends an event to the replay buffer, evicting the oldest one when it is full
+func (h *hub) remember(e events.Event) {
+	h.seen[e.ID] = true
+	h.replay = append(h.replay, e)
+	if len(h.replay) <= h.size {
+		return
+	}
+	oldest := h.replay[0]
+	h.replay = h.replay[1:]
+	delete(h.seen, oldest.ID)
+	if id, err := strconv.ParseInt(oldest.ID, 10, 64); err == nil && id > h.evicted {
+		h.evicted = id
+	}
+}
+
+// Helper to disconnect a client; the caller holds the lock
+func (h *hub) drop(c *client) {
+	if h.clients[c] {
+		delete(h.clients, c)
+		close(c.events)
+	}
+}
+
+// matches reports whether an event type is selected by topics: event types such as
+// blog.published, blog.* or just blog for every event of an entity, and * for all events.
+// No topics selects all events.
+func matches(topics []string, eventType string) bool {
+	if len(topics) == 0 {
+		return true
+	}
+	for _, t := range topics {
+		switch {
+		case t == "*" || t == eventType:
+			return true
+		case !strings.Contains(t, "."):
+			if strings.HasPrefix(eventType, t+".") {
+				return true
+			}
+		case strings.HasSuffix(t, ".*"):
+			if strings.HasPrefix(eventType, strings.TrimSuffix(t, "*")) {
+				return true
+			}
+		}
+	}
+	return false
+}
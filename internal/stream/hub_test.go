@@ -0,0 +1,118 @@
+package stream
+
+import (
+	"cms-project/internal/events"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func event(id, eventType string) events.Event {
+	return events.Event{ID: id, Type: eventType}
+}
+
+// Helper to list the IDs of events
+func ids(list []events.Event) []string {
+	var out []string
+	for _, e := range list {
+		out = append(out, e.ID)
+	}
+	return out
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		topics    []string
+		eventType string
+		want      bool
+	}{
+		{nil, "blog.published", true},
+		{[]string{"*"}, "comment.created", true},
+		{[]string{"blog.published"}, "blog.published", true},
+		{[]string{"blog.published"}, "blog.updated", false},
+		{[]string{"blog"}, "blog.updated", true},
+		{[]string{"blog"}, "blogroll.updated", false},
+		{[]string{"blog.*"}, "blog.deleted", true},
+		{[]string{"blog.*"}, "category.deleted", false},
+		{[]string{"category", "blog.published"}, "blog.published", true},
+		{[]string{"category", "blog.published"}, "media.created", false},
+	}
+	for _, tt := range tests {
+		if got := matches(tt.topics, tt.eventType); got != tt.want {
+			t.Errorf("matches(%v, %q) = %v, want %v", tt.topics, tt.eventType, got, tt.want)
+		}
+	}
+}
+
+func TestSubscribeResume(t *testing.T) {
+	h := newHub(3)
+	// IDs arrive in commit order rather than numerically
+	for _, e := range []events.Event{
+		event("1", "blog.created"),
+		event("2", "blog.updated"),
+		event("4", "blog.published"),
+		event("3", "comment.created"),
+		event("5", "blog.deleted"),
+	} {
+		h.broadcast(e)
+	}
+
+	tests := []struct {
+		name      string
+		topics    []string
+		lastID    string
+		wantIDs   []string
+		wantReset bool
+	}{
+		{"new client", nil, "", nil, false},
+		{"buffered", nil, "4", []string{"3", "5"}, false},
+		{"buffered, filtered", []string{"blog"}, "4", []string{"5"}, false},
+		{"latest", nil, "5", nil, false},
+		{"last evicted", nil, "2", []string{"4", "3", "5"}, false},
+		{"evicted", nil, "1", nil, true},
+		{"not buffered, newer", nil, "9", nil, false},
+		{"non-numeric", nil, "abc", nil, true},
+	}
+	for _, tt := range tests {
+		c, missed, reset := h.subscribe(tt.topics, tt.lastID)
+		h.unsubscribe(c)
+		if got := ids(missed); !reflect.DeepEqual(got, tt.wantIDs) || reset != tt.wantReset {
+			t.Errorf("%s: subscribe = %v, reset %v, want %v, reset %v", tt.name, got, reset, tt.wantIDs, tt.wantReset)
+		}
+	}
+}
+
+func TestBroadcastIgnoresRedispatchedEvents(t *testing.T) {
+	h := newHub(10)
+	c, _, _ := h.subscribe(nil, "")
+	h.broadcast(event("1", "blog.created"))
+	h.broadcast(event("1", "blog.created"))
+
+	if len(c.events) != 1 || len(h.replay) != 1 {
+		t.Errorf("delivered %d and buffered %d events, want 1 each", len(c.events), len(h.replay))
+	}
+}
+
+func TestBroadcastDropsSlowClients(t *testing.T) {
+	h := newHub(clientBuffer * 2)
+	slow, _, _ := h.subscribe(nil, "")
+	other, _, _ := h.subscribe([]string{"comment"}, "")
+
+	for i := 0; i <= clientBuffer; i++ {
+		h.broadcast(event(strconv.Itoa(i+1), "blog.updated"))
+	}
+
+	if h.clients[slow] {
+		t.Error("client that fell behind is still subscribed")
+	}
+	received := 0
+	for range slow.events {
+		received++
+	}
+	if received != clientBuffer {
+		t.Errorf("slow client received %d events before being dropped, want %d", received, clientBuffer)
+	}
+	if !h.clients[other] {
+		t.Error("client not subscribed to the events was dropped")
+	}
+}
@@ -0,0 +1,12 @@
+package stream
+
+import (
+	"cms-project/internal/user"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterStreamRoutes registers the event stream route
+func RegisterStreamRoutes(r *mux.Router) {
+	r.Handle("", user.Require(user.PermBlogsRead, StreamEventsHandler)).Methods("GET")
+}
@@ -0,0 +1,109 @@
+package stream
+
+import (
+	"cms-project/internal/database"
+	"cms-project/internal/events"
+	"cms-project/internal/outbox"
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	// channel is the Postgres notification channel events are fanned out on to every instance
+	channel           = "content_events"
+	defaultReplaySize = 1000
+)
+
+// live holds the clients connected to this instance
+var live = newHub(defaultReplaySize)
+
+// Init registers the stream as a sink of the outbox and listens for the events dispatched by any
+// instance. The replay buffer keeps the last STREAM_REPLAY_SIZE events (default 1000) and starts
+// out with the latest dispatched entries of the outbox, so clients can resume across restarts.
+func Init() {
+	if n, err := strconv.Atoi(os.Getenv("STREAM_REPLAY_SIZE")); err == nil && n > 0 {
+		live = newHub(n)
+	}
+	if err := seed(); err != nil {
+		log.Printf("Error loading recent events for the event stream: %v", err)
+	}
+	outbox.AddSink(Sink{})
+	go listen()
+}
+
+// Sink fans the events dispatched from the outbox out to the stream clients of every instance
+type Sink struct{}
+
+func (Sink) Name() string { return "stream" }
+
+// Send notifies every instance of an event. Notifications are limited to 8000 bytes, so the event
+// data is left out; clients fetch the entity when they need it.
+func (Sink) Send(ctx context.Context, e events.Event) error {
+	payload, err := json.Marshal(summary(e))
+	if err != nil {
+		return err
+	}
+	_, err = database.DB.ExecContext(ctx, "SELECT pg_notify($1, $2)", channel, string(payload))
+	return err
+}
+
+// listen broadcasts the events notified by any instance to the clients of this one
+func listen() {
+	listener := pq.NewListener(os.Getenv("DATABASE_URL"), time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Event stream listener: %v", err)
+		}
+	})
+	if err := listener.Listen(channel); err != nil {
+		log.Printf("Error listening for content events: %v", err)
+		return
+	}
+	for n := range listener.Notify {
+		if n == nil {
+			continue
+		}
+		var e events.Event
+		if err := json.Unmarshal([]byte(n.Extra), &e); err != nil {
+			log.Printf("Error decoding content event: %v", err)
+			continue
+		}
+		live.broadcast(e)
+	}
+}
+
+// seed fills the replay buffer with the latest dispatched outbox entries. Anything older may be
+// gone, so clients resuming from before them are told to reload.
+func seed() error {
+	var entries []outbox.Entry
+	query := `
+		SELECT * FROM (
+			SELECT * FROM outbox WHERE dispatched_at IS NOT NULL ORDER BY id DESC LIMIT $1
+		) latest ORDER BY id`
+	if err := database.DB.Select(&entries, query, live.size); err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	for i := range entries {
+		e, err := entries[i].Event()
+		if err != nil {
+			return err
+		}
+		live.broadcast(summary(e))
+	}
+	live.evicted = entries[0].ID - 1
+	return nil
+}
+
+// Helper to strip the data from an event
+func summary(e events.Event) events.Event {
+	e.Data = nil
+	return e
+}
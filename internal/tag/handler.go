@@ -0,0 +1,139 @@
+package tag
+
+import (
+	"cms-project/internal/audit"
+	"cms-project/pkg/response"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// GetTagsHandler handles tag autocomplete
+// @Summary Autocomplete tags
+// @Description Retrieve tags whose name starts with the query, most used first
+// @Tags Tag
+// @Param q query string false "Name prefix"
+// @Param limit query int false "Maximum number of tags"
+// @Success 200 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /tags [get]
+func GetTagsHandler(w http.ResponseWriter, r *http.Request) {
+	tags, err := Autocomplete(r.URL.Query().Get("q"), getLimit(r, 10, 50))
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to retrieve tags", nil)
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "Tags retrieved successfully", tags)
+}
+
+// GetTagCloudHandler handles retrieving the tag cloud
+// @Summary Get the tag cloud
+// @Description Retrieve the tags of published blogs with the number of blogs using each
+// @Tags Tag
+// @Param limit query int false "Maximum number of tags"
+// @Success 200 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /tags/cloud [get]
+func GetTagCloudHandler(w http.ResponseWriter, r *http.Request) {
+	tags, err := GetTagCloud(getLimit(r, 50, 200))
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to retrieve tag cloud", nil)
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "Tag cloud retrieved successfully", tags)
+}
+
+// RenameTagHandler handles renaming a tag
+// @Summary Rename a tag
+// @Description Change the name and slug of a tag
+// @Tags Tag
+// @Security BearerAuth
+// @Param id path string true "Tag ID"
+// @Param tag body tag.RenameRequest true "New name"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /tags/{id} [put]
+func RenameTagHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid tag ID format", nil)
+		return
+	}
+	var req RenameRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid input", nil)
+		return
+	}
+	tag, err := RenameTag(id, req.Name, audit.ActorFrom(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidName):
+			response.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
+		case errors.Is(err, ErrNameTaken):
+			response.JSON(w, http.StatusConflict, false, err.Error(), nil)
+		case errors.Is(err, sql.ErrNoRows):
+			response.JSON(w, http.StatusNotFound, false, "Tag not found", nil)
+		default:
+			response.JSON(w, http.StatusInternalServerError, false, "Failed to rename tag", nil)
+		}
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "Tag renamed successfully", tag)
+}
+
+// MergeTagHandler handles merging a tag into another
+// @Summary Merge tags
+// @Description Move every blog of a tag to another tag and delete the merged tag
+// @Tags Tag
+// @Security BearerAuth
+// @Param id path string true "ID of the tag to merge away"
+// @Param merge body tag.MergeRequest true "Tag to merge into"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /tags/{id}/merge [post]
+func MergeTagHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid tag ID format", nil)
+		return
+	}
+	var req MergeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Into == uuid.Nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid input", nil)
+		return
+	}
+	target, err := MergeTag(id, req.Into, audit.ActorFrom(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrMergeIntoSelf):
+			response.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
+		case errors.Is(err, sql.ErrNoRows):
+			response.JSON(w, http.StatusNotFound, false, "Tag not found", nil)
+		default:
+			response.JSON(w, http.StatusInternalServerError, false, "Failed to merge tags", nil)
+		}
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "Tags merged successfully", target)
+}
+
+// Helper to read ?limit= within bounds
+func getLimit(r *http.Request, defaultValue, maxValue int) int {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		return defaultValue
+	}
+	return min(limit, maxValue)
+}
@@ -0,0 +1,32 @@
+package tag
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tag is a free-form label authors attach to blogs
+type Tag struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	Name      string    `db:"name" json:"name" example:"PostgreSQL"`
+	Slug      string    `db:"slug" json:"slug" example:"postgresql"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"` // last renamed or merged into
+}
+
+// TagCount is a tag with the number of blogs it is attached to
+type TagCount struct {
+	Tag
+	Count int `db:"count" json:"count" example:"12"`
+}
+
+// RenameRequest represents the new name of a tag
+type RenameRequest struct {
+	Name string `json:"name" example:"PostgreSQL"`
+}
+
+// MergeRequest names the tag another tag is merged into
+type MergeRequest struct {
+	Into uuid.UUID `json:"into" example:"550e8400-e29b-41d4-a716-446655440000"`
+}
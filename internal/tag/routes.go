@@ -0,0 +1,15 @@
+package tag
+
+import (
+	"cms-project/internal/user"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterTagRoutes registers all tag routes
+func RegisterTagRoutes(r *mux.Router) {
+	r.HandleFunc("", GetTagsHandler).Methods("GET")                                                           // Autocomplete
+	r.HandleFunc("/cloud", GetTagCloudHandler).Methods("GET")                                                 // Tags with counts
+	r.Handle("/{id:[a-fA-F0-9-]+}", user.Require(user.PermTagsManage, RenameTagHandler)).Methods("PUT")       // Rename a tag
+	r.Handle("/{id:[a-fA-F0-9-]+}/merge", user.Require(user.PermTagsManage, MergeTagHandler)).Methods("POST") // Merge into another tag
+}
@@ -0,0 +1,317 @@
+package tag
+
+import (
+	"cms-project/internal/audit"
+	"cms-project/internal/database"
+	"cms-project/pkg/slug"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const (
+	maxNameLength  = 50
+	maxTagsPerBlog = 20
+)
+
+var (
+	ErrInvalidName   = fmt.Errorf("tag names must have 1 to %d characters and contain a letter or digit", maxNameLength)
+	ErrTooManyTags   = fmt.Errorf("a blog can have at most %d tags", maxTagsPerBlog)
+	ErrNameTaken     = errors.New("another tag already has this name, merge the tags instead")
+	ErrMergeIntoSelf = errors.New("a tag cannot be merged into itself")
+)
+
+// Normalize trims a tag name and collapses its inner whitespace
+func Normalize(name string) string {
+	return strings.Join(strings.Fields(strings.TrimPrefix(strings.TrimSpace(name), "#")), " ")
+}
+
+// Validate checks a list of tag names as sent with a blog
+func Validate(names []string) error {
+	if len(names) > maxTagsPerBlog {
+		return ErrTooManyTags
+	}
+	for _, name := range names {
+		name = Normalize(name)
+		if utf8.RuneCountInString(name) > maxNameLength || strings.IndexFunc(name, isLetterOrDigit) < 0 {
+			return ErrInvalidName
+		}
+	}
+	return nil
+}
+
+func isLetterOrDigit(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// SetBlogTags replaces the tags of a blog as part of tx, creating tags that do not exist yet.
+// Names are matched case-insensitively, so existing tags keep their spelling.
+func SetBlogTags(tx *sqlx.Tx, blogID uuid.UUID, names []string) ([]Tag, error) {
+	if err := Validate(names); err != nil {
+		return nil, err
+	}
+	tags, err := ensureTags(tx, names)
+	if err != nil {
+		log.Printf("Error creating tags: %v", err)
+		return nil, err
+	}
+	ids := make([]string, 0, len(tags))
+	for _, t := range tags {
+		ids = append(ids, t.ID.String())
+	}
+	if _, err := tx.Exec("DELETE FROM blog_tags WHERE blog_id = $1 AND NOT (tag_id = ANY($2))", blogID, pq.Array(ids)); err != nil {
+		log.Printf("Error removing blog tags: %v", err)
+		return nil, err
+	}
+	query := "INSERT INTO blog_tags (blog_id, tag_id) SELECT $1, unnest($2::uuid[]) ON CONFLICT DO NOTHING"
+	if _, err := tx.Exec(query, blogID, pq.Array(ids)); err != nil {
+		log.Printf("Error adding blog tags: %v", err)
+		return nil, err
+	}
+	return tags, nil
+}
+
+// GetTagsForBlogs retrieves the tags of several blogs in a single query, keyed by blog ID
+func GetTagsForBlogs(blogIDs []uuid.UUID) (map[uuid.UUID][]Tag, error) {
+	result := make(map[uuid.UUID][]Tag)
+	if len(blogIDs) == 0 {
+		return result, nil
+	}
+	ids := make([]string, 0, len(blogIDs))
+	for _, id := range blogIDs {
+		ids = append(ids, id.String())
+	}
+	var rows []struct {
+		BlogID uuid.UUID `db:"blog_id"`
+		Tag
+	}
+	query := `
+		SELECT bt.blog_id, t.* FROM blog_tags bt
+		JOIN tags t ON t.id = bt.tag_id
+		WHERE bt.blog_id = ANY($1)
+		ORDER BY t.name`
+	if err := database.DB.Select(&rows, query, pq.Array(ids)); err != nil {
+		log.Printf("Error fetching blog tags: %v", err)
+		return nil, err
+	}
+	for _, row := range rows {
+		result[row.BlogID] = append(result[row.BlogID], row.Tag)
+	}
+	return result, nil
+}
+
+// GetTagByID retrieves a single tag by ID
+func GetTagByID(id uuid.UUID) (*Tag, error) {
+	var tag Tag
+	query := "SELECT * FROM tags WHERE id = $1"
+	err := database.DB.Get(&tag, query, id)
+	if err != nil {
+		log.Printf("Error fetching tag by ID: %v", err)
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// GetTagBySlug retrieves a single tag by slug
+func GetTagBySlug(tagSlug string) (*Tag, error) {
+	var tag Tag
+	query := "SELECT * FROM tags WHERE slug = $1"
+	err := database.DB.Get(&tag, query, tagSlug)
+	if err != nil {
+		log.Printf("Error fetching tag by slug: %v", err)
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// Autocomplete retrieves tags whose name starts with prefix, most used first
+func Autocomplete(prefix string, limit int) ([]TagCount, error) {
+	var tags []TagCount
+	query := `
+		SELECT t.*, COUNT(bt.blog_id) AS count FROM tags t
+		LEFT JOIN blog_tags bt ON bt.tag_id = t.id
+		WHERE LOWER(t.name) LIKE $1
+		GROUP BY t.id
+		ORDER BY count DESC, t.name
+		LIMIT $2`
+	err := database.DB.Select(&tags, query, escapeLike(strings.ToLower(Normalize(prefix)))+"%", limit)
+	if err != nil {
+		log.Printf("Error autocompleting tags: %v", err)
+		return nil, err
+	}
+	return tags, nil
+}
+
+// GetTagCloud retrieves the tags used by published blogs with their counts, most used first
+func GetTagCloud(limit int) ([]TagCount, error) {
+	var tags []TagCount
+	query := `
+		SELECT t.*, COUNT(*) AS count FROM tags t
+		JOIN blog_tags bt ON bt.tag_id = t.id
+		JOIN blogs b ON b.id = bt.blog_id AND b.status = 'published'
+		GROUP BY t.id
+		ORDER BY count DESC, t.name
+		LIMIT $1`
+	err := database.DB.Select(&tags, query, limit)
+	if err != nil {
+		log.Printf("Error fetching tag cloud: %v", err)
+		return nil, err
+	}
+	return tags, nil
+}
+
+// RenameTag changes the name and slug of a tag, recording the audit event in the same transaction
+func RenameTag(id uuid.UUID, name string, actor audit.Actor) (*Tag, error) {
+	name = Normalize(name)
+	if err := Validate([]string{name}); err != nil {
+		return nil, err
+	}
+	tx, err := database.DB.Beginx()
+	if err != nil {
+		log.Printf("Error starting tag rename: %v", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var existing Tag
+	if err := tx.Get(&existing, "SELECT * FROM tags WHERE id = $1 FOR UPDATE", id); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("Error retrieving tag by ID: %v", err)
+		}
+		return nil, err
+	}
+	tagSlug, err := uniqueSlug(tx, id, name)
+	if err != nil {
+		return nil, err
+	}
+	var tag Tag
+	query := "UPDATE tags SET name = $1, slug = $2, updated_at = NOW() WHERE id = $3 RETURNING *"
+	err = tx.Get(&tag, query, name, tagSlug, id)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return nil, ErrNameTaken
+		}
+		log.Printf("Error renaming tag: %v", err)
+		return nil, err
+	}
+	if err := audit.Write(tx, actor, audit.ActionUpdate, "tag", id.String(), existing, tag); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing tag rename: %v", err)
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// MergeTag moves every blog of the source tag to the target tag and deletes the source, recording
+// the audit event in the same transaction
+func MergeTag(sourceID, targetID uuid.UUID, actor audit.Actor) (*Tag, error) {
+	if sourceID == targetID {
+		return nil, ErrMergeIntoSelf
+	}
+	tx, err := database.DB.Beginx()
+	if err != nil {
+		log.Printf("Error starting tag merge: %v", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var source, target Tag
+	if err := tx.Get(&source, "SELECT * FROM tags WHERE id = $1 FOR UPDATE", sourceID); err != nil {
+		return nil, err
+	}
+	// Blogs of the source now list the target instead
+	if err := tx.Get(&target, "UPDATE tags SET updated_at = NOW() WHERE id = $1 RETURNING *", targetID); err != nil {
+		return nil, err
+	}
+	query := `
+		INSERT INTO blog_tags (blog_id, tag_id)
+		SELECT blog_id, $2 FROM blog_tags WHERE tag_id = $1
+		ON CONFLICT DO NOTHING`
+	if _, err := tx.Exec(query, sourceID, targetID); err != nil {
+		log.Printf("Error moving blogs to merged tag: %v", err)
+		return nil, err
+	}
+	if _, err := tx.Exec("DELETE FROM tags WHERE id = $1", sourceID); err != nil {
+		log.Printf("Error deleting merged tag: %v", err)
+		return nil, err
+	}
+	if err := audit.Write(tx, actor, audit.ActionDelete, "tag", sourceID.String(), source, target); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing tag merge: %v", err)
+		return nil, err
+	}
+	return &target, nil
+}
+
+// ensureTags returns the tags with the given names, creating missing ones. Names are matched
+// case-insensitively; different names sharing a slug, such as C# and C++, get numbered slugs.
+func ensureTags(tx *sqlx.Tx, names []string) ([]Tag, error) {
+	tags := make([]Tag, 0, len(names))
+	seen := make(map[string]bool)
+	query := `
+		INSERT INTO tags (id, name, slug) VALUES ($1, $2, $3)
+		ON CONFLICT (LOWER(name)) DO UPDATE SET name = tags.name
+		RETURNING *`
+	for _, name := range names {
+		name = Normalize(name)
+		if seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+
+		var tag Tag
+		err := tx.Get(&tag, "SELECT * FROM tags WHERE LOWER(name) = LOWER($1)", name)
+		if err == nil {
+			tags = append(tags, tag)
+			continue
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		id := uuid.New()
+		tagSlug, err := uniqueSlug(tx, id, name)
+		if err != nil {
+			return nil, err
+		}
+		if err := tx.Get(&tag, query, id, name, tagSlug); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// uniqueSlug returns a slug for the tag with the given ID and name that no other tag uses,
+// numbering it when needed. Names without transliterable letters, such as Cyrillic or CJK ones,
+// fall back to the ID.
+func uniqueSlug(tx *sqlx.Tx, id uuid.UUID, name string) (string, error) {
+	base := slug.Make(name)
+	if base == "" {
+		return id.String(), nil
+	}
+	var taken []string
+	query := "SELECT slug FROM tags WHERE (slug = $1 OR slug LIKE $2) AND id <> $3"
+	if err := tx.Select(&taken, query, base, base+"-%", id); err != nil {
+		log.Printf("Error checking tag slugs: %v", err)
+		return "", err
+	}
+	return slug.Unique(base, taken), nil
+}
+
+// escapeLike escapes the wildcard characters of a LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,72 @@
+package tag
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"go", "go"},
+		{"  Go  ", "Go"},
+		{"#golang", "golang"},
+		{"  # machine   learning\t", "machine learning"},
+		{"##double", "#double"},
+		{"C#", "C#"},
+		{"   ", ""},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.name); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tooMany := make([]string, maxTagsPerBlog+1)
+	for i := range tooMany {
+		tooMany[i] = "tag"
+	}
+	tests := []struct {
+		name  string
+		names []string
+		want  error
+	}{
+		{"none", nil, nil},
+		{"ascii", []string{"go", "C++", "C#"}, nil},
+		{"cyrillic", []string{"программирование"}, nil},
+		{"cjk", []string{"日本語"}, nil},
+		{"digits", []string{"2024"}, nil},
+		{"longest", []string{strings.Repeat("é", maxNameLength)}, nil},
+		{"empty", []string{"go", ""}, ErrInvalidName},
+		{"only hash", []string{"#"}, ErrInvalidName},
+		{"only punctuation", []string{"+-*"}, ErrInvalidName},
+		{"too long", []string{strings.Repeat("a", maxNameLength+1)}, ErrInvalidName},
+		{"too many", tooMany, ErrTooManyTags},
+	}
+	for _, tt := range tests {
+		if err := Validate(tt.names); !errors.Is(err, tt.want) {
+			t.Errorf("%s: Validate = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		s, want string
+	}{
+		{"go", "go"},
+		{"100%", `100\%`},
+		{"snake_case", `snake\_case`},
+		{`back\slash`, `back\\slash`},
+		{`%_\`, `\%\_\\`},
+	}
+	for _, tt := range tests {
+		if got := escapeLike(tt.s); got != tt.want {
+			t.Errorf("escapeLike(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
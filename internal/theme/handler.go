@@ -0,0 +1,264 @@
+package theme
+
+import (
+	"cms-project/internal/blog"
+	"cms-project/internal/category"
+	"cms-project/internal/menu"
+	"cms-project/internal/tag"
+	"cms-project/pkg/response"
+	"database/sql"
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultPageSize = 10
+	defaultMenus    = "header,footer"
+)
+
+// HomeHandler renders the latest published blogs
+func HomeHandler(w http.ResponseWriter, r *http.Request) {
+	page := &Page{}
+	if !list(w, r, page, blog.PublishedFilter{}) {
+		return
+	}
+	render(w, http.StatusOK, "home", page)
+}
+
+// BlogHandler renders a published blog by slug
+func BlogHandler(w http.ResponseWriter, r *http.Request) {
+	b, err := blog.GetPublishedBlogBySlug(mux.Vars(r)["slug"])
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			NotFoundHandler(w, r)
+			return
+		}
+		serverError(w, err)
+		return
+	}
+	meta, err := blog.GetBlogMeta(&b.Blog)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+	breadcrumb, err := blog.GetBlogBreadcrumb(&b.Blog)
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+	tags, err := tag.GetTagsForBlogs([]uuid.UUID{b.ID})
+	if err != nil {
+		serverError(w, err)
+		return
+	}
+	page := &Page{
+		Title: b.Title,
+		// Both are produced by this application: the content is sanitized when saved and the
+		// meta markup escapes every value
+		Blog:       &BlogView{PublishedBlog: *b, Content: template.HTML(b.ContentHTML), TagLinks: tags[b.ID]},
+		Head:       template.HTML(meta.HTML),
+		Breadcrumb: breadcrumb,
+	}
+	if page.Menus, err = loadMenus(); err != nil {
+		serverError(w, err)
+		return
+	}
+	render(w, http.StatusOK, "blog", page)
+}
+
+// CategoryHandler renders the published blogs of a category
+func CategoryHandler(w http.ResponseWriter, r *http.Request) {
+	c, err := category.GetCategoryBySlug(mux.Vars(r)["slug"])
+	if err != nil {
+		NotFoundHandler(w, r)
+		return
+	}
+	page := &Page{Title: c.Name, Category: c}
+	if !list(w, r, page, blog.PublishedFilter{CategoryID: &c.ID}) {
+		return
+	}
+	render(w, http.StatusOK, "category", page)
+}
+
+// TagHandler renders the published blogs carrying a tag
+func TagHandler(w http.ResponseWriter, r *http.Request) {
+	t, err := tag.GetTagBySlug(mux.Vars(r)["slug"])
+	if err != nil {
+		NotFoundHandler(w, r)
+		return
+	}
+	page := &Page{Title: t.Name, Tag: t}
+	if !list(w, r, page, blog.PublishedFilter{TagSlug: t.Slug}) {
+		return
+	}
+	render(w, http.StatusOK, "tag", page)
+}
+
+// SearchHandler renders the published blogs matching the q parameter
+func SearchHandler(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	page := &Page{Title: "Search", Query: query}
+	if query == "" {
+		var err error
+		if page.Menus, err = loadMenus(); err != nil {
+			serverError(w, err)
+			return
+		}
+		render(w, http.StatusOK, "search", page)
+		return
+	}
+	if !list(w, r, page, blog.PublishedFilter{Query: query}) {
+		return
+	}
+	render(w, http.StatusOK, "search", page)
+}
+
+// NotFoundHandler renders the 404 page for browsers and answers API clients with JSON
+func NotFoundHandler(w http.ResponseWriter, r *http.Request) {
+	if !acceptsHTML(r) {
+		response.JSON(w, http.StatusNotFound, false, "Not found", nil)
+		return
+	}
+	page := &Page{Title: "Page not found"}
+	menus, err := loadMenus()
+	if err != nil {
+		// The 404 page still works without menus
+		log.Printf("Error loading menus for 404 page: %v", err)
+	}
+	page.Menus = menus
+	render(w, http.StatusNotFound, "404", page)
+}
+
+// acceptsHTML reports whether the client asked for an HTML page, as browsers do
+func acceptsHTML(r *http.Request) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		for _, mediaType := range strings.Split(accept, ",") {
+			mediaType, _, _ = strings.Cut(mediaType, ";")
+			if strings.EqualFold(strings.TrimSpace(mediaType), "text/html") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AssetsHandler serves the static files of the theme
+func AssetsHandler() http.Handler {
+	server := http.StripPrefix("/assets/", http.FileServer(http.FS(Assets())))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		server.ServeHTTP(w, r)
+	})
+}
+
+// list loads a page of published blogs matching filter together with the menus. It writes an
+// error response and returns false when that fails.
+func list(w http.ResponseWriter, r *http.Request, page *Page, filter blog.PublishedFilter) bool {
+	size := defaultPageSize
+	if n, err := strconv.Atoi(os.Getenv("THEME_PAGE_SIZE")); err == nil && n > 0 {
+		size = n
+	}
+	// Archives are paginated with /page/{n} so that they can be exported as static files
+	raw := mux.Vars(r)["page"]
+	if raw == "" {
+		raw = r.URL.Query().Get("page")
+	}
+	number, err := strconv.Atoi(raw)
+	if err != nil || number < 1 {
+		number = 1
+	}
+
+	count, _, err := blog.GetPublishedState(filter)
+	if err != nil {
+		serverError(w, err)
+		return false
+	}
+	pages := max((count+size-1)/size, 1)
+	if number > pages {
+		NotFoundHandler(w, r)
+		return false
+	}
+	if page.Blogs, err = blog.GetPublishedBlogs(filter, number, size); err != nil {
+		serverError(w, err)
+		return false
+	}
+	page.Pagination = &Pagination{Page: number, Pages: pages}
+	if number > 1 {
+		page.Pagination.Prev = pageURL(r, number-1)
+	}
+	if number < pages {
+		page.Pagination.Next = pageURL(r, number+1)
+	}
+	if page.Menus, err = loadMenus(); err != nil {
+		serverError(w, err)
+		return false
+	}
+	return true
+}
+
+// loadMenus loads the menu trees of the locations in THEME_MENUS, keyed by location. Locations
+// that do not exist are left out.
+func loadMenus() (map[string][]menu.MenuTree, error) {
+	keys := os.Getenv("THEME_MENUS")
+	if keys == "" {
+		keys = defaultMenus
+	}
+	menus := make(map[string][]menu.MenuTree)
+	for _, key := range strings.Split(keys, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		tree, err := menu.GetLocationTree(key, true)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		menus[key] = tree
+	}
+	return menus, nil
+}
+
+// Helper to build the URL of another page of the current list. Search results keep their query
+// parameters; archives use /page/{n} paths.
+func pageURL(r *http.Request, number int) string {
+	if r.URL.Query().Get("q") != "" {
+		query := r.URL.Query()
+		if number == 1 {
+			query.Del("page")
+		} else {
+			query.Set("page", strconv.Itoa(number))
+		}
+		u := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+		return u.String()
+	}
+	base := r.URL.Path
+	if i := strings.LastIndex(base, "/page/"); i >= 0 && mux.Vars(r)["page"] != "" {
+		base = base[:i]
+	}
+	base = strings.TrimSuffix(base, "/")
+	if number == 1 {
+		if base == "" {
+			return "/"
+		}
+		return base
+	}
+	return base + "/page/" + strconv.Itoa(number)
+}
+
+// Helper to log a failure and answer with a plain error page
+func serverError(w http.ResponseWriter, err error) {
+	log.Printf("Error rendering page: %v", err)
+	http.Error(w, "Something went wrong", http.StatusInternalServerError)
+}
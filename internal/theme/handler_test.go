@@ -0,0 +1,45 @@
+package theme
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAcceptsHTML(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept []string
+		want   bool
+	}{
+		{"browser", []string{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"}, true},
+		{"parameters", []string{"application/json, Text/HTML; q=0.5"}, true},
+		{"several headers", []string{"application/json", "text/html"}, true},
+		{"json", []string{"application/json"}, false},
+		{"anything", []string{"*/*"}, false},
+		{"none", nil, false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/missing", nil)
+		for _, accept := range tt.accept {
+			r.Header.Add("Accept", accept)
+		}
+		if got := acceptsHTML(r); got != tt.want {
+			t.Errorf("%s: acceptsHTML = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNotFoundHandlerAPI(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/blogs/missing/extra", nil)
+	r.Header.Set("Accept", "application/json")
+	NotFoundHandler(w, r)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("Content-Type = %q, want JSON", ct)
+	}
+}
@@ -0,0 +1,46 @@
+package theme
+
+import (
+	"cms-project/internal/blog"
+	"cms-project/internal/category"
+	"cms-project/internal/menu"
+	"cms-project/internal/tag"
+	"html/template"
+)
+
+// Page is the data every page template receives
+type Page struct {
+	Site       SiteInfo
+	Title      string
+	Head       template.HTML // extra markup for the head, such as the meta tags of a blog
+	Menus      map[string][]menu.MenuTree
+	Blog       *BlogView
+	Blogs      []blog.PublishedBlog
+	Category   *category.Category
+	Tag        *tag.Tag
+	Query      string
+	Pagination *Pagination
+	Breadcrumb []blog.Breadcrumb
+}
+
+// SiteInfo describes the site in templates
+type SiteInfo struct {
+	Name        string
+	Description string
+	URL         string
+}
+
+// BlogView is a published blog with its sanitized content ready to be rendered
+type BlogView struct {
+	blog.PublishedBlog
+	Content  template.HTML
+	TagLinks []tag.Tag // the tags with their slugs, which can differ from what their names suggest
+}
+
+// Pagination links the pages of a list
+type Pagination struct {
+	Page  int
+	Pages int
+	Prev  string // empty on the first page
+	Next  string // empty on the last page
+}
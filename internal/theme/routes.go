@@ -0,0 +1,22 @@
+package theme
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterThemeRoutes registers the public HTML pages at the root of the site. Unknown paths get the
+// 404 page when a browser asks for HTML and a JSON error otherwise, since the API shares the root.
+func RegisterThemeRoutes(r *mux.Router) {
+	r.HandleFunc("/", HomeHandler).Methods("GET", "HEAD")
+	r.HandleFunc("/page/{page:[0-9]+}", HomeHandler).Methods("GET", "HEAD")
+	r.HandleFunc("/blog/{slug}", BlogHandler).Methods("GET", "HEAD")
+	r.HandleFunc("/category/{slug}", CategoryHandler).Methods("GET", "HEAD")
+	r.HandleFunc("/category/{slug}/page/{page:[0-9]+}", CategoryHandler).Methods("GET", "HEAD")
+	r.HandleFunc("/tag/{slug}", TagHandler).Methods("GET", "HEAD")
+	r.HandleFunc("/tag/{slug}/page/{page:[0-9]+}", TagHandler).Methods("GET", "HEAD")
+	r.HandleFunc("/search", SearchHandler).Methods("GET", "HEAD")
+	r.PathPrefix("/assets/").Handler(AssetsHandler()).Methods("GET", "HEAD")
+	r.NotFoundHandler = http.HandlerFunc(NotFoundHandler)
+}
@@ -0,0 +1,172 @@
+package theme
+
+import (
+	"bytes"
+	"cms-project/internal/site"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+//go:embed default
+var embedded embed.FS
+
+// pages lists the page templates a theme provides under templates/pages
+var pages = []string{"home", "blog", "category", "tag", "search", "404"}
+
+var (
+	mu        sync.RWMutex
+	files     fs.FS
+	templates map[string]*template.Template
+)
+
+// Enabled reports whether public pages are rendered, set with THEME_ENABLED
+func Enabled() bool {
+	return os.Getenv("THEME_ENABLED") == "true"
+}
+
+// Init loads the theme from THEME_DIR. Files missing there, or all of them without THEME_DIR,
+// come from the embedded default theme.
+func Init() {
+	if !Enabled() {
+		return
+	}
+	defaults, err := fs.Sub(embedded, "default")
+	if err != nil {
+		log.Fatalf("Failed to load default theme: %v", err)
+	}
+	themeFiles := defaults
+	if dir := os.Getenv("THEME_DIR"); dir != "" {
+		themeFiles = overlay{primary: os.DirFS(dir), fallback: defaults}
+	}
+	parsed, err := parse(themeFiles)
+	if err != nil {
+		log.Fatalf("Failed to load theme: %v", err)
+	}
+	mu.Lock()
+	files, templates = themeFiles, parsed
+	mu.Unlock()
+}
+
+// Assets returns the static files of the theme, served under /assets/
+func Assets() fs.FS {
+	mu.RLock()
+	defer mu.RUnlock()
+	assets, err := fs.Sub(files, "assets")
+	if err != nil {
+		log.Fatalf("Failed to load theme assets: %v", err)
+	}
+	return assets
+}
+
+// parse parses every page together with the layout and partials it is rendered in
+func parse(fsys fs.FS) (map[string]*template.Template, error) {
+	parsed := make(map[string]*template.Template, len(pages))
+	for _, name := range pages {
+		t, err := template.New(name).Funcs(funcs).ParseFS(fsys,
+			"templates/layout.html", "templates/partials/*.html", "templates/pages/"+name+".html")
+		if err != nil {
+			return nil, err
+		}
+		parsed[name] = t
+	}
+	return parsed, nil
+}
+
+// render writes a page with the given status. With THEME_RELOAD set templates are parsed on every
+// request, so theme changes show without a restart.
+func render(w http.ResponseWriter, status int, name string, page *Page) {
+	mu.RLock()
+	t := templates[name]
+	fsys := files
+	mu.RUnlock()
+	if os.Getenv("THEME_RELOAD") == "true" {
+		parsed, err := parse(fsys)
+		if err != nil {
+			log.Printf("Error reloading theme: %v", err)
+			http.Error(w, "Failed to load theme", http.StatusInternalServerError)
+			return
+		}
+		t = parsed[name]
+	}
+
+	page.Site = SiteInfo{Name: site.Name(), Description: site.Description(), URL: site.URL("/")}
+	var buf bytes.Buffer
+	if err := t.ExecuteTemplate(&buf, "layout", page); err != nil {
+		log.Printf("Error rendering %s page: %v", name, err)
+		http.Error(w, "Failed to render page", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
+
+// funcs are the helpers available to theme templates
+var funcs = template.FuncMap{
+	"blogPath":     site.BlogPath,
+	"categoryPath": site.CategoryPath,
+	"tagPath":      site.TagPath,
+	"date": func(t time.Time) string {
+		return t.Format("January 2, 2006")
+	},
+	"iso": func(t time.Time) string {
+		return t.UTC().Format(time.RFC3339)
+	},
+	"plural": func(n int, singular, plural string) string {
+		if n == 1 {
+			return fmt.Sprintf("%d %s", n, singular)
+		}
+		return fmt.Sprintf("%d %s", n, plural)
+	},
+}
+
+// overlay serves files from primary and falls back to fallback for files primary lacks
+type overlay struct {
+	primary  fs.FS
+	fallback fs.FS
+}
+
+func (o overlay) Open(name string) (fs.File, error) {
+	f, err := o.primary.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return o.fallback.Open(name)
+	}
+	return f, err
+}
+
+// ReadDir merges the entries of both file systems so that globs see files from either
+func (o overlay) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries := map[string]fs.DirEntry{}
+	found := false
+	for _, fsys := range []fs.FS{o.fallback, o.primary} {
+		list, err := fs.ReadDir(fsys, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found = true
+		for _, e := range list {
+			entries[e.Name()] = e
+		}
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	merged := make([]fs.DirEntry, 0, len(entries))
+	for _, e := range entries {
+		merged = append(merged, e)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Name() < merged[j].Name() })
+	return merged, nil
+}
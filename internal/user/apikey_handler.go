@@ -0,0 +1,123 @@
+package user
+
+import (
+	"cms-project/pkg/response"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// GetAPIKeysHandler handles retrieving all API keys
+// @Summary Get all API keys
+// @Description Retrieve all API keys; secrets are never returned
+// @Tags APIKey
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /api-keys [get]
+func GetAPIKeysHandler(w http.ResponseWriter, r *http.Request) {
+	keys, err := GetAPIKeys()
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to fetch API keys", nil)
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "API keys retrieved successfully", keys)
+}
+
+// CreateAPIKeyHandler handles creating a new API key
+// @Summary Create an API key
+// @Description Create a scoped API key; the returned key is shown only once
+// @Tags APIKey
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param key body user.CreateAPIKeyRequest true "API key data"
+// @Success 201 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /api-keys [post]
+func CreateAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	var req CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid JSON input", nil)
+		return
+	}
+
+	key, err := CreateAPIKey(FromContext(r.Context()), req)
+	if err != nil {
+		if errors.Is(err, ErrInvalidAPIKeyRequest) {
+			response.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
+			return
+		}
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to create API key", nil)
+		return
+	}
+	response.JSON(w, http.StatusCreated, true, "API key created successfully; store it now, it will not be shown again", key)
+}
+
+// RotateAPIKeyHandler handles replacing the secret of an API key
+// @Summary Rotate an API key
+// @Description Issue a new secret for a key; the previous secret stops working immediately
+// @Tags APIKey
+// @Security BearerAuth
+// @Param id path string true "API key ID"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /api-keys/{id}/rotate [post]
+func RotateAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid API key ID format", nil)
+		return
+	}
+
+	key, err := RotateAPIKey(id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.JSON(w, http.StatusNotFound, false, "API key not found", nil)
+			return
+		}
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to rotate API key", nil)
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "API key rotated successfully; store it now, it will not be shown again", key)
+}
+
+// RevokeAPIKeyHandler handles revoking an API key
+// @Summary Revoke an API key
+// @Description Permanently disable an API key
+// @Tags APIKey
+// @Security BearerAuth
+// @Param id path string true "API key ID"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /api-keys/{id} [delete]
+func RevokeAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid API key ID format", nil)
+		return
+	}
+
+	if err := RevokeAPIKey(id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.JSON(w, http.StatusNotFound, false, "API key not found", nil)
+			return
+		}
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to revoke API key", nil)
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "API key revoked successfully", nil)
+}
@@ -0,0 +1,72 @@
+package user
+
+import (
+	"net"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// CreateAPIKeyRequest represents the required fields for creating an API key
+type CreateAPIKeyRequest struct {
+	Name       string     `json:"name" example:"Static site build"`
+	Scopes     []string   `json:"scopes" example:"blogs:read,menus:write"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" example:"2030-01-01T00:00:00Z"`
+	AllowedIPs []string   `json:"allowed_ips,omitempty" example:"203.0.113.0/24"` // IPs or CIDR ranges; empty allows any
+}
+
+// APIKey represents a key used by machine clients; only a hash of the secret is stored
+type APIKey struct {
+	ID         uuid.UUID      `db:"id" json:"id"`
+	Name       string         `db:"name" json:"name"`
+	Prefix     string         `db:"prefix" json:"prefix"`
+	KeyHash    string         `db:"key_hash" json:"-"`
+	Scopes     pq.StringArray `db:"scopes" json:"scopes" swaggertype:"array,string"`
+	AllowedIPs pq.StringArray `db:"allowed_ips" json:"allowed_ips" swaggertype:"array,string"`
+	ExpiresAt  *time.Time     `db:"expires_at" json:"expires_at,omitempty"`
+	LastUsedAt *time.Time     `db:"last_used_at" json:"last_used_at,omitempty"`
+	LastUsedIP *string        `db:"last_used_ip" json:"last_used_ip,omitempty"`
+	RevokedAt  *time.Time     `db:"revoked_at" json:"revoked_at,omitempty"`
+	CreatedBy  uuid.UUID      `db:"created_by" json:"created_by"`
+	CreatedAt  time.Time      `db:"created_at" json:"created_at"`
+}
+
+// IssuedAPIKey is returned when a key is created or rotated; the secret is shown only once
+type IssuedAPIKey struct {
+	APIKey
+	Key string `json:"key" example:"cms_1a2b3c4d_..."`
+}
+
+// HasScope reports whether the key was granted the permission
+func (k *APIKey) HasScope(perm Permission) bool {
+	for _, s := range k.Scopes {
+		if s == string(perm) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsIP reports whether the key may be used from ip
+func (k *APIKey) AllowsIP(ip string) bool {
+	if len(k.AllowedIPs) == 0 {
+		return true
+	}
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, allowed := range k.AllowedIPs {
+		if _, network, err := net.ParseCIDR(allowed); err == nil {
+			if network.Contains(addr) {
+				return true
+			}
+			continue
+		}
+		if other := net.ParseIP(allowed); other != nil && other.Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
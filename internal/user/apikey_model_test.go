@@ -0,0 +1,53 @@
+package user
+
+import "testing"
+
+func TestAPIKeyHasScope(t *testing.T) {
+	key := APIKey{Scopes: []string{string(PermBlogsRead), string(PermMenusWrite)}}
+	tests := []struct {
+		perm Permission
+		want bool
+	}{
+		{PermBlogsRead, true},
+		{PermMenusWrite, true},
+		{PermBlogsCreate, false},
+		{Permission("blogs"), false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := key.HasScope(tt.perm); got != tt.want {
+			t.Errorf("HasScope(%q) = %v, want %v", tt.perm, got, tt.want)
+		}
+	}
+	if (&APIKey{}).HasScope(PermBlogsRead) {
+		t.Error("a key without scopes has blogs:read")
+	}
+}
+
+func TestAPIKeyAllowsIP(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		ip      string
+		want    bool
+	}{
+		{"no restriction", nil, "203.0.113.7", true},
+		{"no restriction with invalid IP", nil, "not-an-ip", true},
+		{"exact IP", []string{"203.0.113.7"}, "203.0.113.7", true},
+		{"other IP", []string{"203.0.113.7"}, "203.0.113.8", false},
+		{"inside CIDR", []string{"198.51.100.0/24"}, "198.51.100.200", true},
+		{"outside CIDR", []string{"198.51.100.0/24"}, "198.51.101.1", false},
+		{"any of several", []string{"203.0.113.7", "198.51.100.0/24"}, "198.51.100.1", true},
+		{"IPv6 CIDR", []string{"2001:db8::/32"}, "2001:db8::1", true},
+		{"IPv4-mapped IPv6", []string{"203.0.113.7"}, "::ffff:203.0.113.7", true},
+		{"invalid IP", []string{"203.0.113.0/24"}, "not-an-ip", false},
+		{"empty IP", []string{"203.0.113.0/24"}, "", false},
+		{"invalid allow entry ignored", []string{"garbage", "203.0.113.7"}, "203.0.113.7", true},
+	}
+	for _, tt := range tests {
+		key := APIKey{AllowedIPs: tt.allowed}
+		if got := key.AllowsIP(tt.ip); got != tt.want {
+			t.Errorf("%s: AllowsIP(%q) = %v, want %v", tt.name, tt.ip, got, tt.want)
+		}
+	}
+}
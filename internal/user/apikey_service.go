@@ -0,0 +1,183 @@
+package user
+
+import (
+	"cms-project/internal/database"
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const apiKeyPrefix = "cms_"
+
+var (
+	// ErrInvalidAPIKey is returned for unknown, revoked, expired or IP-restricted keys
+	ErrInvalidAPIKey = errors.New("invalid API key")
+	// ErrInvalidAPIKeyRequest is returned when a key cannot be created as requested
+	ErrInvalidAPIKeyRequest = errors.New("invalid API key request")
+)
+
+// CreateAPIKey stores a new key for the given owner and returns its secret
+func CreateAPIKey(owner *User, req CreateAPIKeyRequest) (*IssuedAPIKey, error) {
+	if err := validateAPIKeyRequest(req); err != nil {
+		return nil, err
+	}
+
+	prefix, secret, err := generateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	key := APIKey{
+		ID:         uuid.New(),
+		Name:       strings.TrimSpace(req.Name),
+		Prefix:     prefix,
+		KeyHash:    hashToken(secret),
+		Scopes:     req.Scopes,
+		AllowedIPs: req.AllowedIPs,
+		ExpiresAt:  req.ExpiresAt,
+		CreatedBy:  owner.ID,
+	}
+	if key.AllowedIPs == nil {
+		key.AllowedIPs = pq.StringArray{}
+	}
+
+	query := `
+		INSERT INTO api_keys (id, name, prefix, key_hash, scopes, allowed_ips, expires_at, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+		RETURNING created_at`
+	err = database.DB.QueryRow(query, key.ID, key.Name, key.Prefix, key.KeyHash, key.Scopes, key.AllowedIPs, key.ExpiresAt, key.CreatedBy).Scan(&key.CreatedAt)
+	if err != nil {
+		log.Printf("Error creating API key: %v", err)
+		return nil, err
+	}
+	return &IssuedAPIKey{APIKey: key, Key: secret}, nil
+}
+
+// GetAPIKeys retrieves all API keys
+func GetAPIKeys() ([]APIKey, error) {
+	var keys []APIKey
+	query := "SELECT * FROM api_keys ORDER BY created_at DESC"
+	err := database.DB.Select(&keys, query)
+	if err != nil {
+		log.Printf("Error fetching API keys: %v", err)
+		return nil, err
+	}
+	return keys, nil
+}
+
+// RotateAPIKey replaces the secret of an active key; the old secret stops working immediately
+func RotateAPIKey(id uuid.UUID) (*IssuedAPIKey, error) {
+	prefix, secret, err := generateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	var key APIKey
+	query := `
+		UPDATE api_keys SET prefix = $1, key_hash = $2
+		WHERE id = $3 AND revoked_at IS NULL
+		RETURNING *`
+	err = database.DB.Get(&key, query, prefix, hashToken(secret), id)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("Error rotating API key: %v", err)
+		}
+		return nil, err
+	}
+	return &IssuedAPIKey{APIKey: key, Key: secret}, nil
+}
+
+// RevokeAPIKey disables a key permanently
+func RevokeAPIKey(id uuid.UUID) error {
+	query := "UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL"
+	result, err := database.DB.Exec(query, id)
+	if err != nil {
+		log.Printf("Error revoking API key: %v", err)
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// AuthenticateAPIKey checks a presented key and records its use. It returns the key and its owner.
+func AuthenticateAPIKey(raw, ip string) (*APIKey, *User, error) {
+	prefix, ok := parseAPIKeyPrefix(raw)
+	if !ok {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	var key APIKey
+	if err := database.DB.Get(&key, "SELECT * FROM api_keys WHERE prefix = $1", prefix); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, ErrInvalidAPIKey
+		}
+		log.Printf("Error fetching API key: %v", err)
+		return nil, nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(hashToken(raw))) != 1 {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	if key.RevokedAt != nil || (key.ExpiresAt != nil && time.Now().After(*key.ExpiresAt)) || !key.AllowsIP(ip) {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	owner, err := GetUserByID(key.CreatedBy)
+	if err != nil {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	if _, err := database.DB.Exec("UPDATE api_keys SET last_used_at = NOW(), last_used_ip = $1 WHERE id = $2", ip, key.ID); err != nil {
+		log.Printf("Error recording API key use: %v", err)
+	}
+	return &key, owner, nil
+}
+
+func validateAPIKeyRequest(req CreateAPIKeyRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidAPIKeyRequest)
+	}
+	if len(req.Scopes) == 0 {
+		return fmt.Errorf("%w: at least one scope is required", ErrInvalidAPIKeyRequest)
+	}
+	for _, scope := range req.Scopes {
+		if !validPermission(Permission(scope)) {
+			return fmt.Errorf("%w: unknown scope %q", ErrInvalidAPIKeyRequest, scope)
+		}
+	}
+	for _, allowed := range req.AllowedIPs {
+		if _, _, err := net.ParseCIDR(allowed); err != nil && net.ParseIP(allowed) == nil {
+			return fmt.Errorf("%w: %q is not an IP address or CIDR range", ErrInvalidAPIKeyRequest, allowed)
+		}
+	}
+	return nil
+}
+
+// generateAPIKey returns a random lookup prefix and the full key "cms_<prefix>_<secret>"
+func generateAPIKey() (string, string, error) {
+	buf := make([]byte, 36)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	prefix := hex.EncodeToString(buf[:4])
+	return prefix, apiKeyPrefix + prefix + "_" + base64.RawURLEncoding.EncodeToString(buf[4:]), nil
+}
+
+func parseAPIKeyPrefix(raw string) (string, bool) {
+	if !strings.HasPrefix(raw, apiKeyPrefix) {
+		return "", false
+	}
+	prefix, _, ok := strings.Cut(strings.TrimPrefix(raw, apiKeyPrefix), "_")
+	return prefix, ok && len(prefix) == 8
+}
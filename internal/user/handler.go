@@ -0,0 +1,246 @@
+package user
+
+import (
+	"cms-project/pkg/response"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// RegisterHandler handles creating a new account
+// @Summary Register
+// @Description Create a new user account
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param user body user.RegisterRequest true "Account data"
+// @Success 201 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /auth/register [post]
+func RegisterHandler(w http.ResponseWriter, r *http.Request) {
+	var req RegisterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid JSON input", nil)
+		return
+	}
+
+	user, err := Register(req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidEmail), errors.Is(err, ErrWeakPassword), errors.Is(err, ErrPasswordTooLong):
+			response.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
+		case errors.Is(err, ErrEmailTaken):
+			response.JSON(w, http.StatusConflict, false, err.Error(), nil)
+		default:
+			response.JSON(w, http.StatusInternalServerError, false, "Failed to register user", nil)
+		}
+		return
+	}
+	response.JSON(w, http.StatusCreated, true, "User registered successfully", user)
+}
+
+// LoginHandler handles exchanging credentials for tokens
+// @Summary Log in
+// @Description Exchange email and password for an access token and a refresh token
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param credentials body user.LoginRequest true "Credentials"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /auth/login [post]
+func LoginHandler(w http.ResponseWriter, r *http.Request) {
+	var req LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid JSON input", nil)
+		return
+	}
+
+	user, err := Authenticate(req.Email, req.Password)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCredentials) {
+			response.JSON(w, http.StatusUnauthorized, false, err.Error(), nil)
+			return
+		}
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to log in", nil)
+		return
+	}
+
+	tokens, err := IssueTokens(user)
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to issue tokens", nil)
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "Logged in successfully", tokens)
+}
+
+// RefreshHandler handles rotating a refresh token
+// @Summary Refresh tokens
+// @Description Exchange a refresh token for a new token pair; the presented refresh token is revoked
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param token body user.RefreshRequest true "Refresh token"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /auth/refresh [post]
+func RefreshHandler(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		response.JSON(w, http.StatusBadRequest, false, "Refresh token is required", nil)
+		return
+	}
+
+	tokens, err := RefreshTokens(req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, ErrInvalidToken) {
+			response.JSON(w, http.StatusUnauthorized, false, "Invalid or expired refresh token", nil)
+			return
+		}
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to refresh tokens", nil)
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "Tokens refreshed successfully", tokens)
+}
+
+// LogoutHandler handles ending the current session
+// @Summary Log out
+// @Description Revoke the current access token and, when given, the refresh token family
+// @Tags Auth
+// @Security BearerAuth
+// @Param token body user.RefreshRequest false "Refresh token"
+// @Success 200 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /auth/logout [post]
+func LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	// The body is optional; a missing refresh token only skips its revocation
+	json.NewDecoder(r.Body).Decode(&req)
+
+	if req.RefreshToken != "" {
+		if err := RevokeRefreshToken(req.RefreshToken); err != nil {
+			response.JSON(w, http.StatusInternalServerError, false, "Failed to revoke refresh token", nil)
+			return
+		}
+	}
+	if claims := claimsFromContext(r.Context()); claims != nil {
+		if err := RevokeAccessToken(claims); err != nil {
+			response.JSON(w, http.StatusInternalServerError, false, "Failed to revoke access token", nil)
+			return
+		}
+	}
+	response.JSON(w, http.StatusOK, true, "Logged out successfully", nil)
+}
+
+// RevokeHandler handles revoking a refresh token
+// @Summary Revoke a refresh token
+// @Description Revoke a refresh token and every token rotated from the same login
+// @Tags Auth
+// @Accept json
+// @Param token body user.RefreshRequest true "Refresh token"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /auth/revoke [post]
+func RevokeHandler(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		response.JSON(w, http.StatusBadRequest, false, "Refresh token is required", nil)
+		return
+	}
+	if err := RevokeRefreshToken(req.RefreshToken); err != nil {
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to revoke refresh token", nil)
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "Token revoked successfully", nil)
+}
+
+// MeHandler handles retrieving the authenticated user
+// @Summary Current user
+// @Description Retrieve the account the access token belongs to
+// @Tags Auth
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Router /auth/me [get]
+func MeHandler(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, http.StatusOK, true, "User retrieved successfully", FromContext(r.Context()))
+}
+
+// GetUsersHandler handles retrieving all users
+// @Summary Get all users
+// @Description Retrieve all user accounts with pagination
+// @Tags User
+// @Security BearerAuth
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of users per page"
+// @Success 200 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /users [get]
+func GetUsersHandler(w http.ResponseWriter, r *http.Request) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	users, err := GetUsers(page, limit)
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to fetch users", nil)
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "Users retrieved successfully", users)
+}
+
+// UpdateUserRoleHandler handles assigning a role to a user
+// @Summary Assign a role
+// @Description Set the role of a user to admin, editor, author or viewer
+// @Tags User
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param role body user.UpdateRoleRequest true "Role"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /users/{id}/role [put]
+func UpdateUserRoleHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid user ID format", nil)
+		return
+	}
+
+	var req UpdateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || !ValidRole(req.Role) {
+		response.JSON(w, http.StatusBadRequest, false, "Role must be one of admin, editor, author or viewer", nil)
+		return
+	}
+
+	if err := UpdateUserRole(id, req.Role); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.JSON(w, http.StatusNotFound, false, "User not found", nil)
+			return
+		}
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to update user role", nil)
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "User role updated successfully", nil)
+}
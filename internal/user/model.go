@@ -0,0 +1,60 @@
+package user
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RegisterRequest represents the required fields for creating an account
+type RegisterRequest struct {
+	Email    string `json:"email" example:"ayse@example.com"`
+	Name     string `json:"name" example:"Ayşe Yılmaz"`
+	Password string `json:"password" example:"correct horse battery staple"`
+}
+
+// LoginRequest represents the credentials used to log in
+type LoginRequest struct {
+	Email    string `json:"email" example:"ayse@example.com"`
+	Password string `json:"password" example:"correct horse battery staple"`
+}
+
+// RefreshRequest carries a refresh token to rotate or revoke
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" example:"kM4x...Q"`
+}
+
+// User represents a registered account
+type User struct {
+	ID           uuid.UUID `db:"id" json:"id"`
+	Email        string    `db:"email" json:"email"`
+	Name         string    `db:"name" json:"name"`
+	PasswordHash string    `db:"password_hash" json:"-"`
+	Role         string    `db:"role" json:"role" example:"author"` // admin, editor, author, viewer
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// UpdateRoleRequest represents the role assigned to a user
+type UpdateRoleRequest struct {
+	Role string `json:"role" example:"editor"`
+}
+
+// TokenPair is returned on login and refresh
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type" example:"Bearer"`
+	ExpiresIn    int    `json:"expires_in" example:"900"` // access token lifetime in seconds
+}
+
+// refreshToken is a stored refresh token; only the hash of the token is kept
+type refreshToken struct {
+	ID        uuid.UUID  `db:"id"`
+	UserID    uuid.UUID  `db:"user_id"`
+	FamilyID  uuid.UUID  `db:"family_id"`
+	TokenHash string     `db:"token_hash"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	RevokedAt *time.Time `db:"revoked_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
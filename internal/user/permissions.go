@@ -0,0 +1,95 @@
+package user
+
+import "context"
+
+// Roles a user can hold
+const (
+	RoleAdmin  = "admin"
+	RoleEditor = "editor"
+	RoleAuthor = "author"
+	RoleViewer = "viewer"
+)
+
+// Permission names an action on the management API
+type Permission string
+
+// Permissions checked by the management API
+const (
+	PermBlogsRead        Permission = "blogs:read" // read blogs in every workflow state, not only published ones
+	PermBlogsCreate      Permission = "blogs:create"
+	PermBlogsEditOwn     Permission = "blogs:edit_own" // edit own drafts
+	PermBlogsEdit        Permission = "blogs:edit"     // edit any blog in any state
+	PermBlogsPublish     Permission = "blogs:publish"
+	PermBlogsDeleteOwn   Permission = "blogs:delete_own" // delete own drafts
+	PermBlogsDelete      Permission = "blogs:delete"
+	PermBlogsBreakLock   Permission = "blogs:break_lock"
+	PermCategoriesWrite  Permission = "categories:write"
+	PermMenusWrite       Permission = "menus:write"
+	PermTagsManage       Permission = "tags:manage" // rename and merge; tags are created through blogs
+	PermCommentsModerate Permission = "comments:moderate"
+	PermMediaRead        Permission = "media:read"   // list media and their metadata; files are public
+	PermMediaUpload      Permission = "media:upload" // upload and describe media
+	PermMediaDelete      Permission = "media:delete"
+	PermUsersManage      Permission = "users:manage"
+	PermAPIKeysManage    Permission = "api_keys:manage"
+	PermAuditRead        Permission = "audit:read"
+	PermWebhooksManage   Permission = "webhooks:manage"
+)
+
+// readPermissions are granted to every role. Categories and menus have no unpublished state and
+// are readable without authentication, so they have no read permission.
+var readPermissions = []Permission{PermBlogsRead, PermMediaRead}
+
+// rolePermissions declares what each role may do
+var rolePermissions = map[string][]Permission{
+	RoleAdmin: {
+		PermBlogsCreate, PermBlogsEditOwn, PermBlogsEdit, PermBlogsPublish, PermBlogsDeleteOwn, PermBlogsDelete,
+		PermBlogsBreakLock, PermCategoriesWrite, PermMenusWrite, PermTagsManage, PermCommentsModerate, PermMediaUpload,
+		PermMediaDelete, PermUsersManage, PermAPIKeysManage, PermAuditRead, PermWebhooksManage,
+	},
+	RoleEditor: {
+		PermBlogsCreate, PermBlogsEditOwn, PermBlogsEdit, PermBlogsPublish, PermBlogsDeleteOwn, PermBlogsDelete,
+		PermCommentsModerate, PermMediaUpload, PermMediaDelete,
+	},
+	RoleAuthor: {
+		PermBlogsCreate, PermBlogsEditOwn, PermBlogsDeleteOwn, PermMediaUpload,
+	},
+	RoleViewer: {},
+}
+
+// ValidRole reports whether role is one of the known roles
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// RoleHas reports whether role grants the permission
+func RoleHas(role string, perm Permission) bool {
+	if _, ok := rolePermissions[role]; !ok {
+		return false
+	}
+	for _, p := range append(readPermissions, rolePermissions[role]...) {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPermission reports whether the authenticated principal of ctx holds the permission.
+// Requests made with an API key are limited to the key's scopes within its owner's role.
+func HasPermission(ctx context.Context, perm Permission) bool {
+	user := FromContext(ctx)
+	if user == nil || !RoleHas(user.Role, perm) {
+		return false
+	}
+	if key := APIKeyFromContext(ctx); key != nil {
+		return key.HasScope(perm)
+	}
+	return true
+}
+
+// validPermission reports whether perm is granted by any role
+func validPermission(perm Permission) bool {
+	return RoleHas(RoleAdmin, perm)
+}
@@ -0,0 +1,76 @@
+package user
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRoleHas(t *testing.T) {
+	tests := []struct {
+		role string
+		perm Permission
+		want bool
+	}{
+		{RoleAdmin, PermCategoriesWrite, true},
+		{RoleAdmin, PermMenusWrite, true},
+		{RoleAdmin, PermUsersManage, true},
+		{RoleAdmin, PermWebhooksManage, true},
+		{RoleEditor, PermBlogsPublish, true},
+		{RoleEditor, PermBlogsEdit, true},
+		{RoleEditor, PermBlogsDelete, true},
+		{RoleEditor, PermCategoriesWrite, false},
+		{RoleEditor, PermMenusWrite, false},
+		{RoleEditor, PermUsersManage, false},
+		{RoleAuthor, PermBlogsCreate, true},
+		{RoleAuthor, PermBlogsEditOwn, true},
+		{RoleAuthor, PermBlogsDeleteOwn, true},
+		{RoleAuthor, PermBlogsEdit, false},
+		{RoleAuthor, PermBlogsPublish, false},
+		{RoleAuthor, PermBlogsDelete, false},
+		{RoleAuthor, PermCategoriesWrite, false},
+		{RoleAuthor, PermMenusWrite, false},
+		{RoleViewer, PermBlogsRead, true},
+		{RoleViewer, PermMediaRead, true},
+		{RoleViewer, PermMediaUpload, false},
+		{RoleViewer, PermBlogsCreate, false},
+		{RoleViewer, PermCategoriesWrite, false},
+		{"unknown", PermBlogsRead, false},
+		{"", PermBlogsRead, false},
+	}
+	for _, tt := range tests {
+		if got := RoleHas(tt.role, tt.perm); got != tt.want {
+			t.Errorf("RoleHas(%q, %q) = %v, want %v", tt.role, tt.perm, got, tt.want)
+		}
+	}
+}
+
+func TestForbiddenNamesPermission(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Forbidden(rec, PermBlogsPublish)
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+	var body struct {
+		Success bool   `json:"success"`
+		Message string `json:"message"`
+		Data    struct {
+			MissingPermission Permission `json:"missing_permission"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Success {
+		t.Error("success = true, want false")
+	}
+	if !strings.Contains(body.Message, string(PermBlogsPublish)) {
+		t.Errorf("message %q does not name %q", body.Message, PermBlogsPublish)
+	}
+	if body.Data.MissingPermission != PermBlogsPublish {
+		t.Errorf("missing_permission = %q, want %q", body.Data.MissingPermission, PermBlogsPublish)
+	}
+}
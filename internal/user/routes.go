@@ -0,0 +1,29 @@
+package user
+
+import (
+	"github.com/gorilla/mux"
+)
+
+// RegisterAuthRoutes registers all authentication routes
+func RegisterAuthRoutes(r *mux.Router) {
+	r.HandleFunc("/register", RegisterHandler).Methods("POST")
+	r.HandleFunc("/login", LoginHandler).Methods("POST")
+	r.HandleFunc("/refresh", RefreshHandler).Methods("POST")
+	r.HandleFunc("/revoke", RevokeHandler).Methods("POST")
+	r.Handle("/logout", RequireAuthFunc(LogoutHandler)).Methods("POST")
+	r.Handle("/me", RequireAuthFunc(MeHandler)).Methods("GET")
+}
+
+// RegisterUserRoutes registers all user management routes
+func RegisterUserRoutes(r *mux.Router) {
+	r.Handle("", Require(PermUsersManage, GetUsersHandler)).Methods("GET")
+	r.Handle("/{id:[a-fA-F0-9-]+}/role", Require(PermUsersManage, UpdateUserRoleHandler)).Methods("PUT")
+}
+
+// RegisterAPIKeyRoutes registers all API key management routes
+func RegisterAPIKeyRoutes(r *mux.Router) {
+	r.Handle("", Require(PermAPIKeysManage, GetAPIKeysHandler)).Methods("GET")
+	r.Handle("", Require(PermAPIKeysManage, CreateAPIKeyHandler)).Methods("POST")
+	r.Handle("/{id:[a-fA-F0-9-]+}/rotate", Require(PermAPIKeysManage, RotateAPIKeyHandler)).Methods("POST")
+	r.Handle("/{id:[a-fA-F0-9-]+}", Require(PermAPIKeysManage, RevokeAPIKeyHandler)).Methods("DELETE")
+}
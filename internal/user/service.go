@@ -0,0 +1,151 @@
+package user
+
+import (
+	"cms-project/internal/database"
+	"database/sql"
+	"errors"
+	"log"
+	"net/mail"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	minPasswordLength = 8
+	maxPasswordLength = 72 // bcrypt only hashes the first 72 bytes and refuses longer passwords
+)
+
+var (
+	// ErrInvalidCredentials is returned when the email or password does not match
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	// ErrEmailTaken is returned when registering an email that already has an account
+	ErrEmailTaken = errors.New("email is already registered")
+	// ErrInvalidEmail is returned for malformed email addresses
+	ErrInvalidEmail = errors.New("email address is invalid")
+	// ErrWeakPassword is returned when the password is too short
+	ErrWeakPassword = errors.New("password must be at least 8 characters")
+	// ErrPasswordTooLong is returned when the password is longer than bcrypt accepts
+	ErrPasswordTooLong = errors.New("password must be at most 72 bytes")
+)
+
+// dummyHash is compared against when no user matches, so unknown emails take as long as wrong passwords
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("not-a-real-password"), bcrypt.DefaultCost)
+
+// Register creates a new account with a bcrypt hashed password
+func Register(req RegisterRequest) (*User, error) {
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if _, err := mail.ParseAddress(email); err != nil {
+		return nil, ErrInvalidEmail
+	}
+	if len(req.Password) < minPasswordLength {
+		return nil, ErrWeakPassword
+	}
+	if len(req.Password) > maxPasswordLength {
+		return nil, ErrPasswordTooLong
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		log.Printf("Error hashing password: %v", err)
+		return nil, err
+	}
+
+	user := User{
+		ID:           uuid.New(),
+		Email:        email,
+		Name:         strings.TrimSpace(req.Name),
+		PasswordHash: string(hash),
+		Role:         RoleViewer, // new accounts are read-only until an admin assigns a role
+	}
+	query := "INSERT INTO users (id, email, name, password_hash, role, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, NOW(), NOW()) RETURNING created_at, updated_at"
+	err = database.DB.QueryRow(query, user.ID, user.Email, user.Name, user.PasswordHash, user.Role).Scan(&user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return nil, ErrEmailTaken
+		}
+		log.Printf("Error creating user: %v", err)
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Authenticate checks an email and password pair and returns the matching user
+func Authenticate(email, password string) (*User, error) {
+	var user User
+	query := "SELECT * FROM users WHERE email = $1"
+	err := database.DB.Get(&user, query, strings.ToLower(strings.TrimSpace(email)))
+	if errors.Is(err, sql.ErrNoRows) {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		log.Printf("Error fetching user by email: %v", err)
+		return nil, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return &user, nil
+}
+
+// GetUserByID retrieves a single user by ID
+func GetUserByID(id uuid.UUID) (*User, error) {
+	var user User
+	query := "SELECT * FROM users WHERE id = $1"
+	err := database.DB.Get(&user, query, id)
+	if err != nil {
+		log.Printf("Error fetching user by ID: %v", err)
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetUsersByIDs retrieves the users with the given IDs in a single query
+func GetUsersByIDs(ids []uuid.UUID) ([]User, error) {
+	var users []User
+	if len(ids) == 0 {
+		return users, nil
+	}
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, id.String())
+	}
+	query := "SELECT * FROM users WHERE id = ANY($1)"
+	err := database.DB.Select(&users, query, pq.Array(keys))
+	if err != nil {
+		log.Printf("Error fetching users by IDs: %v", err)
+		return nil, err
+	}
+	return users, nil
+}
+
+// GetUsers retrieves all users
+func GetUsers(page, limit int) ([]User, error) {
+	var users []User
+	offset := (page - 1) * limit
+	query := "SELECT * FROM users ORDER BY created_at DESC LIMIT $1 OFFSET $2"
+	err := database.DB.Select(&users, query, limit, offset)
+	if err != nil {
+		log.Printf("Error fetching users: %v", err)
+		return nil, err
+	}
+	return users, nil
+}
+
+// UpdateUserRole assigns a role to a user
+func UpdateUserRole(id uuid.UUID, role string) error {
+	query := "UPDATE users SET role = $1, updated_at = NOW() WHERE id = $2"
+	result, err := database.DB.Exec(query, role, id)
+	if err != nil {
+		log.Printf("Error updating user role: %v", err)
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package user
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRegisterValidatesPassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		want     error
+	}{
+		{"too short", "1234567", ErrWeakPassword},
+		{"too long for bcrypt", strings.Repeat("a", 73), ErrPasswordTooLong},
+		{"multi-byte characters count as bytes", strings.Repeat("ş", 37), ErrPasswordTooLong},
+	}
+	for _, tt := range tests {
+		_, err := Register(RegisterRequest{Email: "reader@example.com", Password: tt.password})
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
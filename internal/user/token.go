@@ -0,0 +1,207 @@
+package user
+
+import (
+	"cms-project/internal/database"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const issuer = "cms-project"
+
+// ErrInvalidToken is returned for expired, revoked or malformed tokens
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+var (
+	jwtSecret       []byte
+	accessTokenTTL  = 15 * time.Minute
+	refreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// Claims are the JWT claims carried by access tokens
+type Claims struct {
+	jwt.RegisteredClaims
+}
+
+// InitAuth loads the token settings from environment variables
+func InitAuth() {
+	secret := os.Getenv("JWT_SECRET")
+	if len(secret) < 32 {
+		log.Fatal("JWT_SECRET must be set to at least 32 characters")
+	}
+	jwtSecret = []byte(secret)
+
+	accessTokenTTL = durationFromEnv("ACCESS_TOKEN_TTL", accessTokenTTL)
+	refreshTokenTTL = durationFromEnv("REFRESH_TOKEN_TTL", refreshTokenTTL)
+}
+
+// IssueTokens creates a short-lived access token and starts a new refresh token family
+func IssueTokens(user *User) (*TokenPair, error) {
+	return issueTokens(database.DB, user.ID, uuid.New())
+}
+
+// RefreshTokens rotates a refresh token: the presented token is revoked and a new pair is issued
+// in the same family. Presenting an already rotated token revokes the whole family.
+func RefreshTokens(raw string) (*TokenPair, error) {
+	tx, err := database.DB.Beginx()
+	if err != nil {
+		log.Printf("Error starting refresh transaction: %v", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var token refreshToken
+	query := "SELECT * FROM refresh_tokens WHERE token_hash = $1 FOR UPDATE"
+	if err := tx.Get(&token, query, hashToken(raw)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidToken
+		}
+		log.Printf("Error fetching refresh token: %v", err)
+		return nil, err
+	}
+
+	if token.RevokedAt != nil {
+		// A rotated token was replayed; assume it leaked and end the whole session
+		log.Printf("Refresh token reuse detected for user %s", token.UserID)
+		if _, err := tx.Exec("UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL", token.FamilyID); err != nil {
+			log.Printf("Error revoking refresh token family: %v", err)
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidToken
+	}
+	if time.Now().After(token.ExpiresAt) {
+		return nil, ErrInvalidToken
+	}
+
+	if _, err := tx.Exec("UPDATE refresh_tokens SET revoked_at = NOW() WHERE id = $1", token.ID); err != nil {
+		log.Printf("Error rotating refresh token: %v", err)
+		return nil, err
+	}
+	pair, err := issueTokens(tx, token.UserID, token.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Error committing refresh token rotation: %v", err)
+		return nil, err
+	}
+	return pair, nil
+}
+
+// RevokeRefreshToken revokes the refresh token family the given token belongs to
+func RevokeRefreshToken(raw string) error {
+	query := `
+		UPDATE refresh_tokens SET revoked_at = NOW()
+		WHERE revoked_at IS NULL
+		AND family_id = (SELECT family_id FROM refresh_tokens WHERE token_hash = $1)`
+	_, err := database.DB.Exec(query, hashToken(raw))
+	if err != nil {
+		log.Printf("Error revoking refresh token: %v", err)
+		return err
+	}
+	return nil
+}
+
+// RevokeAccessToken denies an access token until it expires on its own
+func RevokeAccessToken(claims *Claims) error {
+	if _, err := database.DB.Exec("DELETE FROM revoked_tokens WHERE expires_at < NOW()"); err != nil {
+		log.Printf("Error pruning revoked tokens: %v", err)
+	}
+	query := "INSERT INTO revoked_tokens (jti, expires_at) VALUES ($1, $2) ON CONFLICT DO NOTHING"
+	_, err := database.DB.Exec(query, claims.ID, claims.ExpiresAt.Time)
+	if err != nil {
+		log.Printf("Error revoking access token: %v", err)
+		return err
+	}
+	return nil
+}
+
+// ParseAccessToken verifies an access token and checks it has not been revoked
+func ParseAccessToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		return jwtSecret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithIssuer(issuer), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	var revoked bool
+	if err := database.DB.Get(&revoked, "SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)", claims.ID); err != nil {
+		log.Printf("Error checking token revocation: %v", err)
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func issueTokens(db execer, userID, familyID uuid.UUID) (*TokenPair, error) {
+	now := time.Now()
+	claims := Claims{RegisteredClaims: jwt.RegisteredClaims{
+		ID:        uuid.NewString(),
+		Subject:   userID.String(),
+		Issuer:    issuer,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(accessTokenTTL)),
+	}}
+	access, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(jwtSecret)
+	if err != nil {
+		log.Printf("Error signing access token: %v", err)
+		return nil, err
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	refresh := base64.RawURLEncoding.EncodeToString(buf)
+
+	query := "INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, expires_at, created_at) VALUES ($1, $2, $3, $4, $5, NOW())"
+	if _, err := db.Exec(query, uuid.New(), userID, familyID, hashToken(refresh), now.Add(refreshTokenTTL)); err != nil {
+		log.Printf("Error storing refresh token: %v", err)
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(accessTokenTTL.Seconds()),
+	}, nil
+}
+
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+func durationFromEnv(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid %s %q, using %s", key, value, fallback)
+		return fallback
+	}
+	return d
+}
@@ -0,0 +1,309 @@
+package webhook
+
+import (
+	"bytes"
+	"cms-project/internal/database"
+	"cms-project/internal/events"
+	"cms-project/internal/outbox"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Delivery settings, overridable with WEBHOOK_MAX_ATTEMPTS, WEBHOOK_TIMEOUT, WEBHOOK_RETRY_BASE
+// and WEBHOOK_POLL
+const (
+	defaultMaxAttempts = 8
+	defaultTimeout     = 10 * time.Second
+	defaultRetryBase   = 30 * time.Second
+	defaultPoll        = 10 * time.Second
+	maxRetryDelay      = 6 * time.Hour
+	batchSize          = 20
+	maxResponseBody    = 1024
+)
+
+// job is a claimed delivery together with where and how to send it
+type job struct {
+	Delivery
+	URL    string `db:"url"`
+	Secret string `db:"secret"`
+}
+
+// ErrPrivateAddress is returned when a webhook URL resolves to an address of this host or its
+// private network
+var ErrPrivateAddress = errors.New("webhook receiver has a private address")
+
+var (
+	// wakeup tells the worker that deliveries are due without waiting for the next poll
+	wakeup = make(chan struct{}, 1)
+	client = newClient(durationEnv("WEBHOOK_TIMEOUT", defaultTimeout))
+)
+
+// newClient returns the HTTP client sending deliveries. Webhook URLs are chosen by users, so the
+// client connects to public addresses only and does not follow redirects, which keeps deliveries
+// from reaching internal services. The address is checked when connecting, after DNS resolution.
+// WEBHOOK_ALLOW_PRIVATE=true lifts the restriction for receivers on the local network.
+func newClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: checkAddress}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would be the address checked instead of the receiver
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// checkAddress refuses connections to loopback, link-local and private addresses
+func checkAddress(network, address string, _ syscall.RawConn) error {
+	if os.Getenv("WEBHOOK_ALLOW_PRIVATE") == "true" {
+		return nil
+	}
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !isPublic(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+// Helper to tell whether an address is reachable from the internet rather than local to this host
+// or network
+func isPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return !addr.IsLoopback() && !addr.IsLinkLocalUnicast() && !addr.IsLinkLocalMulticast() &&
+		!addr.IsPrivate() && !addr.IsUnspecified()
+}
+
+// Init registers webhooks as a sink of the outbox, which queues a delivery for every webhook
+// subscribed to an event, and starts the worker that sends them. Deliveries are stored before
+// they are sent, so they survive restarts and are shared between instances.
+func Init() {
+	outbox.AddSink(Sink{})
+	go work()
+}
+
+// Sink queues webhook deliveries for the events dispatched from the outbox
+type Sink struct{}
+
+func (Sink) Name() string { return "webhooks" }
+
+// Send queues the deliveries of an event
+func (Sink) Send(ctx context.Context, e events.Event) error {
+	return Enqueue(e)
+}
+
+// Enqueue stores a delivery of an event for every active webhook subscribed to it. An event the
+// outbox sends again is queued only once per webhook.
+func Enqueue(e events.Event) error {
+	var hooks []Webhook
+	if err := database.DB.Select(&hooks, "SELECT * FROM webhooks WHERE active"); err != nil {
+		log.Printf("Error fetching active webhooks: %v", err)
+		return err
+	}
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	queued := 0
+	for _, h := range hooks {
+		if !h.Matches(e.Type) {
+			continue
+		}
+		query := `
+			INSERT INTO webhook_deliveries (webhook_id, event_type, payload)
+			SELECT $1::uuid, $2::text, $3::jsonb
+			WHERE $4::text = '' OR NOT EXISTS (SELECT 1 FROM webhook_deliveries WHERE webhook_id = $1 AND payload->>'id' = $4)`
+		if _, err := database.DB.Exec(query, h.ID, e.Type, payload, e.ID); err != nil {
+			log.Printf("Error queueing webhook delivery: %v", err)
+			return err
+		}
+		queued++
+	}
+	if queued > 0 {
+		wake()
+	}
+	return nil
+}
+
+// Sign returns the signature sent in the X-Webhook-Signature header: the hex encoded
+// HMAC-SHA256 of the timestamp, a dot and the body, keyed with the webhook secret. Receivers
+// should compute it the same way and compare in constant time.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// work sends due deliveries whenever it is woken up and every WEBHOOK_POLL
+func work() {
+	ticker := time.NewTicker(durationEnv("WEBHOOK_POLL", defaultPoll))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-wakeup:
+		case <-ticker.C:
+		}
+		for {
+			jobs, err := claim()
+			if err != nil || len(jobs) == 0 {
+				break
+			}
+			var wg sync.WaitGroup
+			for _, j := range jobs {
+				wg.Add(1)
+				go func(j job) {
+					defer wg.Done()
+					deliver(j)
+				}(j)
+			}
+			wg.Wait()
+		}
+	}
+}
+
+// claim picks due deliveries of active webhooks and pushes their next attempt past the time a
+// send may take, so that other instances skip them while they are in flight
+func claim() ([]job, error) {
+	var jobs []job
+	lease := 2 * client.Timeout
+	query := `
+		WITH claimed AS (
+			UPDATE webhook_deliveries SET next_attempt_at = NOW() + $1 * INTERVAL '1 millisecond'
+			WHERE id IN (
+				SELECT d.id FROM webhook_deliveries d
+				JOIN webhooks w ON w.id = d.webhook_id AND w.active
+				WHERE d.status = 'pending' AND d.next_attempt_at <= NOW()
+				ORDER BY d.next_attempt_at
+				LIMIT $2
+				FOR UPDATE OF d SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT c.*, w.url, w.secret FROM claimed c JOIN webhooks w ON w.id = c.webhook_id`
+	if err := database.DB.Select(&jobs, query, lease.Milliseconds(), batchSize); err != nil {
+		log.Printf("Error claiming webhook deliveries: %v", err)
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// deliver sends a delivery and records the outcome, scheduling a retry with exponential backoff
+// after a failure until the attempts run out
+func deliver(j job) {
+	code, body, err := send(j)
+	attempts := j.Attempts + 1
+	if err == nil {
+		query := `
+			UPDATE webhook_deliveries SET status = $1, attempts = $2, response_code = $3, response_body = $4,
+				error = '', delivered_at = NOW()
+			WHERE id = $5`
+		if _, err := database.DB.Exec(query, StatusDelivered, attempts, code, body, j.ID); err != nil {
+			log.Printf("Error recording webhook delivery: %v", err)
+		}
+		return
+	}
+
+	status := StatusPending
+	if attempts >= intEnv("WEBHOOK_MAX_ATTEMPTS", defaultMaxAttempts) {
+		status = StatusDead
+		log.Printf("Webhook delivery %d to %s failed %d times, giving up: %v", j.ID, j.URL, attempts, err)
+	}
+	var responseCode *int
+	if code != 0 {
+		responseCode = &code
+	}
+	query := `
+		UPDATE webhook_deliveries SET status = $1, attempts = $2, response_code = $3, response_body = $4,
+			error = $5, next_attempt_at = NOW() + $6 * INTERVAL '1 millisecond'
+		WHERE id = $7`
+	_, dbErr := database.DB.Exec(query, status, attempts, responseCode, body, err.Error(), backoff(attempts).Milliseconds(), j.ID)
+	if dbErr != nil {
+		log.Printf("Error recording webhook delivery: %v", dbErr)
+	}
+}
+
+// send posts the payload of a delivery, signed with the webhook secret. Any status other than
+// 2xx is an error; the status and the start of the response body are returned either way.
+func send(j job) (int, string, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req, err := http.NewRequest(http.MethodPost, j.URL, bytes.NewReader(j.Payload))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "cms-webhooks/1.0")
+	req.Header.Set("X-Webhook-ID", strconv.FormatInt(j.ID, 10))
+	req.Header.Set("X-Webhook-Event", j.EventType)
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", Sign(j.Secret, timestamp, j.Payload))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, string(body), fmt.Errorf("receiver responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, string(body), nil
+}
+
+// backoff returns how long to wait before the next attempt: WEBHOOK_RETRY_BASE doubled after
+// every failed attempt, capped at six hours, with up to 20% jitter so that retries of many
+// deliveries to a receiver that was down do not arrive all at once
+func backoff(attempts int) time.Duration {
+	delay := durationEnv("WEBHOOK_RETRY_BASE", defaultRetryBase)
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, maxRetryDelay)
+	return delay + rand.N(delay/5+1)
+}
+
+// Helper to wake up the worker without blocking when it is already awake
+func wake() {
+	select {
+	case wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// Helper to read a duration setting with a default
+func durationEnv(key string, fallback time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(key)); err == nil && d > 0 {
+		return d
+	}
+	return fallback
+}
+
+// Helper to read a numeric setting with a default
+func intEnv(key string, fallback int) int {
+	if n, err := strconv.Atoi(os.Getenv(key)); err == nil && n > 0 {
+		return n
+	}
+	return fallback
+}
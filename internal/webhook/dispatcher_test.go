@@ -0,0 +1,256 @@
+package webhook
+
+import (
+	"cms-project/internal/database/dbtest"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+// receiver is a webhook endpoint that answers with a fixed status and keeps the last request
+type receiver struct {
+	*httptest.Server
+	status int
+	header http.Header
+	body   []byte
+}
+
+func newReceiver(t *testing.T, status int) *receiver {
+	// The test server listens on the loopback interface
+	t.Setenv("WEBHOOK_ALLOW_PRIVATE", "true")
+	rcv := &receiver{status: status}
+	rcv.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rcv.header = r.Header.Clone()
+		rcv.body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(rcv.status)
+		io.WriteString(w, http.StatusText(rcv.status))
+	}))
+	t.Cleanup(rcv.Close)
+	return rcv
+}
+
+// between matches a numeric query argument within an inclusive range
+type between struct{ min, max int64 }
+
+func (b between) Match(v driver.Value) bool {
+	n, ok := v.(int64)
+	return ok && n >= b.min && n <= b.max
+}
+
+// retryDelay matches the backoff in milliseconds after a number of attempts, including jitter
+func retryDelay(base time.Duration, attempts int) between {
+	delay := base << (attempts - 1)
+	return between{delay.Milliseconds(), (delay + delay/5).Milliseconds()}
+}
+
+func testJob(url string, attempts int) job {
+	return job{
+		Delivery: Delivery{ID: 7, EventType: "blog.published", Payload: []byte(`{"id":"42","type":"blog.published"}`), Attempts: attempts},
+		URL:      url,
+		Secret:   "s3cret",
+	}
+}
+
+func TestSendSignsPayload(t *testing.T) {
+	rcv := newReceiver(t, http.StatusOK)
+	j := testJob(rcv.URL, 0)
+
+	if _, _, err := send(j); err != nil {
+		t.Fatal(err)
+	}
+	timestamp := rcv.header.Get("X-Webhook-Timestamp")
+	if timestamp == "" {
+		t.Fatal("X-Webhook-Timestamp header missing")
+	}
+	signature := rcv.header.Get("X-Webhook-Signature")
+	if want := Sign(j.Secret, timestamp, rcv.body); signature != want {
+		t.Errorf("X-Webhook-Signature = %q, want %q", signature, want)
+	}
+
+	// Receivers verify the HMAC-SHA256 of the timestamp, a dot and the raw body
+	mac := hmac.New(sha256.New, []byte(j.Secret))
+	mac.Write([]byte(timestamp + "." + string(rcv.body)))
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); signature != want {
+		t.Errorf("X-Webhook-Signature = %q, receiver computes %q", signature, want)
+	}
+	if got := rcv.header.Get("X-Webhook-Event"); got != j.EventType {
+		t.Errorf("X-Webhook-Event = %q, want %q", got, j.EventType)
+	}
+	if got := rcv.header.Get("X-Webhook-ID"); got != "7" {
+		t.Errorf("X-Webhook-ID = %q, want %q", got, "7")
+	}
+}
+
+func TestSendRejectsPrivateAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("delivery reached a loopback receiver")
+	}))
+	defer srv.Close()
+
+	if _, _, err := send(testJob(srv.URL, 0)); !errors.Is(err, ErrPrivateAddress) {
+		t.Errorf("send error = %v, want ErrPrivateAddress", err)
+	}
+}
+
+func TestSendDoesNotFollowRedirects(t *testing.T) {
+	target := newReceiver(t, http.StatusOK)
+	redirect := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+	defer redirect.Close()
+
+	status, _, err := send(testJob(redirect.URL, 0))
+	if err == nil || status != http.StatusTemporaryRedirect {
+		t.Errorf("send = %d, %v, want the redirect reported as a failure", status, err)
+	}
+	if target.body != nil {
+		t.Error("redirect was followed")
+	}
+}
+
+func TestIsPublic(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"0.0.0.0", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"fd00::1", false},
+		{"::ffff:127.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := isPublic(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("isPublic(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestDeliverRecordsSuccess(t *testing.T) {
+	rcv := newReceiver(t, http.StatusNoContent)
+	mock := dbtest.Mock(t)
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE webhook_deliveries SET status = $1")).
+		WithArgs(StatusDelivered, 1, http.StatusNoContent, "", 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	deliver(testJob(rcv.URL, 0))
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDeliverSchedulesRetry(t *testing.T) {
+	t.Setenv("WEBHOOK_RETRY_BASE", "1m")
+	rcv := newReceiver(t, http.StatusServiceUnavailable)
+	mock := dbtest.Mock(t)
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE webhook_deliveries SET status = $1")).
+		WithArgs(StatusPending, 3, http.StatusServiceUnavailable, "Service Unavailable",
+			"receiver responded with status 503", retryDelay(time.Minute, 3), 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	deliver(testJob(rcv.URL, 2))
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDeliverGoesDeadAfterMaxAttempts(t *testing.T) {
+	t.Setenv("WEBHOOK_MAX_ATTEMPTS", "3")
+	t.Setenv("WEBHOOK_RETRY_BASE", "1m")
+	rcv := newReceiver(t, http.StatusInternalServerError)
+	mock := dbtest.Mock(t)
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE webhook_deliveries SET status = $1")).
+		WithArgs(StatusDead, 3, http.StatusInternalServerError, "Internal Server Error",
+			"receiver responded with status 500", retryDelay(time.Minute, 3), 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	deliver(testJob(rcv.URL, 2))
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	t.Setenv("WEBHOOK_RETRY_BASE", "30s")
+	for attempts := 1; attempts <= 12; attempts++ {
+		delay := min(30*time.Second<<(attempts-1), maxRetryDelay)
+		got := backoff(attempts)
+		if got < delay || got > delay+delay/5 {
+			t.Errorf("backoff(%d) = %v, want between %v and %v", attempts, got, delay, delay+delay/5)
+		}
+	}
+}
+
+func TestRedeliverCopiesPayloadIntoNewDelivery(t *testing.T) {
+	mock := dbtest.Mock(t)
+	webhookID := uuid.New()
+	// Only the payload is copied, so status, attempts and response start over from their defaults,
+	// and the delivery must belong to the webhook in the URL
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO webhook_deliveries (webhook_id, event_type, payload) "+
+		"SELECT webhook_id, event_type, payload FROM webhook_deliveries WHERE id = $1 AND webhook_id = $2 RETURNING *")).
+		WithArgs(int64(7), webhookID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(8))
+
+	if _, err := Redeliver(webhookID, 7); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRedeliverUnknownDelivery(t *testing.T) {
+	mock := dbtest.Mock(t)
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO webhook_deliveries")).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	if _, err := Redeliver(uuid.New(), 7); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Redeliver error = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		events    []string
+		eventType string
+		want      bool
+	}{
+		{[]string{"*"}, "blog.published", true},
+		{[]string{"*"}, "menu.updated", true},
+		{[]string{"blog.*"}, "blog.published", true},
+		{[]string{"blog.*"}, "blog.deleted", true},
+		{[]string{"blog.*"}, "category.created", false},
+		{[]string{"blog.*"}, "blogroll.updated", false},
+		{[]string{"blog.published"}, "blog.published", true},
+		{[]string{"blog.published"}, "blog.archived", false},
+		{[]string{"category.created", "menu.*"}, "menu.deleted", true},
+		{[]string{"category.created", "menu.*"}, "category.deleted", false},
+		{nil, "blog.published", false},
+	}
+	for _, tt := range tests {
+		h := Webhook{Events: tt.events}
+		if got := h.Matches(tt.eventType); got != tt.want {
+			t.Errorf("%v matches %q = %v, want %v", tt.events, tt.eventType, got, tt.want)
+		}
+	}
+}
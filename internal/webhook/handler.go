@@ -0,0 +1,256 @@
+package webhook
+
+import (
+	"cms-project/internal/user"
+	"cms-project/pkg/response"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// GetWebhooksHandler handles retrieving all webhooks
+// @Summary Get all webhooks
+// @Description Retrieve all webhook subscriptions; secrets are never returned
+// @Tags Webhook
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /webhooks [get]
+func GetWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	hooks, err := GetWebhooks()
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to fetch webhooks", nil)
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "Webhooks retrieved successfully", hooks)
+}
+
+// CreateWebhookHandler handles creating a new webhook
+// @Summary Create a webhook
+// @Description Subscribe a URL to content events. Payloads are signed with HMAC-SHA256 using the returned secret, which is shown only once.
+// @Tags Webhook
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param webhook body webhook.CreateWebhookRequest true "Webhook data"
+// @Success 201 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /webhooks [post]
+func CreateWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	var req CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid JSON input", nil)
+		return
+	}
+
+	var createdBy *uuid.UUID
+	if u := user.FromContext(r.Context()); u != nil {
+		createdBy = &u.ID
+	}
+	hook, err := CreateWebhook(createdBy, req)
+	if err != nil {
+		if errors.Is(err, ErrInvalidWebhook) {
+			response.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
+			return
+		}
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to create webhook", nil)
+		return
+	}
+	response.JSON(w, http.StatusCreated, true, "Webhook created successfully; store the secret now, it will not be shown again", hook)
+}
+
+// GetWebhookByIDHandler handles retrieving a single webhook
+// @Summary Get a webhook by ID
+// @Description Retrieve a webhook subscription by its ID
+// @Tags Webhook
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /webhooks/{id} [get]
+func GetWebhookByIDHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+	hook, err := GetWebhookByID(id)
+	if err != nil {
+		notFoundOrError(w, err, "Failed to fetch webhook")
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "Webhook retrieved successfully", hook)
+}
+
+// UpdateWebhookHandler handles updating a webhook
+// @Summary Update a webhook
+// @Description Replace the URL and event filter of a webhook, or pause and resume it with active. Deliveries queued while it was paused are sent once it is active again.
+// @Tags Webhook
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param webhook body webhook.CreateWebhookRequest true "Webhook data"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /webhooks/{id} [put]
+func UpdateWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+	var req CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid JSON input", nil)
+		return
+	}
+
+	hook, err := UpdateWebhook(id, req)
+	if err != nil {
+		if errors.Is(err, ErrInvalidWebhook) {
+			response.JSON(w, http.StatusBadRequest, false, err.Error(), nil)
+			return
+		}
+		notFoundOrError(w, err, "Failed to update webhook")
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "Webhook updated successfully", hook)
+}
+
+// DeleteWebhookHandler handles deleting a webhook
+// @Summary Delete a webhook
+// @Description Remove a webhook subscription together with its delivery log
+// @Tags Webhook
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /webhooks/{id} [delete]
+func DeleteWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+	if err := DeleteWebhook(id); err != nil {
+		notFoundOrError(w, err, "Failed to delete webhook")
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "Webhook deleted successfully", nil)
+}
+
+// GetDeliveriesHandler handles retrieving the delivery log of a webhook
+// @Summary Get webhook deliveries
+// @Description Retrieve the deliveries of a webhook with their status, number of attempts and last response, newest first
+// @Tags Webhook
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Param status query string false "Status (pending, delivered, dead)"
+// @Param page query int false "Page number"
+// @Param limit query int false "Number of deliveries per page"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /webhooks/{id}/deliveries [get]
+func GetDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+	q := r.URL.Query()
+	filter := DeliveryFilter{WebhookID: id, Status: q.Get("status")}
+	switch filter.Status {
+	case "", StatusPending, StatusDelivered, StatusDead:
+	default:
+		response.JSON(w, http.StatusBadRequest, false, "Invalid status, expected pending, delivered or dead", nil)
+		return
+	}
+
+	var err error
+	filter.Page, err = strconv.Atoi(q.Get("page"))
+	if err != nil || filter.Page < 1 {
+		filter.Page = 1
+	}
+	filter.Limit, err = strconv.Atoi(q.Get("limit"))
+	if err != nil || filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+
+	deliveries, err := GetDeliveries(filter)
+	if err != nil {
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to fetch webhook deliveries", nil)
+		return
+	}
+	response.JSON(w, http.StatusOK, true, "Webhook deliveries retrieved successfully", deliveries)
+}
+
+// RedeliverHandler handles sending a delivery again
+// @Summary Redeliver a webhook delivery
+// @Description Queue a new delivery with the payload of an earlier one, for example after a dead delivery once the receiver is fixed
+// @Tags Webhook
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Param delivery path int true "Delivery ID"
+// @Success 202 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /webhooks/{id}/deliveries/{delivery}/redeliver [post]
+func RedeliverHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+	deliveryID, err := strconv.ParseInt(mux.Vars(r)["delivery"], 10, 64)
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid delivery ID format", nil)
+		return
+	}
+
+	delivery, err := Redeliver(id, deliveryID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			response.JSON(w, http.StatusNotFound, false, "Delivery not found", nil)
+			return
+		}
+		response.JSON(w, http.StatusInternalServerError, false, "Failed to redeliver webhook", nil)
+		return
+	}
+	response.JSON(w, http.StatusAccepted, true, "Delivery queued successfully", delivery)
+}
+
+// Helper to parse the webhook ID of a request, answering 400 when it is malformed
+func webhookID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		response.JSON(w, http.StatusBadRequest, false, "Invalid webhook ID format", nil)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// Helper to answer 404 for missing webhooks and 500 for other errors
+func notFoundOrError(w http.ResponseWriter, err error, msg string) {
+	if errors.Is(err, sql.ErrNoRows) {
+		response.JSON(w, http.StatusNotFound, false, "Webhook not found", nil)
+		return
+	}
+	response.JSON(w, http.StatusInternalServerError, false, msg, nil)
+}
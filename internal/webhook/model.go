@@ -0,0 +1,79 @@
+package webhook
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx/types"
+	"github.com/lib/pq"
+)
+
+// Delivery statuses
+const (
+	StatusPending   = "pending"   // waiting for its first or next attempt
+	StatusDelivered = "delivered" // the receiver answered with a 2xx status
+	StatusDead      = "dead"      // every attempt failed; only a manual redelivery sends it again
+)
+
+// CreateWebhookRequest represents the fields of a webhook subscription
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" example:"https://example.com/hooks/cms"`
+	Events []string `json:"events" example:"blog.published,blog.deleted,menu.*"` // event types; "blog.*" matches every blog event and "*" every event
+	Active *bool    `json:"active,omitempty" example:"true"`                     // defaults to true
+}
+
+// Webhook represents a subscription of an external URL to content events
+type Webhook struct {
+	ID        uuid.UUID      `db:"id" json:"id"`
+	URL       string         `db:"url" json:"url"`
+	Secret    string         `db:"secret" json:"-"`
+	Events    pq.StringArray `db:"events" json:"events" swaggertype:"array,string"`
+	Active    bool           `db:"active" json:"active"`
+	CreatedBy *uuid.UUID     `db:"created_by" json:"created_by,omitempty"`
+	CreatedAt time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+// CreatedWebhook is returned when a webhook is created; the signing secret is shown only once
+type CreatedWebhook struct {
+	Webhook
+	Secret string `json:"secret" example:"whsec_3f2a..."`
+}
+
+// Delivery represents an event sent, or still to be sent, to a webhook
+type Delivery struct {
+	ID            int64          `db:"id" json:"id"`
+	WebhookID     uuid.UUID      `db:"webhook_id" json:"webhook_id"`
+	EventType     string         `db:"event_type" json:"event_type" example:"blog.published"`
+	Payload       types.JSONText `db:"payload" json:"payload" swaggertype:"object"`
+	Status        string         `db:"status" json:"status" example:"delivered"`
+	Attempts      int            `db:"attempts" json:"attempts" example:"1"`
+	ResponseCode  *int           `db:"response_code" json:"response_code,omitempty" example:"200"`
+	ResponseBody  string         `db:"response_body" json:"response_body"`
+	Error         string         `db:"error" json:"error"`
+	NextAttemptAt time.Time      `db:"next_attempt_at" json:"next_attempt_at"`
+	DeliveredAt   *time.Time     `db:"delivered_at" json:"delivered_at,omitempty"`
+	CreatedAt     time.Time      `db:"created_at" json:"created_at"`
+}
+
+// DeliveryFilter narrows down the deliveries returned by GetDeliveries
+type DeliveryFilter struct {
+	WebhookID uuid.UUID
+	Status    string
+	Page      int
+	Limit     int
+}
+
+// Matches reports whether the webhook subscribes to an event type
+func (h *Webhook) Matches(eventType string) bool {
+	for _, pattern := range h.Events {
+		if pattern == "*" || pattern == eventType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasPrefix(eventType, prefix) {
+			return true
+		}
+	}
+	return false
+}
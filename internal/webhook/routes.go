@@ -0,0 +1,18 @@
+package webhook
+
+import (
+	"cms-project/internal/user"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterWebhookRoutes registers all webhook management routes
+func RegisterWebhookRoutes(r *mux.Router) {
+	r.Handle("", user.Require(user.PermWebhooksManage, GetWebhooksHandler)).Methods("GET")
+	r.Handle("", user.Require(user.PermWebhooksManage, CreateWebhookHandler)).Methods("POST")
+	r.Handle("/{id:[a-fA-F0-9-]+}", user.Require(user.PermWebhooksManage, GetWebhookByIDHandler)).Methods("GET")
+	r.Handle("/{id:[a-fA-F0-9-]+}", user.Require(user.PermWebhooksManage, UpdateWebhookHandler)).Methods("PUT")
+	r.Handle("/{id:[a-fA-F0-9-]+}", user.Require(user.PermWebhooksManage, DeleteWebhookHandler)).Methods("DELETE")
+	r.Handle("/{id:[a-fA-F0-9-]+}/deliveries", user.Require(user.PermWebhooksManage, GetDeliveriesHandler)).Methods("GET")
+	r.Handle("/{id:[a-fA-F0-9-]+}/deliveries/{delivery:[0-9]+}/redeliver", user.Require(user.PermWebhooksManage, RedeliverHandler)).Methods("POST")
+}
@@ -0,0 +1,169 @@
+package webhook
+
+import (
+	"cms-project/internal/database"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"regexp"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const secretPrefix = "whsec_"
+
+// ErrInvalidWebhook is returned when a webhook cannot be saved as requested
+var ErrInvalidWebhook = errors.New("invalid webhook")
+
+// eventPattern matches the event filters a webhook may subscribe to: an event type such as
+// blog.published, every event of an entity such as blog.*, or * for all events
+var eventPattern = regexp.MustCompile(`^(\*|[a-z_]+\.(\*|[a-z_]+))$`)
+
+// CreateWebhook stores a new webhook with a generated signing secret
+func CreateWebhook(createdBy *uuid.UUID, req CreateWebhookRequest) (*CreatedWebhook, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+	h := Webhook{
+		ID:        uuid.New(),
+		URL:       req.URL,
+		Secret:    secret,
+		Events:    req.Events,
+		Active:    req.Active == nil || *req.Active,
+		CreatedBy: createdBy,
+	}
+	query := `
+		INSERT INTO webhooks (id, url, secret, events, active, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+		RETURNING created_at, updated_at`
+	err = database.DB.QueryRow(query, h.ID, h.URL, h.Secret, h.Events, h.Active, h.CreatedBy).Scan(&h.CreatedAt, &h.UpdatedAt)
+	if err != nil {
+		log.Printf("Error creating webhook: %v", err)
+		return nil, err
+	}
+	return &CreatedWebhook{Webhook: h, Secret: secret}, nil
+}
+
+// GetWebhooks retrieves all webhooks
+func GetWebhooks() ([]Webhook, error) {
+	hooks := []Webhook{}
+	if err := database.DB.Select(&hooks, "SELECT * FROM webhooks ORDER BY created_at DESC"); err != nil {
+		log.Printf("Error fetching webhooks: %v", err)
+		return nil, err
+	}
+	return hooks, nil
+}
+
+// GetWebhookByID retrieves a single webhook by ID
+func GetWebhookByID(id uuid.UUID) (*Webhook, error) {
+	var h Webhook
+	if err := database.DB.Get(&h, "SELECT * FROM webhooks WHERE id = $1", id); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("Error fetching webhook by ID: %v", err)
+		}
+		return nil, err
+	}
+	return &h, nil
+}
+
+// UpdateWebhook replaces the URL, event filter and active flag of a webhook; the secret is kept
+func UpdateWebhook(id uuid.UUID, req CreateWebhookRequest) (*Webhook, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+	var h Webhook
+	query := `
+		UPDATE webhooks SET url = $1, events = $2, active = COALESCE($3, active), updated_at = NOW()
+		WHERE id = $4
+		RETURNING *`
+	if err := database.DB.Get(&h, query, req.URL, pq.StringArray(req.Events), req.Active, id); err != nil {
+		log.Printf("Error updating webhook: %v", err)
+		return nil, err
+	}
+	if h.Active {
+		wake()
+	}
+	return &h, nil
+}
+
+// DeleteWebhook removes a webhook together with its deliveries
+func DeleteWebhook(id uuid.UUID) error {
+	result, err := database.DB.Exec("DELETE FROM webhooks WHERE id = $1", id)
+	if err != nil {
+		log.Printf("Error deleting webhook: %v", err)
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetDeliveries retrieves the deliveries of a webhook, newest first
+func GetDeliveries(filter DeliveryFilter) ([]Delivery, error) {
+	deliveries := []Delivery{}
+	args := []interface{}{filter.WebhookID}
+	query := "SELECT * FROM webhook_deliveries WHERE webhook_id = $1"
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		query += " AND status = $2"
+	}
+	args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+	query += fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+	if err := database.DB.Select(&deliveries, query, args...); err != nil {
+		log.Printf("Error fetching webhook deliveries: %v", err)
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// Redeliver queues a new delivery of the payload of an earlier one, whatever its status. The
+// original is kept in the log.
+func Redeliver(webhookID uuid.UUID, deliveryID int64) (*Delivery, error) {
+	var d Delivery
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_type, payload)
+		SELECT webhook_id, event_type, payload FROM webhook_deliveries WHERE id = $1 AND webhook_id = $2
+		RETURNING *`
+	if err := database.DB.Get(&d, query, deliveryID, webhookID); err != nil {
+		log.Printf("Error redelivering webhook delivery: %v", err)
+		return nil, err
+	}
+	wake()
+	return &d, nil
+}
+
+// validate checks the URL and event filter of a webhook
+func validate(req CreateWebhookRequest) error {
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidWebhook)
+	}
+	if len(req.Events) == 0 {
+		return fmt.Errorf("%w: at least one event is required", ErrInvalidWebhook)
+	}
+	for _, e := range req.Events {
+		if !eventPattern.MatchString(e) {
+			return fmt.Errorf("%w: unknown event filter %q", ErrInvalidWebhook, e)
+		}
+	}
+	return nil
+}
+
+// Helper to generate a signing secret
+func generateSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return secretPrefix + hex.EncodeToString(b), nil
+}
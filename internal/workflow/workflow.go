@@ -0,0 +1,211 @@
+package workflow
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+)
+
+// Editorial states of a blog
+const (
+	Draft            = "draft"
+	InReview         = "in_review"
+	ChangesRequested = "changes_requested"
+	Approved         = "approved"
+	Published        = "published"
+	Archived         = "archived"
+)
+
+var (
+	// ErrUnknownState is returned for states that are not part of the workflow
+	ErrUnknownState = errors.New("unknown workflow state")
+	// ErrNoPublishedState is returned for workflows without the published state the site relies on
+	ErrNoPublishedState = errors.New("workflow has no published state")
+	// ErrUnreachableState is returned for states no transition leads to from the initial state
+	ErrUnreachableState = errors.New("workflow state cannot be reached from the initial state")
+	// ErrIllegalTransition is returned when no transition connects two states
+	ErrIllegalTransition = errors.New("illegal workflow transition")
+	// ErrRoleNotAllowed is returned when the transition exists but not for the given role
+	ErrRoleNotAllowed = errors.New("role may not perform this transition")
+	// ErrCommentRequired is returned when a transition needs a reviewer comment
+	ErrCommentRequired = errors.New("a comment is required for this transition")
+)
+
+// Transition is a permitted move between two states
+type Transition struct {
+	From           string   `json:"from" example:"in_review"`
+	To             string   `json:"to" example:"approved"`
+	Roles          []string `json:"roles" example:"editor,admin"`
+	RequireComment bool     `json:"require_comment,omitempty"`
+}
+
+// Config lists the states a blog can be in and how it may move between them
+type Config struct {
+	Initial     string       `json:"initial" example:"draft"`
+	States      []string     `json:"states"`
+	Editable    []string     `json:"editable"` // states in which authors may still edit their own posts
+	Transitions []Transition `json:"transitions"`
+}
+
+// DefaultConfig is used unless WORKFLOW_CONFIG points at a JSON file
+var DefaultConfig = Config{
+	Initial:  Draft,
+	States:   []string{Draft, InReview, ChangesRequested, Approved, Published, Archived},
+	Editable: []string{Draft, ChangesRequested},
+	Transitions: []Transition{
+		{From: Draft, To: InReview, Roles: []string{"author", "editor", "admin"}},
+		{From: InReview, To: Draft, Roles: []string{"author", "editor", "admin"}},
+		{From: InReview, To: ChangesRequested, Roles: []string{"editor", "admin"}, RequireComment: true},
+		{From: InReview, To: Approved, Roles: []string{"editor", "admin"}},
+		{From: ChangesRequested, To: InReview, Roles: []string{"author", "editor", "admin"}},
+		{From: Approved, To: Published, Roles: []string{"editor", "admin"}},
+		{From: Draft, To: Published, Roles: []string{"editor", "admin"}},
+		{From: Published, To: Draft, Roles: []string{"editor", "admin"}},
+		{From: Published, To: Archived, Roles: []string{"editor", "admin"}},
+		{From: Archived, To: Draft, Roles: []string{"editor", "admin"}},
+	},
+}
+
+var (
+	mu     sync.RWMutex
+	config = DefaultConfig
+)
+
+// Init loads the workflow from the JSON file named by WORKFLOW_CONFIG, if set
+func Init() {
+	path := os.Getenv("WORKFLOW_CONFIG")
+	if path == "" {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatalf("Failed to read workflow config: %v", err)
+	}
+	var c Config
+	if err := json.Unmarshal(data, &c); err != nil {
+		log.Fatalf("Failed to parse workflow config: %v", err)
+	}
+	if err := SetConfig(c); err != nil {
+		log.Fatalf("Invalid workflow config: %v", err)
+	}
+	log.Printf("Loaded workflow config from %s", path)
+}
+
+// SetConfig replaces the active workflow after checking it is consistent: every state it mentions
+// is declared, published is one of them and all of them can be reached from the initial state
+func SetConfig(c Config) error {
+	known := map[string]bool{}
+	for _, s := range c.States {
+		known[s] = true
+	}
+	if !known[Published] {
+		return ErrNoPublishedState
+	}
+	if !known[c.Initial] {
+		return fmt.Errorf("%w: initial state %q", ErrUnknownState, c.Initial)
+	}
+	for _, s := range c.Editable {
+		if !known[s] {
+			return fmt.Errorf("%w: editable state %q", ErrUnknownState, s)
+		}
+	}
+	for _, t := range c.Transitions {
+		if !known[t.From] || !known[t.To] {
+			return fmt.Errorf("%w: transition %s -> %s", ErrUnknownState, t.From, t.To)
+		}
+	}
+	reached := map[string]bool{c.Initial: true}
+	for queue := []string{c.Initial}; len(queue) > 0; queue = queue[1:] {
+		for _, t := range c.Transitions {
+			if t.From == queue[0] && !reached[t.To] {
+				reached[t.To] = true
+				queue = append(queue, t.To)
+			}
+		}
+	}
+	for _, s := range c.States {
+		if !reached[s] {
+			return fmt.Errorf("%w: %q", ErrUnreachableState, s)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	config = c
+	return nil
+}
+
+// Current returns the active workflow
+func Current() Config {
+	mu.RLock()
+	defer mu.RUnlock()
+	return config
+}
+
+// Initial returns the state new blogs start in
+func Initial() string {
+	return Current().Initial
+}
+
+// ValidState reports whether state is part of the workflow
+func ValidState(state string) bool {
+	for _, s := range Current().States {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+// Editable reports whether authors may still edit their own posts in state
+func Editable(state string) bool {
+	for _, s := range Current().Editable {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns nil when role may move a blog from one state to another with the given comment
+func Check(role, from, to, comment string) error {
+	if !ValidState(to) {
+		return fmt.Errorf("%w: %q", ErrUnknownState, to)
+	}
+	for _, t := range Current().Transitions {
+		if t.From != from || t.To != to {
+			continue
+		}
+		if !contains(t.Roles, role) {
+			return fmt.Errorf("%w: %s cannot move %s to %s", ErrRoleNotAllowed, role, from, to)
+		}
+		if t.RequireComment && comment == "" {
+			return ErrCommentRequired
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: %s to %s", ErrIllegalTransition, from, to)
+}
+
+// Available lists the transitions role may perform from state
+func Available(role, from string) []Transition {
+	var available []Transition
+	for _, t := range Current().Transitions {
+		if t.From == from && contains(t.Roles, role) {
+			available = append(available, t)
+		}
+	}
+	return available
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package workflow
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name                    string
+		role, from, to, comment string
+		want                    error
+	}{
+		{"author submits", "author", Draft, InReview, "", nil},
+		{"editor approves", "editor", InReview, Approved, "", nil},
+		{"admin publishes draft", "admin", Draft, Published, "", nil},
+		{"author publishes", "author", Approved, Published, "", ErrRoleNotAllowed},
+		{"changes without comment", "editor", InReview, ChangesRequested, "", ErrCommentRequired},
+		{"changes with comment", "editor", InReview, ChangesRequested, "Fix the intro", nil},
+		{"skips review", "editor", Draft, Approved, "", ErrIllegalTransition},
+		{"unknown target", "admin", Draft, "deleted", "", ErrUnknownState},
+	}
+	for _, tt := range tests {
+		err := Check(tt.role, tt.from, tt.to, tt.comment)
+		if tt.want == nil && err != nil || tt.want != nil && !errors.Is(err, tt.want) {
+			t.Errorf("%s: Check = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestAvailable(t *testing.T) {
+	tests := []struct {
+		role, from string
+		want       []string
+	}{
+		{"author", Draft, []string{InReview}},
+		{"editor", Draft, []string{InReview, Published}},
+		{"editor", InReview, []string{Draft, ChangesRequested, Approved}},
+		{"author", Published, nil},
+		{"editor", Published, []string{Draft, Archived}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, transition := range Available(tt.role, tt.from) {
+			got = append(got, transition.To)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Available(%s, %s) = %v, want %v", tt.role, tt.from, got, tt.want)
+		}
+	}
+}
+
+func TestEditable(t *testing.T) {
+	for state, want := range map[string]bool{
+		Draft:            true,
+		ChangesRequested: true,
+		InReview:         false,
+		Published:        false,
+		"unknown":        false,
+	} {
+		if got := Editable(state); got != want {
+			t.Errorf("Editable(%s) = %v, want %v", state, got, want)
+		}
+	}
+}
+
+func TestSetConfig(t *testing.T) {
+	t.Cleanup(func() { SetConfig(DefaultConfig) })
+
+	simple := func() Config {
+		return Config{
+			Initial:     Draft,
+			States:      []string{Draft, Published},
+			Editable:    []string{Draft},
+			Transitions: []Transition{{From: Draft, To: Published, Roles: []string{"editor"}}},
+		}
+	}
+	tests := []struct {
+		name   string
+		change func(*Config)
+		want   error
+	}{
+		{"valid", func(c *Config) {}, nil},
+		{"unknown initial", func(c *Config) { c.Initial = "new" }, ErrUnknownState},
+		{"unknown editable", func(c *Config) { c.Editable = []string{"new"} }, ErrUnknownState},
+		{"unknown transition target", func(c *Config) {
+			c.Transitions = append(c.Transitions, Transition{From: Published, To: Archived})
+		}, ErrUnknownState},
+		{"unreachable state", func(c *Config) { c.States = append(c.States, Archived) }, ErrUnreachableState},
+		{"unreachable published", func(c *Config) { c.Transitions = nil }, ErrUnreachableState},
+		{"missing published", func(c *Config) {
+			c.States = []string{Draft, Approved}
+			c.Transitions = []Transition{{From: Draft, To: Approved}}
+		}, ErrNoPublishedState},
+	}
+	for _, tt := range tests {
+		c := simple()
+		tt.change(&c)
+		err := SetConfig(c)
+		if tt.want == nil && err != nil || tt.want != nil && !errors.Is(err, tt.want) {
+			t.Errorf("%s: SetConfig = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+	if err := SetConfig(DefaultConfig); err != nil {
+		t.Errorf("default config: SetConfig = %v, want nil", err)
+	}
+}
@@ -0,0 +1,39 @@
+-- Slugs used to build public URLs for blogs and categories
+ALTER TABLE blogs ADD COLUMN IF NOT EXISTS slug TEXT NOT NULL DEFAULT '';
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS slug TEXT NOT NULL DEFAULT '';
+
+-- Backfill slugs of existing rows from their title or name the way pkg/slug does, falling back to
+-- the ID when nothing transliterable is left and numbering duplicates -2, -3, ... by age
+WITH base AS (
+    SELECT id, created_at, COALESCE(NULLIF(trim(BOTH '-' FROM regexp_replace(
+        lower(replace(translate(title, 'çğıöşüâîûéèàÇĞİÖŞÜÂÎÛÉÈÀ', 'cgiosuaiueeaCGIOSUAIUEEA'), 'ß', 'ss')),
+        '[^a-z0-9]+', '-', 'g')), ''), id::text) AS slug
+    FROM blogs WHERE slug = ''
+), numbered AS (
+    SELECT id, slug, row_number() OVER (PARTITION BY slug ORDER BY created_at, id) AS n FROM base
+)
+UPDATE blogs b SET slug = CASE WHEN n.n = 1 THEN n.slug ELSE n.slug || '-' || n.n END
+FROM numbered n WHERE b.id = n.id;
+
+WITH base AS (
+    SELECT id, created_at, COALESCE(NULLIF(trim(BOTH '-' FROM regexp_replace(
+        lower(replace(translate(name, 'çğıöşüâîûéèàÇĞİÖŞÜÂÎÛÉÈÀ', 'cgiosuaiueeaCGIOSUAIUEEA'), 'ß', 'ss')),
+        '[^a-z0-9]+', '-', 'g')), ''), id::text) AS slug
+    FROM categories WHERE slug = ''
+), numbered AS (
+    SELECT id, slug, row_number() OVER (PARTITION BY slug ORDER BY created_at, id) AS n FROM base
+)
+UPDATE categories c SET slug = CASE WHEN n.n = 1 THEN n.slug ELSE n.slug || '-' || n.n END
+FROM numbered n WHERE c.id = n.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS blogs_slug_key ON blogs (slug) WHERE slug <> '';
+CREATE UNIQUE INDEX IF NOT EXISTS categories_slug_key ON categories (slug) WHERE slug <> '';
+
+-- Typed link targets for menu items
+ALTER TABLE menus
+    ADD COLUMN IF NOT EXISTS link_type TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS blog_id UUID REFERENCES blogs (id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS category_id UUID REFERENCES categories (id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS link_url TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS target TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS rel TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,19 @@
+-- Named menu containers that own trees of menu items
+CREATE TABLE IF NOT EXISTS menu_locations (
+    id SERIAL PRIMARY KEY,
+    key TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE menus
+    ADD COLUMN IF NOT EXISTS location_id INT REFERENCES menu_locations (id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS position INT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS menus_location_position_idx ON menus (location_id, parent_id, position);
+
+INSERT INTO menu_locations (key, name) VALUES
+    ('header', 'Header'),
+    ('footer', 'Footer'),
+    ('sidebar', 'Sidebar')
+ON CONFLICT (key) DO NOTHING;
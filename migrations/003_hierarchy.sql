@@ -0,0 +1,3 @@
+-- Nested categories and a primary category per blog, used for breadcrumbs
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES categories (id) ON DELETE SET NULL;
+ALTER TABLE blogs ADD COLUMN IF NOT EXISTS primary_category_id UUID REFERENCES categories (id) ON DELETE SET NULL;
@@ -0,0 +1,4 @@
+-- Role based access control; promote the first admin manually:
+--   UPDATE users SET role = 'admin' WHERE email = '...';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role TEXT NOT NULL DEFAULT 'viewer'
+    CHECK (role IN ('admin', 'editor', 'author', 'viewer'));
@@ -0,0 +1,11 @@
+-- Workflow history of blogs with reviewer comments
+CREATE TABLE IF NOT EXISTS blog_transitions (
+    id BIGSERIAL PRIMARY KEY,
+    blog_id UUID NOT NULL REFERENCES blogs (id) ON DELETE CASCADE,
+    from_state TEXT NOT NULL,
+    to_state TEXT NOT NULL,
+    actor_id UUID REFERENCES users (id) ON DELETE SET NULL,
+    comment TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS blog_transitions_blog_idx ON blog_transitions (blog_id, id);
@@ -0,0 +1,8 @@
+-- Advisory edit locks; a lock past expires_at is free to be taken over
+CREATE TABLE IF NOT EXISTS blog_locks (
+    blog_id UUID PRIMARY KEY REFERENCES blogs (id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    user_name TEXT NOT NULL DEFAULT '',
+    acquired_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
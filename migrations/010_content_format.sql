@@ -0,0 +1,10 @@
+-- Declared content format and the sanitized HTML rendered from it on save.
+-- Existing posts are treated as plain text until they are saved again.
+ALTER TABLE blogs
+    ADD COLUMN IF NOT EXISTS content_format TEXT NOT NULL DEFAULT 'plain'
+        CHECK (content_format IN ('markdown', 'html', 'plain')),
+    ADD COLUMN IF NOT EXISTS content_html TEXT NOT NULL DEFAULT '';
+
+UPDATE blogs
+SET content_html = '<p>' || replace(replace(replace(content, '&', '&amp;'), '<', '&lt;'), '>', '&gt;') || '</p>'
+WHERE content_html = '' AND content <> '';
@@ -0,0 +1,24 @@
+-- Ordered typed content blocks for blogs and the 'blocks' content format.
+-- Existing posts get a single block: plain text becomes a paragraph, markdown and
+-- HTML an html block holding the rendered output without anchors and table of contents.
+ALTER TABLE blogs
+    ADD COLUMN IF NOT EXISTS blocks JSONB NOT NULL DEFAULT '[]';
+
+ALTER TABLE blogs DROP CONSTRAINT IF EXISTS blogs_content_format_check;
+ALTER TABLE blogs
+    ADD CONSTRAINT blogs_content_format_check
+        CHECK (content_format IN ('markdown', 'html', 'plain', 'blocks'));
+
+UPDATE blogs
+SET blocks = jsonb_build_array(jsonb_build_object(
+    'type', 'paragraph',
+    'data', jsonb_build_object('text', replace(replace(replace(trim(content), '&', '&amp;'), '<', '&lt;'), '>', '&gt;'))))
+WHERE blocks = '[]' AND content_format = 'plain' AND trim(content) <> '';
+
+UPDATE blogs
+SET blocks = jsonb_build_array(jsonb_build_object(
+    'type', 'html',
+    'data', jsonb_build_object('html', regexp_replace(regexp_replace(content_html,
+        '<nav class="toc">.*?</nav>\s*', '', 'g'),
+        '<a class="heading-anchor"[^>]*>#</a>', '', 'g'))))
+WHERE blocks = '[]' AND content_format IN ('markdown', 'html') AND content_html <> '';
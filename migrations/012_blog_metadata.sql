@@ -0,0 +1,23 @@
+-- Excerpt, word count and reading time computed whenever a blog is saved.
+-- custom_excerpt holds an excerpt set by the author, excerpt the one that is shown.
+ALTER TABLE blogs
+    ADD COLUMN IF NOT EXISTS custom_excerpt TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS excerpt TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS word_count INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS reading_time_minutes INTEGER NOT NULL DEFAULT 0;
+
+-- Approximate values for existing posts from their rendered HTML; exact ones follow on the next save
+WITH stripped AS (
+    SELECT id, btrim(regexp_replace(regexp_replace(regexp_replace(content_html,
+        '<nav class="toc">.*?</nav>', ' ', 'g'),
+        '<[^>]*>', ' ', 'g'),
+        '\s+', ' ', 'g')) AS text
+    FROM blogs
+    WHERE word_count = 0 AND content_html <> ''
+)
+UPDATE blogs b
+SET word_count = cardinality(regexp_split_to_array(s.text, ' ')),
+    reading_time_minutes = ceil(cardinality(regexp_split_to_array(s.text, ' ')) / 200.0),
+    excerpt = CASE WHEN length(s.text) > 200 THEN left(s.text, 199) || '…' ELSE s.text END
+FROM stripped s
+WHERE b.id = s.id AND s.text <> '';
@@ -0,0 +1,18 @@
+-- Free-form tags attached to blogs. Names are unique regardless of case.
+CREATE TABLE IF NOT EXISTS tags (
+    id UUID PRIMARY KEY,
+    name TEXT NOT NULL,
+    slug TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS tags_lower_name_idx ON tags (LOWER(name));
+CREATE INDEX IF NOT EXISTS tags_lower_name_prefix_idx ON tags (LOWER(name) text_pattern_ops);
+
+CREATE TABLE IF NOT EXISTS blog_tags (
+    blog_id UUID NOT NULL REFERENCES blogs (id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+    PRIMARY KEY (blog_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS blog_tags_tag_id_idx ON blog_tags (tag_id);
@@ -0,0 +1,24 @@
+-- Reader comments with threading and moderation, and a per-blog switch to disable them
+ALTER TABLE blogs
+    ADD COLUMN IF NOT EXISTS comments_enabled BOOLEAN NOT NULL DEFAULT TRUE;
+
+CREATE TABLE IF NOT EXISTS comments (
+    id UUID PRIMARY KEY,
+    blog_id UUID NOT NULL REFERENCES blogs (id) ON DELETE CASCADE,
+    parent_id UUID REFERENCES comments (id) ON DELETE CASCADE,
+    depth INTEGER NOT NULL DEFAULT 0,
+    author_name TEXT NOT NULL,
+    author_email TEXT NOT NULL,
+    author_url TEXT NOT NULL DEFAULT '',
+    content TEXT NOT NULL,
+    content_html TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'approved', 'spam', 'trash')),
+    ip TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS comments_blog_id_status_idx ON comments (blog_id, status, created_at);
+CREATE INDEX IF NOT EXISTS comments_status_created_at_idx ON comments (status, created_at DESC);
@@ -0,0 +1,19 @@
+-- Uploaded media files, deduplicated by content hash, and blog covers referencing them
+CREATE TABLE IF NOT EXISTS media (
+    id UUID PRIMARY KEY,
+    storage_key TEXT NOT NULL UNIQUE,
+    filename TEXT NOT NULL,
+    mime_type TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    hash TEXT NOT NULL UNIQUE,
+    alt TEXT NOT NULL DEFAULT '',
+    caption TEXT NOT NULL DEFAULT '',
+    uploaded_by UUID REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS media_created_at_idx ON media (created_at DESC);
+
+ALTER TABLE blogs
+    ADD COLUMN IF NOT EXISTS cover_media_id UUID REFERENCES media (id) ON DELETE SET NULL;
@@ -0,0 +1,6 @@
+-- Image dimensions and the focal point used for square crops
+ALTER TABLE media
+    ADD COLUMN IF NOT EXISTS width INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS height INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS focal_x DOUBLE PRECISION NOT NULL DEFAULT 0.5,
+    ADD COLUMN IF NOT EXISTS focal_y DOUBLE PRECISION NOT NULL DEFAULT 0.5;
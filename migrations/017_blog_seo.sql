@@ -0,0 +1,7 @@
+-- Search and social metadata per blog; empty values fall back to the title, excerpt and cover
+ALTER TABLE blogs
+    ADD COLUMN IF NOT EXISTS meta_title TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS meta_description TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS canonical_url TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS noindex BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS social_image TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,8 @@
+-- Sinks that accepted an outbox entry, so that a failing sink is retried on its own instead of
+-- every sink receiving the entry again
+CREATE TABLE IF NOT EXISTS outbox_dispatches (
+    entry_id BIGINT NOT NULL REFERENCES outbox (id) ON DELETE CASCADE,
+    sink TEXT NOT NULL,
+    dispatched_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (entry_id, sink)
+);
@@ -0,0 +1,3 @@
+-- When a tag was last renamed or merged into, so that feeds listing its name can tell they changed
+ALTER TABLE tags
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
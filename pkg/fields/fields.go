@@ -0,0 +1,90 @@
+package fields
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// Parse splits a comma separated list such as "id,title,slug", dropping blanks and duplicates
+func Parse(raw string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+// Names returns the JSON field names of a struct, including those of embedded structs
+func Names(v interface{}) map[string]bool {
+	names := make(map[string]bool)
+	collect(reflect.TypeOf(v), names)
+	return names
+}
+
+// Unknown returns the first name that is not allowed, or an empty string when all are
+func Unknown(names []string, allowed map[string]bool) string {
+	for _, name := range names {
+		if !allowed[name] {
+			return name
+		}
+	}
+	return ""
+}
+
+// Select encodes v as a JSON object keeping only the given fields; all fields are kept when names is empty
+func Select(v interface{}, names []string) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return all, nil
+	}
+	selected := make(map[string]json.RawMessage, len(names))
+	for _, name := range names {
+		if value, ok := all[name]; ok {
+			selected[name] = value
+		}
+	}
+	return selected, nil
+}
+
+// Helper to walk struct fields the way encoding/json does
+func collect(t reflect.Type, names map[string]bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			collect(f.Type, names)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		names[name] = true
+	}
+}
@@ -0,0 +1,266 @@
+package markup
+
+import (
+	"bytes"
+	"cms-project/pkg/slug"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	gmhtml "github.com/yuin/goldmark/renderer/html"
+	xhtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Content formats a blog can be written in
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+	FormatPlain    = "plain"
+)
+
+// minTOCHeadings is the number of headings from which a table of contents is added
+const minTOCHeadings = 2
+
+// ErrUnknownFormat is returned for content formats other than markdown, html and plain
+var ErrUnknownFormat = errors.New("content_format must be one of markdown, html or plain")
+
+var (
+	markdown = goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		// Raw HTML is passed through here and removed by the sanitizer below
+		goldmark.WithRendererOptions(gmhtml.WithUnsafe()),
+	)
+	policy = bluemonday.UGCPolicy()
+)
+
+// blockElements are separated by whitespace when converting to plain text
+var blockElements = map[atom.Atom]bool{
+	atom.P: true, atom.Br: true, atom.Div: true, atom.Li: true, atom.Blockquote: true, atom.Pre: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Figure: true, atom.Figcaption: true, atom.Tr: true, atom.Td: true, atom.Th: true, atom.Hr: true,
+}
+
+// Heading is an entry of the table of contents
+type Heading struct {
+	Level int
+	Text  string
+	ID    string
+}
+
+// ValidFormat reports whether format is a supported content format
+func ValidFormat(format string) bool {
+	return format == FormatMarkdown || format == FormatHTML || format == FormatPlain
+}
+
+// Render converts content to sanitized HTML. Scripts, event handlers and unsafe URLs are stripped,
+// headings get anchors and, with enough headings, a table of contents is put in front.
+func Render(format, src string) (string, error) {
+	html, err := Convert(format, src)
+	if err != nil {
+		return "", err
+	}
+	return Decorate(html)
+}
+
+// Convert turns content into sanitized HTML without heading anchors or a table of contents
+func Convert(format, src string) (string, error) {
+	var raw string
+	switch format {
+	case FormatMarkdown:
+		var buf bytes.Buffer
+		if err := markdown.Convert([]byte(src), &buf); err != nil {
+			return "", err
+		}
+		raw = buf.String()
+	case FormatHTML:
+		raw = src
+	case FormatPlain:
+		raw = plainToHTML(src)
+	default:
+		return "", ErrUnknownFormat
+	}
+	return Sanitize(raw), nil
+}
+
+// Sanitize removes everything outside the allow-list policy from an HTML fragment
+func Sanitize(src string) string {
+	return policy.Sanitize(src)
+}
+
+// plainToHTML escapes text and turns blank-line separated blocks into paragraphs
+func plainToHTML(src string) string {
+	var b strings.Builder
+	for _, para := range strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n\n") {
+		para = strings.TrimSpace(para)
+		if para == "" {
+			continue
+		}
+		b.WriteString("<p>")
+		b.WriteString(strings.ReplaceAll(html.EscapeString(para), "\n", "<br>"))
+		b.WriteString("</p>\n")
+	}
+	return b.String()
+}
+
+// PlainText strips all markup from an HTML fragment, separating block elements with whitespace
+func PlainText(src string) string {
+	nodes, err := parseFragment(src)
+	if err != nil {
+		return ""
+	}
+	var b strings.Builder
+	var walk func(n *xhtml.Node)
+	walk = func(n *xhtml.Node) {
+		if n.Type == xhtml.TextNode {
+			b.WriteString(n.Data)
+		}
+		if n.Type == xhtml.ElementNode && (n.DataAtom == atom.Nav || (n.DataAtom == atom.A && hasClass(n, "heading-anchor"))) {
+			return // generated navigation is not part of the text
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+		if n.Type == xhtml.ElementNode && blockElements[n.DataAtom] {
+			b.WriteByte(' ')
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// Decorate gives every heading of sanitized HTML a unique id and a self link, and prepends a
+// table of contents when there are enough headings
+func Decorate(src string) (string, error) {
+	nodes, err := parseFragment(src)
+	if err != nil {
+		return "", err
+	}
+
+	var headings []Heading
+	used := map[string]int{}
+	var walk func(n *xhtml.Node)
+	walk = func(n *xhtml.Node) {
+		if level := headingLevel(n); level > 0 {
+			text := strings.TrimSpace(textContent(n))
+			id := uniqueID(slug.Make(text), used)
+			setAttr(n, "id", id)
+			n.AppendChild(anchor(id))
+			headings = append(headings, Heading{Level: level, Text: text, ID: id})
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+
+	var buf bytes.Buffer
+	if len(headings) >= minTOCHeadings {
+		buf.WriteString(tableOfContents(headings))
+	}
+	for _, n := range nodes {
+		if err := xhtml.Render(&buf, n); err != nil {
+			return "", err
+		}
+	}
+	return buf.String(), nil
+}
+
+func parseFragment(src string) ([]*xhtml.Node, error) {
+	return xhtml.ParseFragment(strings.NewReader(src), &xhtml.Node{Type: xhtml.ElementNode, Data: "div", DataAtom: atom.Div})
+}
+
+func hasClass(n *xhtml.Node, class string) bool {
+	for _, attr := range n.Attr {
+		if attr.Key == "class" {
+			for _, c := range strings.Fields(attr.Val) {
+				if c == class {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func tableOfContents(headings []Heading) string {
+	var b strings.Builder
+	b.WriteString(`<nav class="toc"><ul>`)
+	for _, h := range headings {
+		fmt.Fprintf(&b, `<li class="toc-h%d"><a href="#%s">%s</a></li>`, h.Level, h.ID, html.EscapeString(h.Text))
+	}
+	b.WriteString("</ul></nav>\n")
+	return b.String()
+}
+
+func anchor(id string) *xhtml.Node {
+	a := &xhtml.Node{Type: xhtml.ElementNode, Data: "a", DataAtom: atom.A, Attr: []xhtml.Attribute{
+		{Key: "class", Val: "heading-anchor"},
+		{Key: "href", Val: "#" + id},
+		{Key: "aria-hidden", Val: "true"},
+	}}
+	a.AppendChild(&xhtml.Node{Type: xhtml.TextNode, Data: "#"})
+	return a
+}
+
+func headingLevel(n *xhtml.Node) int {
+	if n.Type != xhtml.ElementNode {
+		return 0
+	}
+	switch n.DataAtom {
+	case atom.H1:
+		return 1
+	case atom.H2:
+		return 2
+	case atom.H3:
+		return 3
+	case atom.H4:
+		return 4
+	case atom.H5:
+		return 5
+	case atom.H6:
+		return 6
+	}
+	return 0
+}
+
+func textContent(n *xhtml.Node) string {
+	if n.Type == xhtml.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(textContent(c))
+	}
+	return b.String()
+}
+
+func uniqueID(base string, used map[string]int) string {
+	if base == "" {
+		base = "section"
+	}
+	used[base]++
+	if used[base] == 1 {
+		return base
+	}
+	return fmt.Sprintf("%s-%d", base, used[base])
+}
+
+func setAttr(n *xhtml.Node, key, value string) {
+	for i, attr := range n.Attr {
+		if attr.Key == key {
+			n.Attr[i].Val = value
+			return
+		}
+	}
+	n.Attr = append(n.Attr, xhtml.Attribute{Key: key, Val: value})
+}
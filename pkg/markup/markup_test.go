@@ -0,0 +1,115 @@
+package markup
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRenderStripsUnsafeMarkup(t *testing.T) {
+	tests := []struct {
+		name, format, src string
+		forbidden         []string
+		want              string
+	}{
+		{"script element", FormatHTML, `<p>Hi</p><script>alert(1)</script>`, []string{"<script", "alert(1)"}, "<p>Hi</p>"},
+		{"event handler", FormatHTML, `<img src="/a.png" onerror="alert(1)">`, []string{"onerror", "alert(1)"}, `src="/a.png"`},
+		{"uppercase event handler", FormatHTML, `<p ONCLICK="alert(1)">Hi</p>`, []string{"onclick", "ONCLICK", "alert(1)"}, "Hi"},
+		{"javascript link", FormatHTML, `<a href="javascript:alert(1)">x</a>`, []string{"javascript:", "alert(1)"}, "x"},
+		{"mixed case javascript link", FormatHTML, `<a href="JaVaScRiPt:alert(1)">x</a>`, []string{"JaVaScRiPt:", "alert(1)"}, "x"},
+		{"data url", FormatHTML, `<a href="data:text/html;base64,PHNjcmlwdD4=">x</a>`, []string{"data:"}, "x"},
+		{"data image", FormatHTML, `<img src="data:image/svg+xml;base64,PHN2Zz4=">`, []string{"data:"}, ""},
+		{"markdown javascript link", FormatMarkdown, `[x](javascript:alert(1))`, []string{"javascript:", "alert(1)"}, "x"},
+		{"markdown raw script", FormatMarkdown, "Hi\n\n<script>alert(1)</script>", []string{"<script", "alert(1)"}, "<p>Hi</p>"},
+		{"markdown raw event handler", FormatMarkdown, `<div onclick="alert(1)">Hi</div>`, []string{"onclick", "alert(1)"}, "Hi"},
+		{"markdown raw iframe", FormatMarkdown, `<iframe src="https://evil.example"></iframe>`, []string{"<iframe", "evil.example"}, ""},
+		{"plain text escaped", FormatPlain, `<script>alert(1)</script>`, []string{"<script"}, "&lt;script&gt;"},
+	}
+	for _, tt := range tests {
+		got, err := Render(tt.format, tt.src)
+		if err != nil {
+			t.Errorf("%s: Render error = %v", tt.name, err)
+			continue
+		}
+		for _, s := range tt.forbidden {
+			if strings.Contains(got, s) {
+				t.Errorf("%s: Render = %q, must not contain %q", tt.name, got, s)
+			}
+		}
+		if !strings.Contains(got, tt.want) {
+			t.Errorf("%s: Render = %q, want it to contain %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRenderKeepsSafeMarkup(t *testing.T) {
+	got, err := Render(FormatMarkdown, "Some **bold** [link](https://example.com) and ![alt](/uploads/a.png)")
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	for _, want := range []string{"<strong>bold</strong>", `href="https://example.com"`, `src="/uploads/a.png"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Render = %q, want it to contain %q", got, want)
+		}
+	}
+}
+
+func TestRenderUnknownFormat(t *testing.T) {
+	if _, err := Render("rst", "text"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Render error = %v, want ErrUnknownFormat", err)
+	}
+}
+
+func TestDecorateHeadings(t *testing.T) {
+	got, err := Decorate(`<h2>Intro</h2><p>a</p><h3>Intro</h3><h2>Ünïcode &amp; More</h2><h2></h2>`)
+	if err != nil {
+		t.Fatalf("Decorate: %v", err)
+	}
+	for _, want := range []string{
+		`<h2 id="intro">Intro<a class="heading-anchor" href="#intro" aria-hidden="true">#</a></h2>`,
+		`<h3 id="intro-2">`,
+		`href="#intro-2"`,
+		`<h2 id="section">`,
+		`<li class="toc-h2"><a href="#intro">Intro</a></li>`,
+		`<li class="toc-h3"><a href="#intro-2">Intro</a></li>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Decorate = %q, want it to contain %q", got, want)
+		}
+	}
+	if !strings.HasPrefix(got, `<nav class="toc">`) {
+		t.Errorf("Decorate = %q, want the table of contents first", got)
+	}
+}
+
+func TestDecorateTableOfContentsThreshold(t *testing.T) {
+	tests := []struct {
+		name, src string
+		wantTOC   bool
+	}{
+		{"no headings", `<p>Text</p>`, false},
+		{"one heading", `<h2>Only</h2><p>Text</p>`, false},
+		{"two headings", `<h2>First</h2><h2>Second</h2>`, true},
+		{"nested headings", `<div><h2>First</h2></div><blockquote><h3>Second</h3></blockquote>`, true},
+	}
+	for _, tt := range tests {
+		got, err := Decorate(tt.src)
+		if err != nil {
+			t.Errorf("%s: Decorate error = %v", tt.name, err)
+			continue
+		}
+		if hasTOC := strings.Contains(got, `<nav class="toc">`); hasTOC != tt.wantTOC {
+			t.Errorf("%s: table of contents = %v, want %v in %q", tt.name, hasTOC, tt.wantTOC, got)
+		}
+	}
+}
+
+func TestPlainTextSkipsNavigation(t *testing.T) {
+	html, err := Render(FormatMarkdown, "## One\n\nFirst paragraph\n\n## Two\n\nSecond")
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if got, want := PlainText(html), "One First paragraph Two Second"; got != want {
+		t.Errorf("PlainText = %q, want %q", got, want)
+	}
+}
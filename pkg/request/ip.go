@@ -0,0 +1,86 @@
+package request
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"strings"
+)
+
+// ClientIP returns the IP address of the client that sent the request.
+//
+// X-Forwarded-For is only honoured when the request comes from a trusted proxy. TRUSTED_PROXIES
+// lists their IPs and CIDR ranges, comma separated; without it, TRUST_PROXY=true trusts the direct
+// peer as a single proxy hop. Entries are read right to left and the first one that is not a
+// trusted proxy is the client: entries further left were sent by the client and may be spoofed.
+func ClientIP(r *http.Request) string {
+	remote := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		remote = host
+	}
+	forwarded := forwardedFor(r)
+	if len(forwarded) == 0 {
+		return remote
+	}
+
+	proxies := trustedProxies()
+	if len(proxies) == 0 {
+		if os.Getenv("TRUST_PROXY") == "true" {
+			// The direct peer is the only proxy, so the entry it appended is the client
+			return forwarded[len(forwarded)-1]
+		}
+		return remote
+	}
+	if !isTrusted(remote, proxies) {
+		return remote
+	}
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		if !isTrusted(forwarded[i], proxies) {
+			return forwarded[i]
+		}
+	}
+	// Every hop is a trusted proxy, so the left-most one is the closest to the client
+	return forwarded[0]
+}
+
+// trustedProxies parses TRUSTED_PROXIES, skipping entries that are neither an IP nor a CIDR range
+func trustedProxies() []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, entry := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		entry = strings.TrimSpace(entry)
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+		} else if addr, err := netip.ParseAddr(entry); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+		}
+	}
+	return prefixes
+}
+
+func isTrusted(ip string, proxies []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range proxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedFor returns the addresses of all X-Forwarded-For headers of a request, in order
+func forwardedFor(r *http.Request) []string {
+	var addrs []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		for _, addr := range strings.Split(header, ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				addrs = append(addrs, addr)
+			}
+		}
+	}
+	return addrs
+}
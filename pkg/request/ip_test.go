@@ -0,0 +1,46 @@
+package request
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		trustProxy string
+		trusted    string
+		remote     string
+		forwarded  []string
+		want       string
+	}{
+		{"no proxy configured", "", "", "203.0.113.7:4000", nil, "203.0.113.7"},
+		{"forwarded ignored without trust", "", "", "203.0.113.7:4000", []string{"198.51.100.1"}, "203.0.113.7"},
+		{"remote without port", "", "", "203.0.113.7", nil, "203.0.113.7"},
+		{"single trusted hop takes right-most", "true", "", "10.0.0.2:4000", []string{"198.51.100.9, 203.0.113.7"}, "203.0.113.7"},
+		{"single trusted hop without header", "true", "", "10.0.0.2:4000", nil, "10.0.0.2"},
+		{"spoofed left-most entry skipped", "", "10.0.0.0/8", "10.0.0.2:4000", []string{"198.51.100.9, 203.0.113.7"}, "203.0.113.7"},
+		{"trusted hops skipped", "", "10.0.0.0/8, 192.0.2.10", "10.0.0.2:4000", []string{"198.51.100.9, 203.0.113.7, 192.0.2.10, 10.1.2.3"}, "203.0.113.7"},
+		{"repeated headers read in order", "", "10.0.0.0/8", "10.0.0.2:4000", []string{"198.51.100.9", "203.0.113.7, 10.1.2.3"}, "203.0.113.7"},
+		{"untrusted peer ignores header", "", "10.0.0.0/8", "203.0.113.7:4000", []string{"198.51.100.9"}, "203.0.113.7"},
+		{"list takes precedence over TRUST_PROXY", "true", "10.0.0.0/8", "203.0.113.7:4000", []string{"198.51.100.9"}, "203.0.113.7"},
+		{"all hops trusted", "", "10.0.0.0/8", "10.0.0.2:4000", []string{"10.0.0.5, 10.0.0.4"}, "10.0.0.5"},
+		{"IPv6 proxy", "", "2001:db8::/32", "[2001:db8::1]:4000", []string{"2001:db8:ffff::1, 2606:4700::1"}, "2606:4700::1"},
+		{"IPv4-mapped IPv6 proxy", "", "10.0.0.0/8", "[::ffff:10.0.0.2]:4000", []string{"203.0.113.7"}, "203.0.113.7"},
+		{"invalid list entries ignored", "", "proxy.local, 10.0.0.2", "10.0.0.2:4000", []string{"203.0.113.7"}, "203.0.113.7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TRUST_PROXY", tt.trustProxy)
+			t.Setenv("TRUSTED_PROXIES", tt.trusted)
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remote
+			for _, header := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", header)
+			}
+			if got := ClientIP(r); got != tt.want {
+				t.Errorf("ClientIP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package response
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// NotModified sets the ETag and Last-Modified validators of a response and answers with 304 Not
+// Modified when the client's copy is still current. It returns true when nothing more should be
+// written.
+func NotModified(w http.ResponseWriter, r *http.Request, etag string, modified time.Time) bool {
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+	if !modified.IsZero() {
+		w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	fresh := false
+	// If-None-Match takes precedence over If-Modified-Since
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || (etag != "" && candidate == strings.TrimPrefix(etag, "W/")) {
+				fresh = true
+				break
+			}
+		}
+	} else if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !modified.IsZero() {
+		// HTTP dates have second precision
+		fresh = !modified.Truncate(time.Second).After(since)
+	}
+	if fresh {
+		w.WriteHeader(http.StatusNotModified)
+	}
+	return fresh
+}
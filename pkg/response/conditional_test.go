@@ -0,0 +1,72 @@
+package response
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNotModified(t *testing.T) {
+	modified := time.Date(2024, 5, 1, 12, 30, 45, 500_000_000, time.UTC)
+	const etag = `W/"abc"`
+	tests := []struct {
+		name    string
+		method  string
+		headers map[string]string
+		etag    string
+		want    bool
+	}{
+		{"no validators", http.MethodGet, nil, etag, false},
+		{"weak etag", http.MethodGet, map[string]string{"If-None-Match": `W/"abc"`}, etag, true},
+		{"strong etag matches weak", http.MethodGet, map[string]string{"If-None-Match": `"abc"`}, etag, true},
+		{"weak etag matches strong", http.MethodGet, map[string]string{"If-None-Match": `W/"abc"`}, `"abc"`, true},
+		{"other etag", http.MethodGet, map[string]string{"If-None-Match": `W/"def"`}, etag, false},
+		{"etag list", http.MethodGet, map[string]string{"If-None-Match": `"one", W/"abc" ,"two"`}, etag, true},
+		{"etag list without match", http.MethodGet, map[string]string{"If-None-Match": `"one", "two"`}, etag, false},
+		{"wildcard", http.MethodGet, map[string]string{"If-None-Match": "*"}, etag, true},
+		{"no etag of our own", http.MethodGet, map[string]string{"If-None-Match": `""`}, "", false},
+		{"head", http.MethodHead, map[string]string{"If-None-Match": etag}, etag, true},
+		{"post ignores validators", http.MethodPost, map[string]string{"If-None-Match": etag}, etag, false},
+		{"same second", http.MethodGet, map[string]string{"If-Modified-Since": "Wed, 01 May 2024 12:30:45 GMT"}, etag, true},
+		{"later", http.MethodGet, map[string]string{"If-Modified-Since": "Wed, 01 May 2024 13:00:00 GMT"}, etag, true},
+		{"second before", http.MethodGet, map[string]string{"If-Modified-Since": "Wed, 01 May 2024 12:30:44 GMT"}, etag, false},
+		{"invalid date", http.MethodGet, map[string]string{"If-Modified-Since": "yesterday"}, etag, false},
+		{"etag takes precedence", http.MethodGet, map[string]string{
+			"If-None-Match":     `W/"def"`,
+			"If-Modified-Since": "Wed, 01 May 2024 13:00:00 GMT",
+		}, etag, false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(tt.method, "/feed.json", nil)
+		for k, v := range tt.headers {
+			r.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		got := NotModified(w, r, tt.etag, modified)
+		if got != tt.want {
+			t.Errorf("%s: NotModified = %v, want %v", tt.name, got, tt.want)
+		}
+		if wantCode := map[bool]int{true: http.StatusNotModified, false: http.StatusOK}[tt.want]; w.Code != wantCode {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, wantCode)
+		}
+		if tt.etag != "" && w.Header().Get("ETag") != tt.etag {
+			t.Errorf("%s: ETag = %q, want %q", tt.name, w.Header().Get("ETag"), tt.etag)
+		}
+		if got, want := w.Header().Get("Last-Modified"), "Wed, 01 May 2024 12:30:45 GMT"; got != want {
+			t.Errorf("%s: Last-Modified = %q, want %q", tt.name, got, want)
+		}
+	}
+}
+
+func TestNotModifiedWithoutModificationTime(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("If-Modified-Since", "Wed, 01 May 2024 12:30:45 GMT")
+	w := httptest.NewRecorder()
+	if NotModified(w, r, "", time.Time{}) {
+		t.Error("NotModified = true, want false without a modification time")
+	}
+	if w.Header().Get("Last-Modified") != "" || w.Header().Get("ETag") != "" {
+		t.Errorf("validators = %v, want none", w.Header())
+	}
+}
@@ -0,0 +1,53 @@
+package slug
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// replacements transliterates characters that have a natural ASCII form
+var replacements = map[rune]string{
+	'ç': "c", 'ğ': "g", 'ı': "i", 'ö': "o", 'ş': "s", 'ü': "u",
+	'â': "a", 'î': "i", 'û': "u", 'é': "e", 'è': "e", 'à': "a", 'ß': "ss",
+	'\u0307': "", // combining dot left behind by lowercasing 'İ'
+}
+
+// Make converts a title into a lowercase, hyphen separated URL slug
+func Make(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if rep, ok := replacements[r]; ok {
+			b.WriteString(rep)
+			dash = false
+			continue
+		}
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// Unique returns base, or base with the lowest -2, -3, ... suffix that is not among taken
+func Unique(base string, taken []string) string {
+	used := make(map[string]bool, len(taken))
+	for _, t := range taken {
+		used[t] = true
+	}
+	if !used[base] {
+		return base
+	}
+	for n := 2; ; n++ {
+		if candidate := base + "-" + strconv.Itoa(n); !used[candidate] {
+			return candidate
+		}
+	}
+}